@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
@@ -10,10 +9,20 @@ import (
 	"time"
 
 	"github.com/abhisheksainimitawa/job-aggregator/internal/api"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/companies"
 	"github.com/abhisheksainimitawa/job-aggregator/internal/config"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/dedup"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/health"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/lifecycle"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/migrate"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/models"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/partition"
 	"github.com/abhisheksainimitawa/job-aggregator/internal/repository"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/retention"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/scam"
 	"github.com/abhisheksainimitawa/job-aggregator/internal/scraper"
 	"github.com/abhisheksainimitawa/job-aggregator/internal/service"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/tagging"
 	"github.com/abhisheksainimitawa/job-aggregator/pkg/logger"
 )
 
@@ -26,20 +35,86 @@ func main() {
 
 	logger.Info("Starting Job Aggregator API Server...")
 
-	// Initialize database
-	db, err := repository.NewDB(cfg.GetDatabaseDSN())
-	if err != nil {
-		logger.Fatal("Failed to connect to database: %v", err)
-	}
-	defer db.Close()
+	// Background jobs run until shutdown
+	background, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+
+	// Initialize storage. Postgres supports every feature; the sqlite and
+	// memory drivers run without a database server but without companies,
+	// clustering and listing lifecycle.
+	var (
+		jobStore      repository.JobStore
+		companyRepo   *repository.CompanyRepository
+		retentionRuns *repository.RetentionRepository
+		sourceRuns    health.Store
+		resolver      *companies.Resolver
+		clusterer     *dedup.Clusterer
+		tracker       *lifecycle.Tracker
+	)
+	switch cfg.Database.Driver {
+	case config.DriverSQLite:
+		db, err := repository.NewSQLiteDB(cfg.Database.SQLitePath)
+		if err != nil {
+			logger.Fatal("Failed to open SQLite database: %v", err)
+		}
+		defer db.Close()
 
-	// Initialize schema
-	if err := repository.InitSchema(db); err != nil {
-		logger.Fatal("Failed to initialize database schema: %v", err)
-	}
+		jobStore = repository.NewSQLiteJobStore(db)
 
-	// Initialize repositories
-	jobRepo := repository.NewJobRepository(db)
+	case config.DriverMemory:
+		logger.Info("Using the in-memory job store, jobs are lost on exit")
+		jobStore = repository.NewMemoryJobStore()
+
+	default:
+		db, err := repository.NewDB(cfg.GetDatabaseDSN())
+		if err != nil {
+			logger.Fatal("Failed to connect to database: %v", err)
+		}
+		defer db.Close()
+
+		// Refuse to start against an out-of-date schema
+		migrator, err := migrate.New(db)
+		if err != nil {
+			logger.Fatal("Failed to load migrations: %v", err)
+		}
+		if err := migrator.Check(context.Background()); err != nil {
+			logger.Fatal("Database schema is not up to date: %v (run \"migrate up\")", err)
+		}
+
+		// Create the partitions of coming months now and once a day after
+		maintainer := partition.NewMaintainer(repository.NewPartitionRepository(db), cfg.Partitions.MonthsAhead)
+		if err := maintainer.Ensure(background); err != nil {
+			logger.Fatal("Failed to create job partitions: %v", err)
+		}
+		go maintainer.Run(background, 24*time.Hour)
+
+		// Initialize repositories
+		jobRepo := repository.NewJobRepository(db)
+		companyRepo = repository.NewCompanyRepository(db)
+		retentionRuns = repository.NewRetentionRepository(db)
+		sourceRuns = repository.NewSourceRunRepository(db)
+
+		// Key jobs stored before URL canonicalization like new scrapes
+		if err := rekeyListings(jobRepo); err != nil {
+			logger.Fatal("Failed to re-key jobs: %v", err)
+		}
+
+		// Initialize company resolver and apply the alias list
+		resolver = companies.NewResolver(companyRepo)
+		if err := syncCompanies(resolver, cfg.Companies.AliasesPath); err != nil {
+			logger.Fatal("Failed to sync companies: %v", err)
+		}
+
+		// Initialize listing lifecycle tracking
+		var verifier *lifecycle.Verifier
+		if cfg.Lifecycle.VerifyURLs {
+			verifier = lifecycle.NewVerifier(cfg.Lifecycle.VerifyTimeout)
+		}
+		tracker = lifecycle.NewTracker(jobRepo, cfg.Lifecycle.CloseAfterRuns, verifier)
+		clusterer = dedup.NewClusterer(jobRepo)
+
+		jobStore = jobRepo
+	}
 
 	// Initialize scraper engine
 	scraperEngine := scraper.NewEngine(cfg.Scraper.Workers, cfg.Scraper.RateLimit)
@@ -48,11 +123,63 @@ func main() {
 	scraperEngine.RegisterSource(scraper.NewGlassdoorScraper())
 	defer scraperEngine.Shutdown()
 
+	// Initialize skill tagger
+	skills, err := tagging.LoadDictionary(cfg.Tagging.DictionaryPath)
+	if err != nil {
+		logger.Fatal("Failed to load skills dictionary: %v", err)
+	}
+	tagger := tagging.NewTagger(skills)
+
+	// Track source health, in memory unless Postgres keeps the history
+	if sourceRuns == nil {
+		sourceRuns = health.NewMemoryStore(100)
+	}
+	thresholds := health.DefaultThresholds
+	thresholds.VolumeDrop = cfg.Health.VolumeDrop
+	monitor := health.NewMonitor(sourceRuns, health.NewNotifier(cfg.Health.WebhookURL), cfg.Health.BaselineRuns, thresholds)
+
+	// Flag scam and spam postings
+	rules, err := scam.LoadRules(cfg.Scam.RulesPath)
+	if err != nil {
+		logger.Fatal("Failed to load scam rules: %v", err)
+	}
+	var classifier *scam.Classifier
+	if cfg.Scam.Classifier {
+		if classifier, err = scam.TrainFile(cfg.Scam.LabelsPath); err != nil {
+			logger.Fatal("Failed to train scam classifier: %v", err)
+		}
+	}
+	detector, err := scam.NewDetector(rules, classifier, cfg.Scam.Threshold, cfg.Scam.LabelsPath)
+	if err != nil {
+		logger.Fatal("Failed to create scam detector: %v", err)
+	}
+
 	// Initialize services
-	jobService := service.NewJobService(jobRepo, scraperEngine)
+	jobService := service.NewJobService(jobStore, scraperEngine, tagger, resolver, clusterer, tracker, monitor, detector)
+	companyService := service.NewCompanyService(companyRepo)
+
+	// Refresh precomputed stats between scrape runs, which refresh them too
+	if cfg.Stats.RefreshInterval > 0 {
+		go jobService.ScheduleStatsRefresh(background, cfg.Stats.RefreshInterval)
+	}
+
+	// Initialize retention and schedule it when rules are configured
+	var retentionRules []models.RetentionRule
+	if cfg.Retention.RulesPath != "" {
+		if retentionRules, err = retention.LoadRules(cfg.Retention.RulesPath); err != nil {
+			logger.Fatal("Failed to load retention rules: %v", err)
+		}
+	}
+	retentionService := service.NewRetentionService(
+		retention.NewEngine(jobStore, retentionRules, cfg.Retention.BatchSize), retentionRuns,
+	)
+	if len(retentionRules) > 0 && cfg.Retention.Interval > 0 {
+		logger.Info("Applying %d retention rules every %v", len(retentionRules), cfg.Retention.Interval)
+		go retentionService.Schedule(background, cfg.Retention.Interval, cfg.Retention.DryRun)
+	}
 
 	// Initialize HTTP handler
-	handler := api.NewHandler(jobService)
+	handler := api.NewHandler(jobService, companyService, retentionService, cfg.Server.AdminToken)
 	router := handler.SetupRoutes()
 
 	// Create HTTP server
@@ -78,6 +205,7 @@ func main() {
 	<-quit
 
 	logger.Info("Server shutting down gracefully...")
+	stopBackground()
 
 	// Graceful shutdown with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -89,3 +217,34 @@ func main() {
 
 	logger.Info("Server stopped successfully")
 }
+
+// rekeyListings recomputes the keys of jobs stored before URLs were
+// canonicalized, so re-scrapes update them instead of inserting them again
+func rekeyListings(jobRepo *repository.JobRepository) error {
+	rekeyed, kept, err := jobRepo.RekeyListings(context.Background(), scraper.SetListingKeys)
+	if err != nil {
+		return err
+	}
+	if rekeyed+kept > 0 {
+		logger.Info("Re-keyed %d jobs stored before URL canonicalization, %d kept their keys", rekeyed, kept)
+	}
+	return nil
+}
+
+// syncCompanies applies the optional company alias list and links stored
+// jobs without a company to their canonical companies. Jobs follow alias
+// list changes after "companies relink".
+func syncCompanies(resolver *companies.Resolver, path string) error {
+	var entries []companies.AliasEntry
+	if path != "" {
+		var err error
+		if entries, err = companies.LoadAliases(path); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	return resolver.Sync(ctx, entries)
+}
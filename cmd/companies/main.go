@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/abhisheksainimitawa/job-aggregator/internal/companies"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/config"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/migrate"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/repository"
+	"github.com/abhisheksainimitawa/job-aggregator/pkg/logger"
+)
+
+const usage = `Usage: companies <command>
+
+Commands:
+  relink    Apply the COMPANY_ALIASES list and point every stored job at the
+            company its name now resolves to, e.g. after editing the list.
+            The API server only links jobs without a company on start.
+`
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+	}
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) != 1 || args[0] != "relink" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Fatal("Failed to load configuration: %v", err)
+	}
+	if cfg.Database.Driver != config.DriverPostgres {
+		logger.Fatal("Companies require the postgres driver, DB_DRIVER is %s", cfg.Database.Driver)
+	}
+
+	// Initialize database
+	db, err := repository.NewDB(cfg.GetDatabaseDSN())
+	if err != nil {
+		logger.Fatal("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	migrator, err := migrate.New(db)
+	if err != nil {
+		logger.Fatal("Failed to load migrations: %v", err)
+	}
+	if err := migrator.Check(ctx); err != nil {
+		logger.Fatal("Database schema is not up to date: %v (run \"migrate up\")", err)
+	}
+
+	var entries []companies.AliasEntry
+	if cfg.Companies.AliasesPath != "" {
+		if entries, err = companies.LoadAliases(cfg.Companies.AliasesPath); err != nil {
+			logger.Fatal("Failed to load company aliases: %v", err)
+		}
+	}
+
+	resolver := companies.NewResolver(repository.NewCompanyRepository(db))
+	if err := resolver.Sync(ctx, entries); err != nil {
+		logger.Fatal("Failed to sync companies: %v", err)
+	}
+	if err := resolver.Relink(ctx); err != nil {
+		logger.Fatal("Failed to relink jobs: %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/abhisheksainimitawa/job-aggregator/internal/config"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/migrate"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/repository"
+	"github.com/abhisheksainimitawa/job-aggregator/pkg/logger"
+)
+
+const usage = `Usage: migrate [flags] <command>
+
+Commands:
+  up [n]         Apply all pending migrations, or the next n
+  down [n]       Revert the last n applied migrations (default 1)
+  status         List migrations and whether they are applied
+  create <name>  Create empty up/down files for a new migration
+
+Flags:
+`
+
+func main() {
+	dir := flag.String("dir", "internal/migrate/migrations", "Migrations directory used by create")
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// create only touches the filesystem
+	if args[0] == "create" {
+		if len(args) < 2 {
+			logger.Fatal("Migration name is required")
+		}
+		up, down, err := migrate.Create(*dir, args[1])
+		if err != nil {
+			logger.Fatal("Failed to create migration: %v", err)
+		}
+		logger.Info("Created %s and %s", up, down)
+		return
+	}
+
+	n := 0
+	if len(args) > 1 {
+		var err error
+		if n, err = strconv.Atoi(args[1]); err != nil || n < 1 {
+			logger.Fatal("Invalid migration count %q", args[1])
+		}
+	}
+
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Fatal("Failed to load configuration: %v", err)
+	}
+
+	// Initialize database
+	db, err := repository.NewDB(cfg.GetDatabaseDSN())
+	if err != nil {
+		logger.Fatal("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	migrator, err := migrate.New(db)
+	if err != nil {
+		logger.Fatal("Failed to load migrations: %v", err)
+	}
+
+	ctx := context.Background()
+	switch args[0] {
+	case "up":
+		count, err := migrator.Up(ctx, n)
+		if err != nil {
+			logger.Fatal("Migration failed: %v", err)
+		}
+		logger.Info("Applied %d migrations", count)
+
+	case "down":
+		count, err := migrator.Down(ctx, n)
+		if err != nil {
+			logger.Fatal("Migration failed: %v", err)
+		}
+		logger.Info("Reverted %d migrations", count)
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			logger.Fatal("Failed to read migration status: %v", err)
+		}
+		for _, s := range statuses {
+			applied := "pending"
+			if s.Applied {
+				applied = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d  %-32s  %s\n", s.Version, s.Name, applied)
+		}
+		if err := migrator.Check(ctx); err != nil {
+			fmt.Printf("\n%v\n", err)
+		}
+
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
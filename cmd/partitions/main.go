@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/abhisheksainimitawa/job-aggregator/internal/config"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/migrate"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/partition"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/repository"
+	"github.com/abhisheksainimitawa/job-aggregator/pkg/logger"
+)
+
+const usage = `Usage: partitions [flags] <command>
+
+Commands:
+  list                 List the monthly partitions of the jobs table
+  ensure               Create the partitions of the current and coming months
+  archive [YYYY-MM...] Archive the given months to files and drop them, or
+                       every month before -older-than months ago
+  restore <file>...    Load archived jobs back into the jobs table
+
+Flags:
+`
+
+func main() {
+	dir := flag.String("dir", "", "Archive directory (default ARCHIVE_DIR)")
+	format := flag.String("format", "", "Archive format, ndjson or parquet (default ARCHIVE_FORMAT)")
+	olderThan := flag.Int("older-than", 0, "Archive the partitions of months older than this many months")
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Fatal("Failed to load configuration: %v", err)
+	}
+	if cfg.Database.Driver != config.DriverPostgres {
+		logger.Fatal("Partitions require the postgres driver, DB_DRIVER is %s", cfg.Database.Driver)
+	}
+	if *dir == "" {
+		*dir = cfg.Partitions.ArchiveDir
+	}
+	if *format == "" {
+		*format = cfg.Partitions.ArchiveFormat
+	}
+
+	// Initialize database
+	db, err := repository.NewDB(cfg.GetDatabaseDSN())
+	if err != nil {
+		logger.Fatal("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	migrator, err := migrate.New(db)
+	if err != nil {
+		logger.Fatal("Failed to load migrations: %v", err)
+	}
+	if err := migrator.Check(ctx); err != nil {
+		logger.Fatal("Database schema is not up to date: %v (run \"migrate up\")", err)
+	}
+
+	repo := repository.NewPartitionRepository(db)
+
+	switch args[0] {
+	case "list":
+		partitions, err := repo.List(ctx)
+		if err != nil {
+			logger.Fatal("Failed to list partitions: %v", err)
+		}
+		for _, p := range partitions {
+			state := "attached"
+			if !p.Attached {
+				state = "detached"
+			}
+			fmt.Printf("%-14s  %-8s  ~%d jobs\n", p.Name, state, p.Rows)
+		}
+
+	case "ensure":
+		maintainer := partition.NewMaintainer(repo, cfg.Partitions.MonthsAhead)
+		if err := maintainer.Ensure(ctx); err != nil {
+			logger.Fatal("Failed to create partitions: %v", err)
+		}
+
+	case "archive":
+		archiveFormat, err := partition.ParseFormat(*format)
+		if err != nil {
+			logger.Fatal("%v", err)
+		}
+		archiver := partition.NewArchiver(repo, *dir, archiveFormat)
+
+		if *olderThan > 0 {
+			before := time.Now().UTC().AddDate(0, -*olderThan, 0)
+			archived, err := archiver.ArchiveBefore(ctx, before)
+			if err != nil {
+				logger.Fatal("Archive failed: %v", err)
+			}
+			logger.Info("Archived %d partitions", len(archived))
+			return
+		}
+
+		if len(args) < 2 {
+			logger.Fatal("Months to archive or -older-than are required")
+		}
+		for _, arg := range args[1:] {
+			month, err := time.Parse("2006-01", arg)
+			if err != nil {
+				logger.Fatal("Invalid month %q, expected YYYY-MM", arg)
+			}
+			if !month.Before(repository.MonthOf(time.Now())) {
+				logger.Fatal("Refusing to archive %s, only past months can be archived", arg)
+			}
+			if _, err := archiver.Archive(ctx, month); err != nil {
+				logger.Fatal("Archive failed: %v", err)
+			}
+		}
+
+	case "restore":
+		if len(args) < 2 {
+			logger.Fatal("Archive file is required")
+		}
+		archiver := partition.NewArchiver(repo, *dir, "")
+		for _, path := range args[1:] {
+			if _, _, err := archiver.Restore(ctx, path); err != nil {
+				logger.Fatal("Restore failed: %v", err)
+			}
+		}
+
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
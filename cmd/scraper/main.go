@@ -5,9 +5,18 @@ import (
 	"flag"
 	"time"
 
+	"github.com/abhisheksainimitawa/job-aggregator/internal/companies"
 	"github.com/abhisheksainimitawa/job-aggregator/internal/config"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/dedup"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/health"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/lifecycle"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/migrate"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/partition"
 	"github.com/abhisheksainimitawa/job-aggregator/internal/repository"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/scam"
 	"github.com/abhisheksainimitawa/job-aggregator/internal/scraper"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/service"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/tagging"
 	"github.com/abhisheksainimitawa/job-aggregator/pkg/logger"
 )
 
@@ -39,13 +48,45 @@ func main() {
 	}
 	defer db.Close()
 
-	// Initialize schema
-	if err := repository.InitSchema(db); err != nil {
-		logger.Fatal("Failed to initialize database schema: %v", err)
+	// Refuse to start against an out-of-date schema
+	migrator, err := migrate.New(db)
+	if err != nil {
+		logger.Fatal("Failed to load migrations: %v", err)
+	}
+	if err := migrator.Check(context.Background()); err != nil {
+		logger.Fatal("Database schema is not up to date: %v (run \"migrate up\")", err)
+	}
+
+	// Create the partitions of coming months before storing new jobs
+	maintainer := partition.NewMaintainer(repository.NewPartitionRepository(db), cfg.Partitions.MonthsAhead)
+	if err := maintainer.Ensure(context.Background()); err != nil {
+		logger.Fatal("Failed to create job partitions: %v", err)
 	}
 
-	// Initialize repository
+	// Initialize repositories
 	jobRepo := repository.NewJobRepository(db)
+	companyRepo := repository.NewCompanyRepository(db)
+
+	// Key jobs stored before URL canonicalization like new scrapes
+	rekeyed, kept, err := jobRepo.RekeyListings(context.Background(), scraper.SetListingKeys)
+	if err != nil {
+		logger.Fatal("Failed to re-key jobs: %v", err)
+	}
+	if rekeyed+kept > 0 {
+		logger.Info("Re-keyed %d jobs stored before URL canonicalization, %d kept their keys", rekeyed, kept)
+	}
+
+	// Initialize company resolver with the optional alias list
+	resolver := companies.NewResolver(companyRepo)
+	if cfg.Companies.AliasesPath != "" {
+		entries, err := companies.LoadAliases(cfg.Companies.AliasesPath)
+		if err != nil {
+			logger.Fatal("Failed to load company aliases: %v", err)
+		}
+		if err := resolver.Sync(context.Background(), entries); err != nil {
+			logger.Fatal("Failed to sync companies: %v", err)
+		}
+	}
 
 	// Initialize scraper engine
 	scraperEngine := scraper.NewEngine(cfg.Scraper.Workers, cfg.Scraper.RateLimit)
@@ -63,23 +104,53 @@ func main() {
 
 	defer scraperEngine.Shutdown()
 
+	// Initialize skill tagger
+	skills, err := tagging.LoadDictionary(cfg.Tagging.DictionaryPath)
+	if err != nil {
+		logger.Fatal("Failed to load skills dictionary: %v", err)
+	}
+
+	// Initialize listing lifecycle tracking
+	var verifier *lifecycle.Verifier
+	if cfg.Lifecycle.VerifyURLs {
+		verifier = lifecycle.NewVerifier(cfg.Lifecycle.VerifyTimeout)
+	}
+	tracker := lifecycle.NewTracker(jobRepo, cfg.Lifecycle.CloseAfterRuns, verifier)
+
+	// Judge every source against its recent runs
+	thresholds := health.DefaultThresholds
+	thresholds.VolumeDrop = cfg.Health.VolumeDrop
+	monitor := health.NewMonitor(repository.NewSourceRunRepository(db), health.NewNotifier(cfg.Health.WebhookURL), cfg.Health.BaselineRuns, thresholds)
+
+	// Flag scam and spam postings
+	rules, err := scam.LoadRules(cfg.Scam.RulesPath)
+	if err != nil {
+		logger.Fatal("Failed to load scam rules: %v", err)
+	}
+	var classifier *scam.Classifier
+	if cfg.Scam.Classifier {
+		if classifier, err = scam.TrainFile(cfg.Scam.LabelsPath); err != nil {
+			logger.Fatal("Failed to train scam classifier: %v", err)
+		}
+	}
+	detector, err := scam.NewDetector(rules, classifier, cfg.Scam.Threshold, cfg.Scam.LabelsPath)
+	if err != nil {
+		logger.Fatal("Failed to create scam detector: %v", err)
+	}
+
+	// Initialize service
+	jobService := service.NewJobService(jobRepo, scraperEngine, tagging.NewTagger(skills), resolver, dedup.NewClusterer(jobRepo), tracker, monitor, detector)
+
 	// Run scraper with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 	defer cancel()
 
+	// Scrape, enrich and store jobs with deduplication
 	logger.Info("Starting scraping process...")
-	jobs, err := scraperEngine.Start(ctx, *query)
-	if err != nil {
+	if _, err := jobService.RunScraper(ctx, *query); err != nil {
 		logger.Fatal("Scraping failed: %v", err)
 	}
 
-	logger.Info("Scraped %d jobs, storing in database...", len(jobs))
-
-	// Store jobs in database with deduplication
-	if err := jobRepo.CreateBatch(ctx, jobs); err != nil {
-		logger.Fatal("Failed to store jobs: %v", err)
-	}
-
 	// Get statistics
 	stats := scraperEngine.GetStats()
 	logger.Info("=== Scraping Summary ===")
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/abhisheksainimitawa/job-aggregator/internal/config"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/migrate"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/models"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/querylang"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/repository"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/service"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/tagging"
+	"github.com/abhisheksainimitawa/job-aggregator/pkg/logger"
+)
+
+func main() {
+	// Parse command line flags
+	qx := flag.String("qx", "", `Advanced query, e.g. 'title:(go OR golang) AND NOT company:"Acme" AND salary>=150k'`)
+	keywords := flag.String("q", "", "Full-text keywords")
+	status := flag.String("status", "", "Listing status: open (default), closed or all")
+	flagged := flag.String("flagged", "", "Suspicious jobs: include, only or unreviewed (hidden by default)")
+	limit := flag.Int("limit", 20, "Maximum number of jobs to print")
+	flag.Parse()
+
+	if *qx == "" && *keywords == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	query := &models.JobSearchQuery{Keywords: *keywords, Status: *status, Flagged: *flagged, Limit: *limit}
+	if *qx != "" {
+		expr, err := querylang.Parse(*qx)
+		if err != nil {
+			var perr *querylang.Error
+			if errors.As(err, &perr) {
+				fmt.Fprintf(os.Stderr, "Invalid query: %v\n\n%s\n", perr, perr.Caret(*qx))
+				os.Exit(2)
+			}
+			logger.Fatal("Invalid query: %v", err)
+		}
+		query.Expr = expr
+
+		// The default of open jobs would hide what status:closed asks for
+		if query.Status == "" && querylang.HasField(expr, "status") {
+			query.Status = "all"
+		}
+	}
+
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Fatal("Failed to load configuration: %v", err)
+	}
+
+	// Initialize database
+	db, err := repository.NewDB(cfg.GetDatabaseDSN())
+	if err != nil {
+		logger.Fatal("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	// Refuse to run against an out-of-date schema
+	migrator, err := migrate.New(db)
+	if err != nil {
+		logger.Fatal("Failed to load migrations: %v", err)
+	}
+	if err := migrator.Check(context.Background()); err != nil {
+		logger.Fatal("Database schema is not up to date: %v (run \"migrate up\")", err)
+	}
+
+	// Skill aliases in the query are resolved with the tagger's dictionary
+	skills, err := tagging.LoadDictionary(cfg.Tagging.DictionaryPath)
+	if err != nil {
+		logger.Fatal("Failed to load skills dictionary: %v", err)
+	}
+
+	// Only the search paths of the service are used
+	jobService := service.NewJobService(repository.NewJobRepository(db), nil, tagging.NewTagger(skills), nil, nil, nil, nil, nil)
+
+	page, err := jobService.SearchJobsPage(context.Background(), query)
+	if err != nil {
+		logger.Fatal("Search failed: %v", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tPOSTED\tTITLE\tCOMPANY\tLOCATION\tSALARY")
+	for _, job := range page.Jobs {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n",
+			job.ID, job.PostedAt.Format("2006-01-02"), job.Title, job.Company, job.Location, job.Salary)
+	}
+	w.Flush()
+
+	total := fmt.Sprintf("%d", page.Total)
+	if !page.TotalExact {
+		total = "about " + total
+	}
+	fmt.Printf("\nShowing %d of %s matching jobs\n", len(page.Jobs), total)
+}
@@ -2,53 +2,104 @@ package api
 
 import (
 	"context"
+	"crypto/subtle"
+	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/geo"
 	"github.com/abhisheksainimitawa/job-aggregator/internal/models"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/querylang"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/repository"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/sanitize"
 	"github.com/abhisheksainimitawa/job-aggregator/internal/service"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/titles"
 	"github.com/abhisheksainimitawa/job-aggregator/pkg/logger"
+	"github.com/abhisheksainimitawa/job-aggregator/pkg/salary"
+)
+
+const (
+	// maxTimeSeriesBuckets caps the number of buckets of one time series
+	maxTimeSeriesBuckets = 1000
+
+	// maxSalaryGroups caps the number of groups of one salary summary
+	maxSalaryGroups = 500
 )
 
 // Handler holds all HTTP handlers
 type Handler struct {
-	jobService *service.JobService
+	jobService       *service.JobService
+	companyService   *service.CompanyService
+	retentionService *service.RetentionService
+	adminToken       string // Bearer token of the admin routes, "" disables them
 }
 
 // NewHandler creates a new HTTP handler
-func NewHandler(jobService *service.JobService) *Handler {
+func NewHandler(jobService *service.JobService, companyService *service.CompanyService, retentionService *service.RetentionService, adminToken string) *Handler {
 	return &Handler{
-		jobService: jobService,
+		jobService:       jobService,
+		companyService:   companyService,
+		retentionService: retentionService,
+		adminToken:       adminToken,
 	}
 }
 
 // SetupRoutes sets up all API routes
 func (h *Handler) SetupRoutes() http.Handler {
 	r := mux.NewRouter()
+	r.Use(loggingMiddleware)
+
+	// Admin routes change or delete stored jobs. They need the admin token and get
+	// no CORS headers, so pages on other sites cannot call them.
+	admin := r.PathPrefix("/api/v1/admin").Subrouter()
+	admin.Use(h.adminMiddleware)
+	admin.HandleFunc("/flags", h.ListFlaggedJobs).Methods("GET")
+	admin.HandleFunc("/jobs/{id:[0-9]+}/flag", h.ReviewFlag).Methods("PUT")
+	admin.HandleFunc("/retention/run", h.RunRetention).Methods("POST")
+
+	// Every other route is public
+	public := r.NewRoute().Subrouter()
+	public.Use(corsMiddleware)
 
 	// API v1 routes
-	api := r.PathPrefix("/api/v1").Subrouter()
+	api := public.PathPrefix("/api/v1").Subrouter()
 
 	// Job routes
 	api.HandleFunc("/jobs", h.ListJobs).Methods("GET")
 	api.HandleFunc("/jobs/{id:[0-9]+}", h.GetJob).Methods("GET")
+	api.HandleFunc("/jobs/{id:[0-9]+}/history", h.GetJobHistory).Methods("GET")
 	api.HandleFunc("/jobs/search", h.SearchJobs).Methods("GET")
 	api.HandleFunc("/jobs/stats", h.GetStats).Methods("GET")
 
+	// Stats routes
+	api.HandleFunc("/stats/timeseries", h.GetTimeSeries).Methods("GET")
+	api.HandleFunc("/stats/salaries", h.GetSalaryStats).Methods("GET")
+
+	// Company routes
+	api.HandleFunc("/companies", h.ListCompanies).Methods("GET")
+	api.HandleFunc("/companies/{id:[0-9]+}/jobs", h.ListCompanyJobs).Methods("GET")
+
 	// Scraper routes
 	api.HandleFunc("/scraper/run", h.RunScraper).Methods("POST")
 	api.HandleFunc("/scraper/status", h.GetScraperStatus).Methods("GET")
 
-	// Health check
-	r.HandleFunc("/health", h.HealthCheck).Methods("GET")
+	// Source health routes
+	api.HandleFunc("/sources/health", h.GetSourceHealth).Methods("GET")
+	api.HandleFunc("/sources/{source}/runs", h.ListSourceRuns).Methods("GET")
 
-	// Apply middleware
-	r.Use(loggingMiddleware)
-	r.Use(corsMiddleware)
+	// Retention routes
+	api.HandleFunc("/retention/rules", h.GetRetentionRules).Methods("GET")
+	api.HandleFunc("/retention/runs", h.ListRetentionRuns).Methods("GET")
+
+	// Health check
+	public.HandleFunc("/health", h.HealthCheck).Methods("GET")
 
 	return r
 }
@@ -62,23 +113,35 @@ func (h *Handler) ListJobs(w http.ResponseWriter, r *http.Request) {
 		limit = 20
 	}
 
+	format, ok := descriptionFormat(w, r)
+	if !ok {
+		return
+	}
+
 	query := &models.JobSearchQuery{
 		Page:  page,
 		Limit: limit,
 	}
 
-	jobs, err := h.jobService.SearchJobs(r.Context(), query)
+	if !parseCursor(w, r, query) {
+		return
+	}
+
+	result, err := h.jobService.SearchJobsPage(r.Context(), query)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, "Failed to fetch jobs")
+		respondStoreError(w, err, "Failed to fetch jobs")
 		return
 	}
+	applyDescriptionFormat(format, result.Jobs...)
 
-	respondJSON(w, http.StatusOK, map[string]interface{}{
-		"jobs":  jobs,
+	response := map[string]interface{}{
+		"jobs":  result.Jobs,
 		"page":  page,
 		"limit": limit,
-		"total": len(jobs),
-	})
+	}
+	addPagination(w, r, result, response)
+
+	respondJSON(w, http.StatusOK, response)
 }
 
 // GetJob retrieves a single job by ID
@@ -90,26 +153,57 @@ func (h *Handler) GetJob(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	format, ok := descriptionFormat(w, r)
+	if !ok {
+		return
+	}
+
 	job, err := h.jobService.GetJob(r.Context(), id)
 	if err != nil {
 		respondError(w, http.StatusNotFound, "Job not found")
 		return
 	}
+	applyDescriptionFormat(format, job)
 
 	respondJSON(w, http.StatusOK, job)
 }
 
+// GetJobHistory lists the recorded changes of a job
+func (h *Handler) GetJobHistory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid job ID")
+		return
+	}
+
+	if _, err := h.jobService.GetJob(r.Context(), id); err != nil {
+		respondError(w, http.StatusNotFound, "Job not found")
+		return
+	}
+
+	versions, err := h.jobService.GetJobHistory(r.Context(), id)
+	if err != nil {
+		respondStoreError(w, err, "Failed to fetch job history")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"job_id":   id,
+		"versions": versions,
+	})
+}
+
 // SearchJobs searches for jobs based on query parameters
 func (h *Handler) SearchJobs(w http.ResponseWriter, r *http.Request) {
 	q := r.URL.Query()
 
 	query := &models.JobSearchQuery{
-		Keywords: q.Get("q"),
-		Location: q.Get("location"),
-		JobType:  q.Get("type"),
-		Source:   q.Get("source"),
-		Page:     0,
-		Limit:    20,
+		Page:  0,
+		Limit: 20,
+	}
+	if !parseFilters(w, q, query) {
+		return
 	}
 
 	if page := q.Get("page"); page != "" {
@@ -120,40 +214,385 @@ func (h *Handler) SearchJobs(w http.ResponseWriter, r *http.Request) {
 		query.Limit, _ = strconv.Atoi(limit)
 	}
 
-	if remote := q.Get("remote"); remote == "true" {
-		t := true
-		query.Remote = &t
-	} else if remote == "false" {
-		f := false
-		query.Remote = &f
+	if err := parseSort(q, query); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	jobs, err := h.jobService.SearchJobs(r.Context(), query)
+	if !parseCursor(w, r, query) {
+		return
+	}
+	if query.Cursor != nil && !query.Newest() {
+		respondError(w, http.StatusBadRequest, "Cursors are only supported with the default posted_at descending sort, use page")
+		return
+	}
+
+	facets := parseList(q["facets"])
+	for _, facet := range facets {
+		if !isFacetField(facet) {
+			respondError(w, http.StatusBadRequest, "Invalid facet "+facet+", expected one of "+strings.Join(models.FacetFields, ", "))
+			return
+		}
+	}
+
+	format, ok := descriptionFormat(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := h.jobService.SearchJobsPage(r.Context(), query)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, "Search failed")
+		respondStoreError(w, err, "Search failed")
 		return
 	}
+	applyDescriptionFormat(format, result.Jobs...)
 
-	respondJSON(w, http.StatusOK, map[string]interface{}{
-		"jobs":   jobs,
-		"query":  query.Keywords,
-		"total":  len(jobs),
-		"page":   query.Page,
-		"limit":  query.Limit,
-	})
+	response := map[string]interface{}{
+		"jobs":  result.Jobs,
+		"query": query.Keywords,
+		"page":  query.Page,
+		"limit": query.Limit,
+	}
+	addPagination(w, r, result, response)
+
+	if len(facets) > 0 {
+		counts, err := h.jobService.SearchFacets(r.Context(), query, facets)
+		if err != nil {
+			respondStoreError(w, err, "Failed to count facets")
+			return
+		}
+		response["facets"] = counts
+	}
+
+	respondJSON(w, http.StatusOK, response)
 }
 
-// GetStats retrieves job statistics
+// GetStats retrieves job statistics, as of their last refresh unless
+// fresh=true is given and they are over a minute old
 func (h *Handler) GetStats(w http.ResponseWriter, r *http.Request) {
-	stats, err := h.jobService.GetStats(r.Context())
+	fresh := false
+	if v := r.URL.Query().Get("fresh"); v != "" {
+		var err error
+		if fresh, err = strconv.ParseBool(v); err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid fresh, expected true or false")
+			return
+		}
+	}
+
+	stats, err := h.jobService.GetStats(r.Context(), fresh)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, "Failed to fetch stats")
+		respondStoreError(w, err, "Failed to fetch stats")
 		return
 	}
 
 	respondJSON(w, http.StatusOK, stats)
 }
 
+// GetTimeSeries counts new and active jobs per day, week or month,
+// filtered like search, as JSON or CSV
+func (h *Handler) GetTimeSeries(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	query := &models.TimeSeriesQuery{Filter: &models.JobSearchQuery{}, Interval: models.IntervalDay}
+	if interval := q.Get("interval"); interval != "" {
+		if !models.IsInterval(interval) {
+			respondError(w, http.StatusBadRequest, "Invalid interval, expected day, week or month")
+			return
+		}
+		query.Interval = interval
+	}
+	if !parseFilters(w, q, query.Filter) {
+		return
+	}
+
+	query.To = time.Now()
+	if to := q.Get("to"); to != "" {
+		t, err := parseTime(to)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid to, expected RFC 3339 time or YYYY-MM-DD")
+			return
+		}
+		query.To = t
+	}
+
+	switch from := q.Get("from"); {
+	case from != "":
+		t, err := parseTime(from)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid from, expected RFC 3339 time or YYYY-MM-DD")
+			return
+		}
+		query.From = t
+	case query.Interval == models.IntervalMonth:
+		query.From = query.To.AddDate(0, -11, 0)
+	case query.Interval == models.IntervalWeek:
+		query.From = query.To.AddDate(0, 0, -7*25)
+	default:
+		query.From = query.To.AddDate(0, 0, -29)
+	}
+
+	if query.From.After(query.To) {
+		respondError(w, http.StatusBadRequest, "from must not be after to")
+		return
+	}
+	if n := len(query.Bounds()) - 1; n > maxTimeSeriesBuckets {
+		respondError(w, http.StatusBadRequest, fmt.Sprintf("Too many buckets (%d), at most %d are returned; use a wider interval or a shorter range", n, maxTimeSeriesBuckets))
+		return
+	}
+
+	points, err := h.jobService.TimeSeries(r.Context(), query)
+	if err != nil {
+		respondStoreError(w, err, "Failed to count jobs")
+		return
+	}
+
+	if q.Get("format") == "csv" || strings.Contains(r.Header.Get("Accept"), "text/csv") {
+		respondTimeSeriesCSV(w, points)
+		return
+	}
+
+	bounds := query.Bounds()
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"interval": query.Interval,
+		"from":     bounds[0],
+		"to":       bounds[len(bounds)-1],
+		"points":   points,
+	})
+}
+
+// GetSalaryStats returns salary percentiles of the jobs matching the
+// search filters, grouped by the fields in ?group_by
+func (h *Handler) GetSalaryStats(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	query := &models.SalaryStatsQuery{
+		Filter:     &models.JobSearchQuery{},
+		GroupBy:    parseList(q["group_by"]),
+		MinSamples: models.DefaultSalaryMinSamples,
+		Limit:      50,
+	}
+	seen := make(map[string]bool)
+	if query.GroupBy == nil {
+		query.GroupBy = []string{}
+	}
+	for _, field := range query.GroupBy {
+		if !models.IsSalaryGroupField(field) {
+			respondError(w, http.StatusBadRequest, "Invalid group_by "+field+", expected any of "+strings.Join(models.SalaryGroupFields, ", "))
+			return
+		}
+		if seen[field] {
+			respondError(w, http.StatusBadRequest, "Duplicate group_by "+field)
+			return
+		}
+		seen[field] = true
+	}
+
+	if v := q.Get("min_samples"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			respondError(w, http.StatusBadRequest, "Invalid min_samples, expected a positive number")
+			return
+		}
+		query.MinSamples = n
+	}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxSalaryGroups {
+			respondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid limit, expected 1 to %d", maxSalaryGroups))
+			return
+		}
+		query.Limit = n
+	}
+
+	if !parseFilters(w, q, query.Filter) {
+		return
+	}
+
+	groups, err := h.jobService.SalaryStats(r.Context(), query)
+	if err != nil {
+		respondStoreError(w, err, "Failed to compute salary stats")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"group_by":    query.GroupBy,
+		"min_samples": query.MinSamples,
+		"groups":      groups,
+	})
+}
+
+// ListCompanies lists canonical companies with their job counts
+func (h *Handler) ListCompanies(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	page, _ := strconv.Atoi(q.Get("page"))
+	limit, _ := strconv.Atoi(q.Get("limit"))
+
+	if limit == 0 {
+		limit = 20
+	}
+
+	query := &models.CompanySearchQuery{
+		Name:  q.Get("q"),
+		Page:  page,
+		Limit: limit,
+	}
+
+	result, err := h.companyService.ListCompanies(r.Context(), query)
+	if err != nil {
+		respondStoreError(w, err, "Failed to fetch companies")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"companies": result.Companies,
+		"page":      page,
+		"limit":     limit,
+		"total":     result.Total,
+	})
+}
+
+// ListCompanyJobs lists the jobs of a single company
+func (h *Handler) ListCompanyJobs(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid company ID")
+		return
+	}
+
+	company, err := h.companyService.GetCompany(r.Context(), id)
+	if errors.Is(err, repository.ErrNotSupported) {
+		respondStoreError(w, err, "")
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Company not found")
+		return
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	if limit == 0 {
+		limit = 20
+	}
+
+	format, ok := descriptionFormat(w, r)
+	if !ok {
+		return
+	}
+
+	query := &models.JobSearchQuery{
+		CompanyID: id,
+		Page:      page,
+		Limit:     limit,
+	}
+
+	if !parseCursor(w, r, query) {
+		return
+	}
+
+	result, err := h.jobService.SearchJobsPage(r.Context(), query)
+	if err != nil {
+		respondStoreError(w, err, "Failed to fetch jobs")
+		return
+	}
+	applyDescriptionFormat(format, result.Jobs...)
+
+	response := map[string]interface{}{
+		"company": company,
+		"jobs":    result.Jobs,
+		"page":    page,
+		"limit":   limit,
+	}
+	addPagination(w, r, result, response)
+
+	respondJSON(w, http.StatusOK, response)
+}
+
+// ListFlaggedJobs lists the flagged jobs awaiting review, newest first.
+// ?flagged=only lists every suspicious job instead, reviewed ones included.
+func (h *Handler) ListFlaggedJobs(w http.ResponseWriter, r *http.Request) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	if limit == 0 {
+		limit = 20
+	}
+
+	query := &models.JobSearchQuery{
+		Flagged: models.FlaggedUnreviewed,
+		Status:  "all",
+		Page:    page,
+		Limit:   limit,
+	}
+
+	switch flagged := r.URL.Query().Get("flagged"); flagged {
+	case "", models.FlaggedUnreviewed:
+	case models.FlaggedOnly:
+		query.Flagged = flagged
+	default:
+		respondError(w, http.StatusBadRequest, "Invalid flagged, expected unreviewed or only")
+		return
+	}
+
+	if !parseCursor(w, r, query) {
+		return
+	}
+
+	result, err := h.jobService.SearchJobsPage(r.Context(), query)
+	if err != nil {
+		respondStoreError(w, err, "Failed to fetch flagged jobs")
+		return
+	}
+
+	response := map[string]interface{}{
+		"jobs":  result.Jobs,
+		"page":  page,
+		"limit": limit,
+	}
+	addPagination(w, r, result, response)
+
+	respondJSON(w, http.StatusOK, response)
+}
+
+// ReviewFlag records an admin's verdict on a job: scam hides it from
+// searches, legit clears its flag
+func (h *Handler) ReviewFlag(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid job ID")
+		return
+	}
+
+	var req struct {
+		Verdict string `json:"verdict"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.Verdict != models.FlagReviewScam && req.Verdict != models.FlagReviewLegit {
+		respondError(w, http.StatusBadRequest, "Invalid verdict, expected scam or legit")
+		return
+	}
+
+	job, err := h.jobService.ReviewFlag(r.Context(), id, req.Verdict)
+	if errors.Is(err, repository.ErrJobNotFound) {
+		respondError(w, http.StatusNotFound, "Job not found")
+		return
+	}
+	if err != nil {
+		respondStoreError(w, err, "Failed to review flag")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, job)
+}
+
 // RunScraper triggers the scraper
 func (h *Handler) RunScraper(w http.ResponseWriter, r *http.Request) {
 	var req struct {
@@ -195,6 +634,97 @@ func (h *Handler) GetScraperStatus(w http.ResponseWriter, r *http.Request) {
 		"errors":       stats.Errors,
 		"start_time":   stats.StartTime,
 		"end_time":     stats.EndTime,
+		"source_runs":  stats.SourceRuns,
+	})
+}
+
+// GetSourceHealth returns the latest run of every source with the
+// anomalies found against its baseline
+func (h *Handler) GetSourceHealth(w http.ResponseWriter, r *http.Request) {
+	sources, err := h.jobService.SourceHealth(r.Context())
+	if err != nil {
+		respondStoreError(w, err, "Failed to fetch source health")
+		return
+	}
+
+	healthy := true
+	for _, source := range sources {
+		if source.Status != models.SourceHealthy {
+			healthy = false
+		}
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"healthy": healthy,
+		"sources": sources,
+	})
+}
+
+// ListSourceRuns lists the latest runs of a source
+func (h *Handler) ListSourceRuns(w http.ResponseWriter, r *http.Request) {
+	source := mux.Vars(r)["source"]
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	runs, err := h.jobService.SourceRuns(r.Context(), source, limit)
+	if err != nil {
+		respondStoreError(w, err, "Failed to fetch source runs")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"source": source,
+		"runs":   runs,
+		"total":  len(runs),
+	})
+}
+
+// GetRetentionRules returns the configured retention rules
+func (h *Handler) GetRetentionRules(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"rules": h.retentionService.Rules(),
+	})
+}
+
+// RunRetention applies the retention rules. It is a dry run, reporting
+// what each rule would delete, unless dry_run=false is given.
+func (h *Handler) RunRetention(w http.ResponseWriter, r *http.Request) {
+	dryRun := true
+	if v := r.URL.Query().Get("dry_run"); v != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(v); err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid dry_run, expected true or false")
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Minute)
+	defer cancel()
+
+	run, err := h.retentionService.Run(ctx, dryRun)
+	if err != nil {
+		respondJSON(w, http.StatusInternalServerError, map[string]interface{}{
+			"error": fmt.Sprintf("Retention run failed: %v", err),
+			"run":   run,
+		})
+		return
+	}
+
+	respondJSON(w, http.StatusOK, run)
+}
+
+// ListRetentionRuns lists the audit log of retention runs
+func (h *Handler) ListRetentionRuns(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	runs, err := h.retentionService.ListRuns(r.Context(), limit)
+	if err != nil {
+		respondStoreError(w, err, "Failed to fetch retention runs")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"runs":  runs,
+		"total": len(runs),
 	})
 }
 
@@ -219,6 +749,303 @@ func respondError(w http.ResponseWriter, status int, message string) {
 	respondJSON(w, status, map[string]string{"error": message})
 }
 
+// respondStoreError responds 501 when the configured store does not
+// support a feature, and 500 with message for other errors
+func respondStoreError(w http.ResponseWriter, err error, message string) {
+	if errors.Is(err, repository.ErrNotSupported) {
+		respondError(w, http.StatusNotImplemented, err.Error())
+		return
+	}
+	respondError(w, http.StatusInternalServerError, message)
+}
+
+// descriptionFormat reads ?description_format, writing a 400 response and
+// returning false when the value is not supported
+func descriptionFormat(w http.ResponseWriter, r *http.Request) (string, bool) {
+	format := r.URL.Query().Get("description_format")
+	if format == "" {
+		return sanitize.FormatText, true
+	}
+	if !sanitize.IsFormat(format) {
+		respondError(w, http.StatusBadRequest, "Invalid description_format (use html, markdown or text)")
+		return "", false
+	}
+	return format, true
+}
+
+// applyDescriptionFormat puts the requested rendition in each job's description
+func applyDescriptionFormat(format string, jobs ...*models.Job) {
+	for _, job := range jobs {
+		job.Description = sanitize.Select(format, job.Description, job.DescriptionHTML, job.DescriptionMarkdown)
+	}
+}
+
+// respondTimeSeriesCSV writes time series points as CSV with one row per
+// bucket
+func respondTimeSeriesCSV(w http.ResponseWriter, points []models.TimeSeriesPoint) {
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", `attachment; filename="timeseries.csv"`)
+	w.WriteHeader(http.StatusOK)
+
+	out := csv.NewWriter(w)
+	out.Write([]string{"start", "new", "active"})
+	for _, p := range points {
+		out.Write([]string{
+			p.Start.Format("2006-01-02"),
+			strconv.FormatInt(p.New, 10),
+			strconv.FormatInt(p.Active, 10),
+		})
+	}
+	out.Flush()
+}
+
+// parseList flattens repeated and comma-separated query values
+func parseList(values []string) []string {
+	var result []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				result = append(result, item)
+			}
+		}
+	}
+	return result
+}
+
+// parseFilters reads the search filters shared by search and statistics
+// into the query, writing a 400 response and returning false when one is
+// malformed
+func parseFilters(w http.ResponseWriter, q url.Values, query *models.JobSearchQuery) bool {
+	query.Keywords = q.Get("q")
+	query.Location = q.Get("location")
+	query.JobType = q.Get("type")
+	query.Source = q.Get("source")
+
+	if companyID := q.Get("company_id"); companyID != "" {
+		id, err := strconv.ParseInt(companyID, 10, 64)
+		if err != nil || id <= 0 {
+			respondError(w, http.StatusBadRequest, "Invalid company_id")
+			return false
+		}
+		query.CompanyID = id
+	}
+
+	if seniority := q.Get("seniority"); seniority != "" {
+		if !titles.IsSeniority(seniority) {
+			respondError(w, http.StatusBadRequest, "Invalid seniority")
+			return false
+		}
+		query.Seniority = seniority
+	}
+
+	if roleFamily := q.Get("role_family"); roleFamily != "" {
+		if !titles.IsRoleFamily(roleFamily) {
+			respondError(w, http.StatusBadRequest, "Invalid role family")
+			return false
+		}
+		query.RoleFamily = roleFamily
+	}
+
+	query.Skills = parseList(q["skills"])
+	query.SkillsAny = parseList(q["skills_any"])
+
+	switch status := q.Get("status"); status {
+	case "", models.JobStatusOpen, models.JobStatusClosed, "all":
+		query.Status = status
+	default:
+		respondError(w, http.StatusBadRequest, "Invalid status, expected open, closed or all")
+		return false
+	}
+
+	if flagged := q.Get("flagged"); models.IsFlaggedFilter(flagged) {
+		query.Flagged = flagged
+	} else {
+		respondError(w, http.StatusBadRequest, "Invalid flagged, expected include, only or unreviewed")
+		return false
+	}
+
+	if qx := q.Get("qx"); qx != "" {
+		expr, err := querylang.Parse(qx)
+		if err != nil {
+			var perr *querylang.Error
+			if errors.As(err, &perr) {
+				respondJSON(w, http.StatusBadRequest, map[string]interface{}{
+					"error":    "Invalid qx: " + perr.Msg,
+					"position": perr.Pos + 1,
+				})
+				return false
+			}
+			respondError(w, http.StatusBadRequest, "Invalid qx")
+			return false
+		}
+		query.Expr = expr
+
+		// The default of open jobs would hide what status:closed asks for
+		if query.Status == "" && querylang.HasField(expr, "status") {
+			query.Status = "all"
+		}
+	}
+
+	if err := parseDateFilters(q, query); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return false
+	}
+
+	if minSalary := q.Get("min_salary"); minSalary != "" {
+		parsed, err := salary.Parse(minSalary)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid min_salary, expected a yearly amount like 150000 or 150k")
+			return false
+		}
+		query.MinSalary = int(parsed.Min)
+	}
+
+	if v := q.Get("min_quality"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 || n > models.MaxQualityScore {
+			respondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid min_quality, expected 0 to %d", models.MaxQualityScore))
+			return false
+		}
+		query.MinQuality = n
+	}
+
+	if remote := q.Get("remote"); remote == "true" {
+		t := true
+		query.Remote = &t
+	} else if remote == "false" {
+		f := false
+		query.Remote = &f
+	}
+	return true
+}
+
+// parseSort reads ?sort, ?order and ?near into the query
+func parseSort(q url.Values, query *models.JobSearchQuery) error {
+	switch sort := q.Get("sort"); sort {
+	case "", models.SortNewest, models.SortPostedAt, models.SortScrapedAt, models.SortSalary, models.SortCompany:
+		query.Sort = sort
+	case models.SortRelevance:
+		if query.Keywords == "" {
+			return fmt.Errorf("sort=relevance requires q")
+		}
+		query.Sort = sort
+	case models.SortDistance:
+		near := q.Get("near")
+		if near == "" {
+			return fmt.Errorf("sort=distance requires near, as lat,lng or a city name")
+		}
+		point, err := geo.ParsePoint(near)
+		if err != nil {
+			var ok bool
+			if point, ok = geo.Locate(near); !ok {
+				return fmt.Errorf("Unknown near location %q, use lat,lng", near)
+			}
+		}
+		query.Sort = sort
+		query.Near = &point
+	default:
+		return fmt.Errorf("Invalid sort, expected posted_at, scraped_at, salary, company, relevance or distance")
+	}
+
+	switch order := q.Get("order"); order {
+	case "", models.OrderAsc, models.OrderDesc:
+		query.Order = order
+	default:
+		return fmt.Errorf("Invalid order, expected asc or desc")
+	}
+	return nil
+}
+
+// parseDateFilters reads the date range filters into the query
+func parseDateFilters(q url.Values, query *models.JobSearchQuery) error {
+	filters := []struct {
+		param string
+		dest  *time.Time
+	}{
+		{"posted_after", &query.PostedAfter},
+		{"posted_before", &query.PostedBefore},
+		{"updated_since", &query.UpdatedSince},
+		{"salary_changed_since", &query.SalaryChangedSince},
+	}
+
+	for _, f := range filters {
+		if value := q.Get(f.param); value != "" {
+			t, err := parseTime(value)
+			if err != nil {
+				return fmt.Errorf("Invalid %s, expected RFC 3339 time or YYYY-MM-DD", f.param)
+			}
+			*f.dest = t
+		}
+	}
+
+	if !query.PostedAfter.IsZero() && !query.PostedBefore.IsZero() && !query.PostedAfter.Before(query.PostedBefore) {
+		return fmt.Errorf("posted_after must be before posted_before")
+	}
+	return nil
+}
+
+// parseCursor reads ?cursor into the query, writing a 400 response and
+// returning false when it is malformed
+func parseCursor(w http.ResponseWriter, r *http.Request, query *models.JobSearchQuery) bool {
+	value := r.URL.Query().Get("cursor")
+	if value == "" {
+		return true
+	}
+
+	cursor, err := models.DecodeCursor(value)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid cursor")
+		return false
+	}
+	query.Cursor = cursor
+	return true
+}
+
+// addPagination adds the total and cursors of a result page to the
+// response, and links the neighbouring pages in an RFC 8288 Link header
+func addPagination(w http.ResponseWriter, r *http.Request, page *models.JobPage, response map[string]interface{}) {
+	response["total"] = page.Total
+	response["total_exact"] = page.TotalExact
+
+	var links []string
+	for _, link := range []struct{ rel, cursor string }{
+		{"next", page.NextCursor},
+		{"prev", page.PrevCursor},
+	} {
+		if link.cursor == "" {
+			continue
+		}
+		response[link.rel+"_cursor"] = link.cursor
+
+		q := r.URL.Query()
+		q.Del("page")
+		q.Set("cursor", link.cursor)
+		links = append(links, fmt.Sprintf(`<%s?%s>; rel="%s"`, r.URL.Path, q.Encode(), link.rel))
+	}
+
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+}
+
+// isFacetField reports whether name is a supported search facet
+func isFacetField(name string) bool {
+	for _, field := range models.FacetFields {
+		if field == name {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTime parses a query parameter given as an RFC 3339 time or a date
+func parseTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
+
 // Middleware
 
 func loggingMiddleware(next http.Handler) http.Handler {
@@ -230,6 +1057,26 @@ func loggingMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// adminMiddleware only passes requests carrying the admin token as a
+// bearer token
+func (h *Handler) adminMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h.adminToken == "" {
+			respondError(w, http.StatusForbidden, "Admin routes are disabled, set ADMIN_TOKEN to enable them")
+			return
+		}
+
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(h.adminToken)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+			respondError(w, http.StatusUnauthorized, "Invalid or missing admin token")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
 func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
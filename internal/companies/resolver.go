@@ -0,0 +1,223 @@
+package companies
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/abhisheksainimitawa/job-aggregator/internal/models"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/repository"
+	"github.com/abhisheksainimitawa/job-aggregator/pkg/logger"
+)
+
+// legalSuffixes are corporate designators dropped from the end of names
+var legalSuffixes = map[string]bool{
+	"inc": true, "incorporated": true, "llc": true, "ltd": true, "limited": true,
+	"corp": true, "corporation": true, "co": true, "gmbh": true, "plc": true,
+	"ag": true, "sa": true, "sas": true, "bv": true, "nv": true, "pty": true,
+	"pte": true, "lp": true, "llp": true, "srl": true, "oy": true, "ab": true,
+}
+
+// AliasEntry is one company in the editable alias list
+type AliasEntry struct {
+	Name     string          `json:"name"`
+	Domain   string          `json:"domain,omitempty"`
+	Aliases  []string        `json:"aliases,omitempty"`
+	Metadata json.RawMessage `json:"metadata,omitempty"`
+}
+
+// Resolver links raw company strings to canonical companies
+type Resolver struct {
+	repo  *repository.CompanyRepository
+	mu    sync.Mutex
+	cache map[string]int64
+}
+
+// NewResolver creates a new company resolver
+func NewResolver(repo *repository.CompanyRepository) *Resolver {
+	return &Resolver{
+		repo:  repo,
+		cache: make(map[string]int64),
+	}
+}
+
+// Normalize reduces a company name to its matching key: lowercased,
+// punctuation removed, "&" spelled out and legal suffixes dropped, so
+// "Google LLC" and "Google, Inc." both become "google"
+func Normalize(name string) string {
+	words := splitWords(strings.ToLower(name))
+	if len(words) > 1 && words[0] == "the" {
+		words = words[1:]
+	}
+	return strings.Join(trimSuffixes(words), " ")
+}
+
+// Clean strips legal suffixes from a display name while keeping its casing
+func Clean(name string) string {
+	words := splitWords(name)
+	lower := make([]string, len(words))
+	for i, w := range words {
+		lower[i] = strings.ToLower(w)
+	}
+	kept := len(trimSuffixes(lower))
+	if kept == 0 {
+		return strings.TrimSpace(name)
+	}
+	return strings.Join(words[:kept], " ")
+}
+
+// Resolve returns the ID of the canonical company for a raw company name,
+// creating the company on first sight
+func (r *Resolver) Resolve(ctx context.Context, name string) (int64, error) {
+	key := Normalize(name)
+	if key == "" {
+		return 0, fmt.Errorf("empty company name")
+	}
+
+	r.mu.Lock()
+	id, ok := r.cache[key]
+	r.mu.Unlock()
+	if ok {
+		return id, nil
+	}
+
+	id, found, err := r.repo.FindIDByAlias(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+
+	if !found {
+		id, err = r.repo.FindOrCreate(ctx, Clean(name), key)
+		if err != nil {
+			return 0, err
+		}
+		if err := r.repo.AddAlias(ctx, id, strings.TrimSpace(name), key, false); err != nil {
+			return 0, err
+		}
+	}
+
+	r.mu.Lock()
+	r.cache[key] = id
+	r.mu.Unlock()
+
+	return id, nil
+}
+
+// LoadAliases reads the editable alias list from a JSON file
+func LoadAliases(path string) ([]AliasEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read company aliases: %w", err)
+	}
+
+	var entries []AliasEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse company aliases: %w", err)
+	}
+
+	return entries, nil
+}
+
+// Sync applies the alias list to the database and links the stored jobs
+// that have no company yet. Aliases from the list take precedence over
+// automatically learned ones; jobs linked before a list change keep their
+// company until Relink runs.
+func (r *Resolver) Sync(ctx context.Context, entries []AliasEntry) error {
+	for _, entry := range entries {
+		key := Normalize(entry.Name)
+		if key == "" {
+			continue
+		}
+
+		company := &models.Company{
+			Name:     entry.Name,
+			Domain:   entry.Domain,
+			Metadata: entry.Metadata,
+		}
+		if err := r.repo.Upsert(ctx, company, key); err != nil {
+			return err
+		}
+
+		for _, alias := range append([]string{entry.Name}, entry.Aliases...) {
+			aliasKey := Normalize(alias)
+			if aliasKey == "" {
+				continue
+			}
+			if err := r.repo.AddAlias(ctx, company.ID, alias, aliasKey, true); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Forget cached lookups that the list may have changed
+	r.mu.Lock()
+	r.cache = make(map[string]int64)
+	r.mu.Unlock()
+
+	return r.link(ctx, true)
+}
+
+// Relink points every stored job at the company its name now resolves to,
+// so jobs follow changed aliases. It updates the jobs of every company
+// name and is run on demand rather than on every start.
+func (r *Resolver) Relink(ctx context.Context) error {
+	return r.link(ctx, false)
+}
+
+// link points stored jobs at the companies their names resolve to, only
+// the jobs without a company if unlinked is set
+func (r *Resolver) link(ctx context.Context, unlinked bool) error {
+	names, err := r.repo.CompanyNames(ctx, unlinked)
+	if err != nil {
+		return err
+	}
+
+	var linked int64
+	for _, name := range names {
+		id, err := r.Resolve(ctx, name)
+		if err != nil {
+			logger.Error("Failed to resolve company %q: %v", name, err)
+			continue
+		}
+		count, err := r.repo.LinkJobs(ctx, name, id, unlinked)
+		if err != nil {
+			return err
+		}
+		linked += count
+	}
+
+	if linked > 0 {
+		logger.Info("Linked %d jobs to canonical companies", linked)
+	}
+	return nil
+}
+
+// splitWords splits a name on anything that is not a letter, digit or "&",
+// spelling "&" out as "and"
+func splitWords(name string) []string {
+	fields := strings.FieldsFunc(name, func(r rune) bool {
+		return !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == '&')
+	})
+
+	words := make([]string, 0, len(fields))
+	for _, f := range fields {
+		if f == "&" {
+			f = "and"
+		}
+		words = append(words, f)
+	}
+	return words
+}
+
+// trimSuffixes drops trailing legal suffixes ("Pty Ltd") from lowercased
+// words, always keeping at least the first word
+func trimSuffixes(words []string) []string {
+	for len(words) > 1 && legalSuffixes[words[len(words)-1]] {
+		words = words[:len(words)-1]
+	}
+	return words
+}
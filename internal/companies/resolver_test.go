@@ -0,0 +1,42 @@
+package companies
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Google", "google"},
+		{"Google LLC", "google"},
+		{"Google, Inc.", "google"},
+		{"The Boring Company", "boring company"},
+		{"Acme Pty Ltd", "acme"},
+		{"Procter & Gamble Co.", "procter and gamble"},
+		{"Inc", "inc"},
+		{"  ", ""},
+	}
+
+	for _, tt := range tests {
+		if got := Normalize(tt.name); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestClean(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Google LLC", "Google"},
+		{"CloudSystems Inc", "CloudSystems"},
+		{"Stripe", "Stripe"},
+	}
+
+	for _, tt := range tests {
+		if got := Clean(tt.name); got != tt.want {
+			t.Errorf("Clean(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -9,25 +9,43 @@ import (
 
 // Config holds all application configuration
 type Config struct {
-	Database DatabaseConfig
-	Server   ServerConfig
-	Scraper  ScraperConfig
+	Database   DatabaseConfig
+	Server     ServerConfig
+	Scraper    ScraperConfig
+	Tagging    TaggingConfig
+	Companies  CompaniesConfig
+	Lifecycle  LifecycleConfig
+	Partitions PartitionsConfig
+	Retention  RetentionConfig
+	Stats      StatsConfig
+	Health     HealthConfig
+	Scam       ScamConfig
 }
 
+// Job store drivers
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+	DriverMemory   = "memory"
+)
+
 // DatabaseConfig holds database configuration
 type DatabaseConfig struct {
-	Host     string
-	Port     int
-	User     string
-	Password string
-	DBName   string
-	SSLMode  string
+	Driver     string // postgres (default), sqlite or memory
+	Host       string
+	Port       int
+	User       string
+	Password   string
+	DBName     string
+	SSLMode    string
+	SQLitePath string // Database file of the sqlite driver
 }
 
 // ServerConfig holds server configuration
 type ServerConfig struct {
-	Host string
-	Port int
+	Host       string
+	Port       int
+	AdminToken string // Bearer token of the admin routes, which are disabled without one
 }
 
 // ScraperConfig holds scraper configuration
@@ -37,26 +55,123 @@ type ScraperConfig struct {
 	Timeout    time.Duration
 }
 
+// TaggingConfig holds skill tagging configuration
+type TaggingConfig struct {
+	DictionaryPath string // Optional JSON file extending the built-in skills
+}
+
+// CompaniesConfig holds company canonicalization configuration
+type CompaniesConfig struct {
+	AliasesPath string // Optional JSON file with canonical names and aliases
+}
+
+// LifecycleConfig holds listing expiry configuration
+type LifecycleConfig struct {
+	CloseAfterRuns int           // Consecutive missed runs before a job is closed
+	VerifyURLs     bool          // Re-fetch listing URLs before closing
+	VerifyTimeout  time.Duration // Timeout per re-fetch
+}
+
+// PartitionsConfig holds jobs table partitioning configuration
+type PartitionsConfig struct {
+	MonthsAhead   int    // Months of partitions created ahead of the current one
+	ArchiveDir    string // Directory of archived partitions
+	ArchiveFormat string // ndjson or parquet
+}
+
+// RetentionConfig holds job retention configuration
+type RetentionConfig struct {
+	RulesPath string        // Optional JSON file with retention rules
+	Interval  time.Duration // Time between scheduled runs
+	BatchSize int           // Jobs deleted per statement
+	DryRun    bool          // Only report what scheduled runs would delete
+}
+
+// StatsConfig holds job statistics configuration
+type StatsConfig struct {
+	RefreshInterval time.Duration // Time between scheduled refreshes
+}
+
+// HealthConfig holds source health monitoring configuration
+type HealthConfig struct {
+	BaselineRuns int     // Runs averaged into a source's baseline
+	VolumeDrop   float64 // Fraction of the baseline job count a run may lose
+	WebhookURL   string  // Optional URL alerts are posted to
+}
+
+// ScamConfig holds scam and spam detection configuration
+type ScamConfig struct {
+	RulesPath  string  // Optional JSON file extending the built-in rules
+	LabelsPath string  // JSON Lines file of labeled examples; flag reviews are appended
+	Classifier bool    // Train the classifier on the labels at startup
+	Threshold  float64 // Scam probability from which the classifier flags a job
+}
+
 // Load loads configuration from environment variables
 func Load() (*Config, error) {
 	config := &Config{
 		Database: DatabaseConfig{
-			Host:     getEnv("DB_HOST", "localhost"),
-			Port:     getEnvAsInt("DB_PORT", 5432),
-			User:     getEnv("DB_USER", "postgres"),
-			Password: getEnv("DB_PASSWORD", "postgres"),
-			DBName:   getEnv("DB_NAME", "job_aggregator"),
-			SSLMode:  getEnv("DB_SSLMODE", "disable"),
+			Driver:     getEnv("DB_DRIVER", DriverPostgres),
+			Host:       getEnv("DB_HOST", "localhost"),
+			Port:       getEnvAsInt("DB_PORT", 5432),
+			User:       getEnv("DB_USER", "postgres"),
+			Password:   getEnv("DB_PASSWORD", "postgres"),
+			DBName:     getEnv("DB_NAME", "job_aggregator"),
+			SSLMode:    getEnv("DB_SSLMODE", "disable"),
+			SQLitePath: getEnv("SQLITE_PATH", "job_aggregator.db"),
 		},
 		Server: ServerConfig{
-			Host: getEnv("SERVER_HOST", "0.0.0.0"),
-			Port: getEnvAsInt("SERVER_PORT", 8080),
+			Host:       getEnv("SERVER_HOST", "0.0.0.0"),
+			Port:       getEnvAsInt("SERVER_PORT", 8080),
+			AdminToken: getEnv("ADMIN_TOKEN", ""),
 		},
 		Scraper: ScraperConfig{
 			Workers:   getEnvAsInt("SCRAPER_WORKERS", 10),
 			RateLimit: getEnvAsInt("SCRAPER_RATE_LIMIT", 100),
 			Timeout:   time.Duration(getEnvAsInt("SCRAPER_TIMEOUT", 30)) * time.Second,
 		},
+		Tagging: TaggingConfig{
+			DictionaryPath: getEnv("SKILLS_DICTIONARY", ""),
+		},
+		Companies: CompaniesConfig{
+			AliasesPath: getEnv("COMPANY_ALIASES", ""),
+		},
+		Lifecycle: LifecycleConfig{
+			CloseAfterRuns: getEnvAsInt("LIFECYCLE_CLOSE_AFTER_RUNS", 3),
+			VerifyURLs:     getEnvAsBool("LIFECYCLE_VERIFY_URLS", false),
+			VerifyTimeout:  time.Duration(getEnvAsInt("LIFECYCLE_VERIFY_TIMEOUT", 10)) * time.Second,
+		},
+		Partitions: PartitionsConfig{
+			MonthsAhead:   getEnvAsInt("PARTITION_MONTHS_AHEAD", 3),
+			ArchiveDir:    getEnv("ARCHIVE_DIR", "archive"),
+			ArchiveFormat: getEnv("ARCHIVE_FORMAT", "ndjson"),
+		},
+		Retention: RetentionConfig{
+			RulesPath: getEnv("RETENTION_RULES", ""),
+			Interval:  time.Duration(getEnvAsInt("RETENTION_INTERVAL", 24)) * time.Hour,
+			BatchSize: getEnvAsInt("RETENTION_BATCH_SIZE", 1000),
+			DryRun:    getEnvAsBool("RETENTION_DRY_RUN", false),
+		},
+		Stats: StatsConfig{
+			RefreshInterval: time.Duration(getEnvAsInt("STATS_REFRESH_INTERVAL", 15)) * time.Minute,
+		},
+		Health: HealthConfig{
+			BaselineRuns: getEnvAsInt("HEALTH_BASELINE_RUNS", 10),
+			VolumeDrop:   float64(getEnvAsInt("HEALTH_VOLUME_DROP", 70)) / 100,
+			WebhookURL:   getEnv("HEALTH_WEBHOOK_URL", ""),
+		},
+		Scam: ScamConfig{
+			RulesPath:  getEnv("SCAM_RULES", ""),
+			LabelsPath: getEnv("SCAM_LABELS", "scam_labels.jsonl"),
+			Classifier: getEnvAsBool("SCAM_CLASSIFIER", false),
+			Threshold:  float64(getEnvAsInt("SCAM_CLASSIFIER_THRESHOLD", 90)) / 100,
+		},
+	}
+
+	switch config.Database.Driver {
+	case DriverPostgres, DriverSQLite, DriverMemory:
+	default:
+		return nil, fmt.Errorf("invalid DB_DRIVER %q, expected postgres, sqlite or memory", config.Database.Driver)
 	}
 
 	return config, nil
@@ -94,3 +209,11 @@ func getEnvAsInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+func getEnvAsBool(key string, defaultValue bool) bool {
+	valueStr := getEnv(key, "")
+	if value, err := strconv.ParseBool(valueStr); err == nil {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,147 @@
+package dedup
+
+import (
+	"context"
+
+	"github.com/abhisheksainimitawa/job-aggregator/internal/companies"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/models"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/repository"
+	"github.com/abhisheksainimitawa/job-aggregator/pkg/logger"
+)
+
+// Clusterer groups stored jobs into canonical job clusters
+type Clusterer struct {
+	repo *repository.JobRepository
+}
+
+// NewClusterer creates a new clusterer
+func NewClusterer(repo *repository.JobRepository) *Clusterer {
+	return &Clusterer{repo: repo}
+}
+
+// Assign fingerprints stored jobs and attaches each one to the cluster of
+// its nearest near-duplicate from the same company. Jobs without a match
+// start their own cluster and become its canonical job. The batch is
+// written and matched with a few set-based statements; jobs are matched
+// in batch order, each seeing the batch jobs matched before it.
+func (c *Clusterer) Assign(ctx context.Context, jobs []*models.Job) error {
+	var stored []*models.Job
+	var fps, unclustered []repository.JobFingerprint
+	for _, job := range jobs {
+		if job.ID == 0 {
+			continue
+		}
+
+		fp := Fingerprint(job)
+		job.Fingerprint = int64(fp)
+		stored = append(stored, job)
+		fps = append(fps, repository.JobFingerprint{JobID: job.ID, Fingerprint: job.Fingerprint, Bands: BandsOf(fp)})
+
+		// Re-scraped jobs keep the cluster they already belong to
+		if job.ClusterID == nil {
+			unclustered = append(unclustered, fps[len(fps)-1])
+		}
+	}
+
+	if err := c.repo.SetFingerprints(ctx, fps); err != nil {
+		return err
+	}
+	candidates, err := c.repo.FindClusterCandidates(ctx, unclustered)
+	if err != nil {
+		return err
+	}
+
+	batch := make(map[int64]*models.Job, len(stored))
+	for _, job := range stored {
+		batch[job.ID] = job
+	}
+
+	var ids, clusterIDs []int64
+	var merged int
+	for _, job := range stored {
+		if job.ClusterID != nil {
+			continue
+		}
+
+		// Batch jobs count with the cluster assigned here, once matched
+		visible := make([]repository.ClusterCandidate, 0, len(candidates[job.ID]))
+		for _, cand := range candidates[job.ID] {
+			if other, ok := batch[cand.ID]; ok {
+				if other.ClusterID == nil {
+					continue
+				}
+				cand.ClusterID = other.ClusterID
+			}
+			visible = append(visible, cand)
+		}
+
+		clusterID := job.ID
+		if match, ok := nearest(job, uint64(job.Fingerprint), visible); ok {
+			clusterID = match.ID
+			if match.ClusterID != nil {
+				clusterID = *match.ClusterID
+			}
+			merged++
+		}
+
+		ids = append(ids, job.ID)
+		clusterIDs = append(clusterIDs, clusterID)
+		job.ClusterID = &clusterID
+	}
+
+	if err := c.repo.SetClusters(ctx, ids, clusterIDs); err != nil {
+		return err
+	}
+
+	if merged > 0 {
+		logger.Info("Merged %d jobs into existing job clusters", merged)
+	}
+	return nil
+}
+
+// Sources returns for each stored job the number of distinct sources
+// listing its cluster, across runs
+func (c *Clusterer) Sources(ctx context.Context, jobs []*models.Job) ([]int, error) {
+	ids := make([]int64, len(jobs))
+	for i, job := range jobs {
+		ids[i] = job.ID
+	}
+
+	counts, err := c.repo.ClusterSources(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	sources := make([]int, len(jobs))
+	for i, job := range jobs {
+		sources[i] = counts[job.ID]
+	}
+	return sources, nil
+}
+
+// nearest returns the closest candidate within Threshold that belongs to
+// the same company as the job
+func nearest(job *models.Job, fp uint64, candidates []repository.ClusterCandidate) (repository.ClusterCandidate, bool) {
+	var best repository.ClusterCandidate
+	bestDistance := Threshold + 1
+
+	for _, c := range candidates {
+		if !sameCompany(job, c) {
+			continue
+		}
+		if d := Distance(fp, uint64(c.Fingerprint)); d < bestDistance {
+			best, bestDistance = c, d
+		}
+	}
+
+	return best, bestDistance <= Threshold
+}
+
+// sameCompany compares canonical companies, falling back to normalized
+// names for jobs that were never linked to one
+func sameCompany(job *models.Job, c repository.ClusterCandidate) bool {
+	if job.CompanyID != nil && c.CompanyID != nil {
+		return *job.CompanyID == *c.CompanyID
+	}
+	return companies.Normalize(job.Company) == companies.Normalize(c.Company)
+}
@@ -0,0 +1,132 @@
+package dedup
+
+import (
+	"hash/fnv"
+	"math"
+	"math/bits"
+	"strings"
+	"unicode"
+
+	"github.com/abhisheksainimitawa/job-aggregator/internal/companies"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/models"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/titles"
+)
+
+// Threshold is the largest Hamming distance at which two fingerprints are
+// treated as the same job
+const Threshold = 3
+
+// Bands is the number of 16-bit bands a fingerprint is split into for
+// candidate lookup. Because Threshold < Bands, two fingerprints within
+// Threshold always share at least one band exactly.
+const Bands = 4
+
+// shingleSize is the number of words per description shingle
+const shingleSize = 3
+
+// Weight of each field in the fingerprint. A field's weight is divided by
+// the square root of its feature count, so every field pulls with about
+// the same strength however many features it has.
+const (
+	titleWeight       = 0.45
+	companyWeight     = 0.2
+	descriptionWeight = 0.35
+)
+
+// Fingerprint computes the 64-bit SimHash of a job's normalized title,
+// company and description. Reworded titles like "Sr. Go Developer" and
+// "Senior Golang Developer" normalize to the same features.
+func Fingerprint(job *models.Job) uint64 {
+	var v [64]float64
+
+	title := titles.Normalize(job.Title)
+	titleFeatures := append(strings.Fields(title.Normalized), "seniority:"+title.Seniority)
+	addFeatures(&v, "t:", titleFeatures, titleWeight)
+
+	if company := companies.Normalize(job.Company); company != "" {
+		addFeatures(&v, "c:", []string{company}, companyWeight)
+	}
+
+	addFeatures(&v, "d:", shingles(job.Description), descriptionWeight)
+
+	var fp uint64
+	for i, w := range v {
+		if w > 0 {
+			fp |= 1 << uint(i)
+		}
+	}
+	return fp
+}
+
+// Distance returns the number of differing bits between two fingerprints
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// Similar reports whether two fingerprints are within Threshold
+func Similar(a, b uint64) bool {
+	return Distance(a, b) <= Threshold
+}
+
+// BandsOf splits a fingerprint into its 16-bit bands
+func BandsOf(fp uint64) []int {
+	bands := make([]int, Bands)
+	for i := range bands {
+		bands[i] = int(fp >> (16 * uint(i)) & 0xffff)
+	}
+	return bands
+}
+
+// addFeatures adds the hashed features of one field to the bit vector
+func addFeatures(v *[64]float64, prefix string, features []string, weight float64) {
+	if len(features) == 0 {
+		return
+	}
+
+	w := weight / math.Sqrt(float64(len(features)))
+	for _, f := range features {
+		h := hash(prefix + f)
+		for i := range v {
+			if h&(1<<uint(i)) != 0 {
+				v[i] += w
+			} else {
+				v[i] -= w
+			}
+		}
+	}
+}
+
+// shingles returns the overlapping word n-grams of a text
+func shingles(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !(unicode.IsLetter(r) || unicode.IsDigit(r))
+	})
+
+	if len(words) < shingleSize {
+		if len(words) == 0 {
+			return nil
+		}
+		return []string{strings.Join(words, " ")}
+	}
+
+	out := make([]string, 0, len(words)-shingleSize+1)
+	for i := 0; i+shingleSize <= len(words); i++ {
+		out = append(out, strings.Join(words[i:i+shingleSize], " "))
+	}
+	return out
+}
+
+// hash is a 64-bit FNV-1a hash with a final mix, so short features that
+// differ in one character still spread over all bits
+func hash(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	x := h.Sum64()
+
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
@@ -0,0 +1,78 @@
+package dedup
+
+import (
+	"testing"
+
+	"github.com/abhisheksainimitawa/job-aggregator/internal/models"
+)
+
+const goDescription = `We are hiring a backend engineer to build distributed payment services in Go.
+You will own APIs end to end, work with PostgreSQL and Kafka, and mentor other engineers.
+Five years of experience with high throughput systems is expected.`
+
+func TestFingerprintRewordedTitle(t *testing.T) {
+	indeed := &models.Job{Title: "Sr. Go Developer", Company: "Acme Inc.", Description: goDescription}
+	linkedin := &models.Job{Title: "Senior Golang Developer", Company: "Acme", Description: goDescription}
+
+	if d := Distance(Fingerprint(indeed), Fingerprint(linkedin)); d > Threshold {
+		t.Errorf("Distance = %d, want <= %d for the same posting", d, Threshold)
+	}
+}
+
+func TestFingerprintSmallDescriptionEdit(t *testing.T) {
+	a := &models.Job{Title: "Backend Engineer", Company: "Acme", Description: goDescription}
+	b := &models.Job{Title: "Backend Engineer", Company: "Acme", Description: goDescription + " Apply today!"}
+
+	if d := Distance(Fingerprint(a), Fingerprint(b)); d > Threshold {
+		t.Errorf("Distance = %d, want <= %d after a small edit", d, Threshold)
+	}
+}
+
+func TestFingerprintDifferentPostings(t *testing.T) {
+	base := &models.Job{Title: "Backend Engineer", Company: "Acme", Description: goDescription}
+
+	tests := []struct {
+		name string
+		job  *models.Job
+	}{
+		{"same title, other company", &models.Job{Title: "Backend Engineer", Company: "Globex", Description: goDescription}},
+		{"same title, other description", &models.Job{
+			Title:       "Backend Engineer",
+			Company:     "Acme",
+			Description: "Join the data platform team maintaining our Spark pipelines and warehouse in Scala, on call one week per month.",
+		}},
+		{"other title", &models.Job{Title: "Frontend Engineer", Company: "Acme", Description: goDescription}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if d := Distance(Fingerprint(base), Fingerprint(tt.job)); d <= Threshold {
+				t.Errorf("Distance = %d, want > %d for different postings", d, Threshold)
+			}
+		})
+	}
+}
+
+func TestBandsOf(t *testing.T) {
+	fp := uint64(0x1234_5678_9abc_def0)
+	want := []int{0xdef0, 0x9abc, 0x5678, 0x1234}
+
+	got := BandsOf(fp)
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("band %d = %#x, want %#x", i, got[i], want[i])
+		}
+	}
+
+	// Flipping Threshold bits must leave at least one band unchanged
+	other := fp ^ (1 | 1<<17 | 1<<40)
+	shared := false
+	for i, b := range BandsOf(other) {
+		if b == got[i] {
+			shared = true
+		}
+	}
+	if !shared {
+		t.Error("Expected near fingerprints to share a band")
+	}
+}
@@ -0,0 +1,152 @@
+package geo
+
+// cities maps lowercase city names, optionally with their region, to
+// coordinates. It covers the metro areas most job boards post for; a
+// region-qualified entry disambiguates cities sharing a name.
+var cities = map[string]Point{
+	// United States
+	"san francisco":    {37.7749, -122.4194},
+	"oakland":          {37.8044, -122.2712},
+	"san jose":         {37.3382, -121.8863},
+	"palo alto":        {37.4419, -122.1430},
+	"menlo park":       {37.4530, -122.1817},
+	"mountain view":    {37.3861, -122.0839},
+	"sunnyvale":        {37.3688, -122.0363},
+	"cupertino":        {37.3230, -122.0322},
+	"los angeles":      {34.0522, -118.2437},
+	"san diego":        {32.7157, -117.1611},
+	"seattle":          {47.6062, -122.3321},
+	"bellevue":         {47.6101, -122.2015},
+	"redmond":          {47.6740, -122.1215},
+	"portland":         {45.5152, -122.6784},
+	"portland, me":     {43.6591, -70.2568},
+	"new york":         {40.7128, -74.0060},
+	"new york city":    {40.7128, -74.0060},
+	"brooklyn":         {40.6782, -73.9442},
+	"boston":           {42.3601, -71.0589},
+	"cambridge, ma":    {42.3736, -71.1097},
+	"washington":       {38.9072, -77.0369},
+	"washington, dc":   {38.9072, -77.0369},
+	"arlington":        {38.8816, -77.0910},
+	"philadelphia":     {39.9526, -75.1652},
+	"pittsburgh":       {40.4406, -79.9959},
+	"chicago":          {41.8781, -87.6298},
+	"detroit":          {42.3314, -83.0458},
+	"minneapolis":      {44.9778, -93.2650},
+	"denver":           {39.7392, -104.9903},
+	"boulder":          {40.0150, -105.2705},
+	"salt lake city":   {40.7608, -111.8910},
+	"phoenix":          {33.4484, -112.0740},
+	"las vegas":        {36.1699, -115.1398},
+	"austin":           {30.2672, -97.7431},
+	"dallas":           {32.7767, -96.7970},
+	"houston":          {29.7604, -95.3698},
+	"san antonio":      {29.4241, -98.4936},
+	"atlanta":          {33.7490, -84.3880},
+	"miami":            {25.7617, -80.1918},
+	"tampa":            {27.9506, -82.4572},
+	"orlando":          {28.5383, -81.3792},
+	"raleigh":          {35.7796, -78.6382},
+	"durham":           {35.9940, -78.8986},
+	"charlotte":        {35.2271, -80.8431},
+	"nashville":        {36.1627, -86.7816},
+	"columbus":         {39.9612, -82.9988},
+	"st. louis":        {38.6270, -90.1994},
+	"kansas city":      {39.0997, -94.5786},
+	"san mateo":        {37.5630, -122.3255},
+	"redwood city":     {37.4852, -122.2364},
+	"santa clara":      {37.3541, -121.9552},
+	"irvine":           {33.6846, -117.8265},
+	"jersey city":      {40.7178, -74.0431},
+	"baltimore":        {39.2904, -76.6122},
+	"cincinnati":       {39.1031, -84.5120},
+	"indianapolis":     {39.7684, -86.1581},
+	"madison":          {43.0731, -89.4012},
+	"ann arbor":        {42.2808, -83.7430},
+	"sacramento":       {38.5816, -121.4944},
+	"cambridge":        {52.2053, 0.1218},
+	"cambridge, uk":    {52.2053, 0.1218},
+	"vancouver":        {49.2827, -123.1207},
+	"vancouver, wa":    {45.6387, -122.6615},
+	"toronto":          {43.6532, -79.3832},
+	"montreal":         {45.5017, -73.5673},
+	"ottawa":           {45.4215, -75.6972},
+	"waterloo":         {43.4643, -80.5204},
+	"calgary":          {51.0447, -114.0719},
+	"mexico city":      {19.4326, -99.1332},
+	"sao paulo":        {-23.5505, -46.6333},
+	"são paulo":        {-23.5505, -46.6333},
+	"buenos aires":     {-34.6037, -58.3816},
+	"bogota":           {4.7110, -74.0721},
+	"santiago":         {-33.4489, -70.6693},
+	"london":           {51.5074, -0.1278},
+	"manchester":       {53.4808, -2.2426},
+	"edinburgh":        {55.9533, -3.1883},
+	"dublin":           {53.3498, -6.2603},
+	"paris":            {48.8566, 2.3522},
+	"berlin":           {52.5200, 13.4050},
+	"munich":           {48.1351, 11.5820},
+	"münchen":          {48.1351, 11.5820},
+	"hamburg":          {53.5511, 9.9937},
+	"frankfurt":        {50.1109, 8.6821},
+	"amsterdam":        {52.3676, 4.9041},
+	"rotterdam":        {51.9244, 4.4777},
+	"brussels":         {50.8503, 4.3517},
+	"zurich":           {47.3769, 8.5417},
+	"zürich":           {47.3769, 8.5417},
+	"geneva":           {46.2044, 6.1432},
+	"vienna":           {48.2082, 16.3738},
+	"madrid":           {40.4168, -3.7038},
+	"barcelona":        {41.3851, 2.1734},
+	"lisbon":           {38.7223, -9.1393},
+	"milan":            {45.4642, 9.1900},
+	"rome":             {41.9028, 12.4964},
+	"stockholm":        {59.3293, 18.0686},
+	"copenhagen":       {55.6761, 12.5683},
+	"oslo":             {59.9139, 10.7522},
+	"helsinki":         {60.1699, 24.9384},
+	"warsaw":           {52.2297, 21.0122},
+	"krakow":           {50.0647, 19.9450},
+	"prague":           {50.0755, 14.4378},
+	"budapest":         {47.4979, 19.0402},
+	"bucharest":        {44.4268, 26.1025},
+	"tallinn":          {59.4370, 24.7536},
+	"kyiv":             {50.4501, 30.5234},
+	"istanbul":         {41.0082, 28.9784},
+	"tel aviv":         {32.0853, 34.7818},
+	"dubai":            {25.2048, 55.2708},
+	"bangalore":        {12.9716, 77.5946},
+	"bengaluru":        {12.9716, 77.5946},
+	"hyderabad":        {17.3850, 78.4867},
+	"pune":             {18.5204, 73.8567},
+	"mumbai":           {19.0760, 72.8777},
+	"chennai":          {13.0827, 80.2707},
+	"delhi":            {28.7041, 77.1025},
+	"new delhi":        {28.6139, 77.2090},
+	"gurgaon":          {28.4595, 77.0266},
+	"gurugram":         {28.4595, 77.0266},
+	"noida":            {28.5355, 77.3910},
+	"singapore":        {1.3521, 103.8198},
+	"hong kong":        {22.3193, 114.1694},
+	"shanghai":         {31.2304, 121.4737},
+	"beijing":          {39.9042, 116.4074},
+	"shenzhen":         {22.5431, 114.0579},
+	"taipei":           {25.0330, 121.5654},
+	"seoul":            {37.5665, 126.9780},
+	"tokyo":            {35.6762, 139.6503},
+	"osaka":            {34.6937, 135.5023},
+	"sydney":           {-33.8688, 151.2093},
+	"melbourne":        {-37.8136, 144.9631},
+	"brisbane":         {-27.4698, 153.0251},
+	"auckland":         {-36.8485, 174.7633},
+	"cape town":        {-33.9249, 18.4241},
+	"johannesburg":     {-26.2041, 28.0473},
+	"lagos":            {6.5244, 3.3792},
+	"nairobi":          {-1.2921, 36.8219},
+	"cairo":            {30.0444, 31.2357},
+	"kuala lumpur":     {3.1390, 101.6869},
+	"jakarta":          {-6.2088, 106.8456},
+	"manila":           {14.5995, 120.9842},
+	"ho chi minh city": {10.8231, 106.6297},
+	"bangkok":          {13.7563, 100.5018},
+}
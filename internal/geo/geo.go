@@ -0,0 +1,66 @@
+package geo
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// earthRadiusKm is the mean radius of the earth
+const earthRadiusKm = 6371.0
+
+// Point is a WGS 84 coordinate in degrees
+type Point struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+}
+
+// Distance returns the great-circle distance between two points in km
+func Distance(a, b Point) float64 {
+	lat1, lat2 := a.Lat*math.Pi/180, b.Lat*math.Pi/180
+	dLat := lat2 - lat1
+	dLng := (b.Lng - a.Lng) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// ParsePoint parses "lat,lng"
+func ParsePoint(s string) (Point, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 2 {
+		return Point{}, fmt.Errorf("expected lat,lng, got %q", s)
+	}
+
+	lat, err1 := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	lng, err2 := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err1 != nil || err2 != nil || lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		return Point{}, fmt.Errorf("invalid coordinates %q", s)
+	}
+	return Point{Lat: lat, Lng: lng}, nil
+}
+
+// Locate finds the coordinates of a job location such as
+// "San Francisco, CA" or "London, UK" in the built-in gazetteer. Remote
+// and unknown locations are not found.
+func Locate(location string) (Point, bool) {
+	s := strings.ToLower(location)
+	if i := strings.IndexAny(s, "(/|"); i >= 0 {
+		s = s[:i]
+	}
+
+	// "City, Region, Country": try the city alone, then with its region
+	parts := strings.Split(s, ",")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+
+	if len(parts) > 1 {
+		if p, ok := cities[parts[0]+", "+parts[1]]; ok {
+			return p, true
+		}
+	}
+	p, ok := cities[parts[0]]
+	return p, ok
+}
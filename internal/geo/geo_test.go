@@ -0,0 +1,57 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLocate(t *testing.T) {
+	tests := []struct {
+		location string
+		want     string
+		found    bool
+	}{
+		{"San Francisco, CA", "san francisco", true},
+		{"New York, NY", "new york", true},
+		{"Portland, ME", "portland, me", true},
+		{"Cambridge, MA, USA", "cambridge, ma", true},
+		{"Berlin (Hybrid)", "berlin", true},
+		{"Remote", "", false},
+		{"Remote (US)", "", false},
+		{"Springfield", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := Locate(tt.location)
+		if ok != tt.found {
+			t.Errorf("Locate(%q) found = %v, want %v", tt.location, ok, tt.found)
+			continue
+		}
+		if ok && got != cities[tt.want] {
+			t.Errorf("Locate(%q) = %v, want %v", tt.location, got, cities[tt.want])
+		}
+	}
+}
+
+func TestDistance(t *testing.T) {
+	// San Francisco to New York is about 4,130 km
+	d := Distance(cities["san francisco"], cities["new york"])
+	if math.Abs(d-4130) > 20 {
+		t.Errorf("Distance() = %.0f km, want about 4130", d)
+	}
+	if d := Distance(cities["london"], cities["london"]); d != 0 {
+		t.Errorf("Distance() to itself = %f", d)
+	}
+}
+
+func TestParsePoint(t *testing.T) {
+	p, err := ParsePoint("37.77, -122.42")
+	if err != nil || p.Lat != 37.77 || p.Lng != -122.42 {
+		t.Errorf("ParsePoint() = %v, %v", p, err)
+	}
+	for _, s := range []string{"", "37.77", "abc,def", "91,0", "0,181"} {
+		if _, err := ParsePoint(s); err == nil {
+			t.Errorf("ParsePoint(%q) expected error", s)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package health
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/abhisheksainimitawa/job-aggregator/internal/models"
+)
+
+// Thresholds decide when a run deviates from its source's baseline
+type Thresholds struct {
+	MinRuns          int           // Successful runs needed before deviations are flagged
+	VolumeDrop       float64       // Fraction the job count may fall, e.g. 0.7
+	FillDrop         float64       // Fraction a field's fill rate may fall
+	ParseFailureRise float64       // Points the parse failure rate may rise
+	LatencyFactor    float64       // Multiple of the baseline latency allowed
+	MinLatency       time.Duration // Latency never flagged below this
+}
+
+// DefaultThresholds flags a 70% volume drop, a field's fill rate halving,
+// 20 more points of parse failures and runs three times slower than usual
+var DefaultThresholds = Thresholds{
+	MinRuns:          3,
+	VolumeDrop:       0.7,
+	FillDrop:         0.5,
+	ParseFailureRise: 0.2,
+	LatencyFactor:    3,
+	MinLatency:       5 * time.Second,
+}
+
+// Baseline averages the successful runs of a source, nil if there are none
+func Baseline(runs []models.SourceRun) *models.HealthBaseline {
+	b := &models.HealthBaseline{FillRates: make(map[string]float64, len(models.HealthFields))}
+	for i := range runs {
+		run := &runs[i]
+		if run.Error != "" {
+			continue
+		}
+		b.Runs++
+		b.Jobs += float64(run.Jobs)
+		b.ParseFailureRate += run.ParseFailureRate()
+		b.LatencyMs += float64(run.LatencyMs)
+		for _, field := range models.HealthFields {
+			b.FillRates[field] += run.FillRates[field]
+		}
+	}
+	if b.Runs == 0 {
+		return nil
+	}
+
+	n := float64(b.Runs)
+	b.Jobs /= n
+	b.ParseFailureRate /= n
+	b.LatencyMs /= n
+	for field := range b.FillRates {
+		b.FillRates[field] /= n
+	}
+	return b
+}
+
+// Detect describes how a run deviates from the baseline of its source. A
+// failed run is always an anomaly; the other checks need a baseline of at
+// least MinRuns runs.
+func Detect(run *models.SourceRun, baseline *models.HealthBaseline, t Thresholds) []string {
+	if run.Error != "" {
+		return []string{"run failed: " + run.Error}
+	}
+	if baseline == nil || baseline.Runs < t.MinRuns {
+		return nil
+	}
+
+	var anomalies []string
+	if baseline.Jobs > 0 && float64(run.Jobs) < baseline.Jobs*(1-t.VolumeDrop) {
+		anomalies = append(anomalies, fmt.Sprintf("job count fell %.0f%% to %d from an average of %.1f",
+			100*(1-float64(run.Jobs)/baseline.Jobs), run.Jobs, baseline.Jobs))
+	}
+
+	latency := time.Duration(run.LatencyMs) * time.Millisecond
+	if baseline.LatencyMs > 0 && latency >= t.MinLatency && float64(run.LatencyMs) > baseline.LatencyMs*t.LatencyFactor {
+		anomalies = append(anomalies, fmt.Sprintf("latency rose to %v from an average of %v",
+			latency, time.Duration(baseline.LatencyMs)*time.Millisecond))
+	}
+
+	// Rates are meaningless without jobs, which the volume check reports
+	if run.Jobs == 0 {
+		return anomalies
+	}
+	if rate := run.ParseFailureRate(); rate > baseline.ParseFailureRate+t.ParseFailureRise {
+		anomalies = append(anomalies, fmt.Sprintf("parse failure rate rose to %.0f%% from %.0f%%",
+			100*rate, 100*baseline.ParseFailureRate))
+	}
+	for _, field := range models.HealthFields {
+		usual := baseline.FillRates[field]
+		if rate := run.FillRates[field]; usual > 0 && rate < usual*(1-t.FillDrop) {
+			anomalies = append(anomalies, fmt.Sprintf("%s fill rate fell to %.0f%% from %.0f%%",
+				field, 100*rate, 100*usual))
+		}
+	}
+	return anomalies
+}
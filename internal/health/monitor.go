@@ -0,0 +1,93 @@
+package health
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/abhisheksainimitawa/job-aggregator/internal/models"
+	"github.com/abhisheksainimitawa/job-aggregator/pkg/logger"
+)
+
+// Monitor records source runs, flags the ones that deviate from their
+// source's rolling baseline and alerts on them
+type Monitor struct {
+	store      Store
+	notifier   Notifier
+	window     int
+	thresholds Thresholds
+}
+
+// NewMonitor creates a monitor comparing each run with the average of the
+// window runs before it
+func NewMonitor(store Store, notifier Notifier, window int, thresholds Thresholds) *Monitor {
+	if window < 1 {
+		window = 10
+	}
+	if notifier == nil {
+		notifier = LogNotifier{}
+	}
+	return &Monitor{store: store, notifier: notifier, window: window, thresholds: thresholds}
+}
+
+// Record judges and stores the runs of a scrape. Alerts that cannot be
+// delivered are logged; only storage errors are returned.
+func (m *Monitor) Record(ctx context.Context, runs []models.SourceRun) error {
+	for i := range runs {
+		run := &runs[i]
+
+		history, err := m.store.Recent(ctx, run.Source, m.window)
+		if err != nil {
+			return fmt.Errorf("failed to load runs of %s: %w", run.Source, err)
+		}
+		baseline := Baseline(history)
+		run.Anomalies = Detect(run, baseline, m.thresholds)
+
+		if err := m.store.Record(ctx, run); err != nil {
+			return fmt.Errorf("failed to record run of %s: %w", run.Source, err)
+		}
+
+		if len(run.Anomalies) > 0 {
+			alert := Alert{Source: run.Source, Run: *run, Baseline: baseline}
+			if err := m.notifier.Notify(ctx, alert); err != nil {
+				logger.Error("Failed to send health alert for %s: %v", run.Source, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Health returns the latest run of every source judged against the
+// baseline of the runs before it
+func (m *Monitor) Health(ctx context.Context) ([]models.SourceHealth, error) {
+	sources, err := m.store.Sources(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sources: %w", err)
+	}
+
+	health := make([]models.SourceHealth, 0, len(sources))
+	for _, source := range sources {
+		runs, err := m.store.Recent(ctx, source, m.window+1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load runs of %s: %w", source, err)
+		}
+		if len(runs) == 0 {
+			continue
+		}
+
+		last := runs[0]
+		h := models.SourceHealth{Source: source, Status: models.SourceHealthy, LastRun: &last, Baseline: Baseline(runs[1:])}
+		switch {
+		case last.Error != "":
+			h.Status = models.SourceFailing
+		case len(last.Anomalies) > 0:
+			h.Status = models.SourceDegraded
+		}
+		health = append(health, h)
+	}
+	return health, nil
+}
+
+// Runs returns the latest runs of a source, newest first
+func (m *Monitor) Runs(ctx context.Context, source string, limit int) ([]models.SourceRun, error) {
+	return m.store.Recent(ctx, source, limit)
+}
@@ -0,0 +1,118 @@
+package health
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abhisheksainimitawa/job-aggregator/internal/models"
+)
+
+// recordingNotifier keeps the alerts it is sent
+type recordingNotifier struct {
+	alerts []Alert
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, alert Alert) error {
+	n.alerts = append(n.alerts, alert)
+	return nil
+}
+
+// healthyRun returns a run of 20 jobs with every field filled
+func healthyRun(source string, at time.Time) models.SourceRun {
+	run := models.SourceRun{Source: source, StartedAt: at, LatencyMs: 400, Jobs: 20, FillRates: map[string]float64{}}
+	for _, field := range models.HealthFields {
+		run.FillRates[field] = 1
+	}
+	return run
+}
+
+func TestDetect(t *testing.T) {
+	start := time.Now()
+	history := []models.SourceRun{healthyRun("indeed", start), healthyRun("indeed", start), healthyRun("indeed", start)}
+	baseline := Baseline(history)
+
+	tests := []struct {
+		name   string
+		change func(run *models.SourceRun)
+		want   []string
+	}{
+		{"healthy", func(run *models.SourceRun) {}, nil},
+		{"volume drop", func(run *models.SourceRun) { run.Jobs = 5 }, []string{"job count fell 75%"}},
+		{"small drop", func(run *models.SourceRun) { run.Jobs = 15 }, nil},
+		{"empty descriptions", func(run *models.SourceRun) { run.FillRates["description"] = 0 }, []string{"description fill rate fell to 0%"}},
+		{"parse failures", func(run *models.SourceRun) { run.ParseFailures = 10 }, []string{"parse failure rate rose to 50%"}},
+		{"slow", func(run *models.SourceRun) { run.LatencyMs = 9000 }, []string{"latency rose to 9s"}},
+		{"failed", func(run *models.SourceRun) { run.Error = "timeout" }, []string{"run failed: timeout"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := healthyRun("indeed", start)
+			tt.change(&run)
+
+			got := Detect(&run, baseline, DefaultThresholds)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Detect() = %q, want %d anomalies", got, len(tt.want))
+			}
+			for i := range got {
+				if !strings.HasPrefix(got[i], tt.want[i]) {
+					t.Errorf("Detect()[%d] = %q, want prefix %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+
+	// Too little history flags only failures
+	run := healthyRun("indeed", start)
+	run.Jobs = 0
+	if got := Detect(&run, Baseline(history[:1]), DefaultThresholds); len(got) != 0 {
+		t.Errorf("Detect() with one baseline run = %q, want none", got)
+	}
+}
+
+func TestMonitor(t *testing.T) {
+	ctx := context.Background()
+	notifier := &recordingNotifier{}
+	monitor := NewMonitor(NewMemoryStore(10), notifier, 5, DefaultThresholds)
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		at := start.Add(time.Duration(i) * time.Hour)
+		if err := monitor.Record(ctx, []models.SourceRun{healthyRun("indeed", at), healthyRun("linkedin", at)}); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+
+	broken := []models.SourceRun{healthyRun("linkedin", start.Add(5*time.Hour))}
+	broken[0].Jobs = 0
+	if err := monitor.Record(ctx, broken); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if len(broken[0].Anomalies) != 1 || broken[0].ID == 0 {
+		t.Errorf("recorded run = %+v, want an ID and one anomaly", broken[0])
+	}
+
+	if len(notifier.alerts) != 1 || notifier.alerts[0].Source != "linkedin" {
+		t.Fatalf("alerts = %+v, want one for linkedin", notifier.alerts)
+	}
+
+	health, err := monitor.Health(ctx)
+	if err != nil {
+		t.Fatalf("Health() error = %v", err)
+	}
+	if len(health) != 2 {
+		t.Fatalf("Health() = %+v, want two sources", health)
+	}
+	if health[0].Source != "indeed" || health[0].Status != models.SourceHealthy {
+		t.Errorf("indeed = %+v, want healthy", health[0])
+	}
+	if health[1].Status != models.SourceDegraded || health[1].Baseline.Runs != 4 || health[1].LastRun.Jobs != 0 {
+		t.Errorf("linkedin = %+v, want degraded against 4 runs", health[1])
+	}
+
+	runs, err := monitor.Runs(ctx, "linkedin", 2)
+	if err != nil || len(runs) != 2 || runs[0].ID != broken[0].ID {
+		t.Errorf("Runs() = %+v, %v, want the 2 latest, newest first", runs, err)
+	}
+}
@@ -0,0 +1,94 @@
+package health
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/abhisheksainimitawa/job-aggregator/internal/models"
+	"github.com/abhisheksainimitawa/job-aggregator/pkg/logger"
+)
+
+// Alert reports a source run with anomalies
+type Alert struct {
+	Source   string                 `json:"source"`
+	Run      models.SourceRun       `json:"run"`
+	Baseline *models.HealthBaseline `json:"baseline,omitempty"`
+}
+
+// Notifier delivers alerts, e.g. to a log, a chat webhook or a pager
+type Notifier interface {
+	Notify(ctx context.Context, alert Alert) error
+}
+
+// LogNotifier writes alerts to the error log
+type LogNotifier struct{}
+
+// Notify logs the anomalies of the alert
+func (LogNotifier) Notify(ctx context.Context, alert Alert) error {
+	logger.Error("Source %s is unhealthy: %s", alert.Source, strings.Join(alert.Run.Anomalies, "; "))
+	return nil
+}
+
+// WebhookNotifier posts alerts as JSON to a URL
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookNotifier creates a notifier posting to url
+func NewWebhookNotifier(url string, timeout time.Duration) *WebhookNotifier {
+	return &WebhookNotifier{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+// Notify posts the alert and fails on any status other than 2xx
+func (n *WebhookNotifier) Notify(ctx context.Context, alert Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return fmt.Errorf("failed to encode alert: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create alert request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post alert: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("alert webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// Notifiers delivers every alert to each of its notifiers
+type Notifiers []Notifier
+
+// Notify notifies each notifier, returning the first error
+func (ns Notifiers) Notify(ctx context.Context, alert Alert) error {
+	var first error
+	for _, n := range ns {
+		if err := n.Notify(ctx, alert); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// NewNotifier returns a notifier logging alerts and, if webhookURL is
+// set, posting them to it
+func NewNotifier(webhookURL string) Notifier {
+	if webhookURL == "" {
+		return LogNotifier{}
+	}
+	return Notifiers{LogNotifier{}, NewWebhookNotifier(webhookURL, 10*time.Second)}
+}
@@ -0,0 +1,80 @@
+package health
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/abhisheksainimitawa/job-aggregator/internal/models"
+)
+
+// Store keeps the history of source runs. SourceRunRepository stores it
+// in Postgres; MemoryStore keeps recent runs in process.
+type Store interface {
+	// Record stores a run and sets its ID
+	Record(ctx context.Context, run *models.SourceRun) error
+
+	// Recent returns the latest runs of a source, newest first
+	Recent(ctx context.Context, source string, limit int) ([]models.SourceRun, error)
+
+	// Sources returns every source with recorded runs, sorted
+	Sources(ctx context.Context) ([]string, error)
+}
+
+// MemoryStore keeps the latest runs of each source in memory
+type MemoryStore struct {
+	mu     sync.RWMutex
+	keep   int
+	nextID int64
+	runs   map[string][]models.SourceRun // Oldest first
+}
+
+// NewMemoryStore creates a store keeping the latest keep runs per source
+func NewMemoryStore(keep int) *MemoryStore {
+	if keep < 1 {
+		keep = 100
+	}
+	return &MemoryStore{keep: keep, runs: make(map[string][]models.SourceRun)}
+}
+
+// Record stores a run and sets its ID, dropping the oldest run of its
+// source beyond the limit
+func (s *MemoryStore) Record(ctx context.Context, run *models.SourceRun) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	run.ID = s.nextID
+	runs := append(s.runs[run.Source], *run)
+	if len(runs) > s.keep {
+		runs = runs[len(runs)-s.keep:]
+	}
+	s.runs[run.Source] = runs
+	return nil
+}
+
+// Recent returns the latest runs of a source, newest first
+func (s *MemoryStore) Recent(ctx context.Context, source string, limit int) ([]models.SourceRun, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	runs := s.runs[source]
+	recent := make([]models.SourceRun, 0, limit)
+	for i := len(runs) - 1; i >= 0 && len(recent) < limit; i-- {
+		recent = append(recent, runs[i])
+	}
+	return recent, nil
+}
+
+// Sources returns every source with recorded runs, sorted
+func (s *MemoryStore) Sources(ctx context.Context) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sources := make([]string, 0, len(s.runs))
+	for source := range s.runs {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+	return sources, nil
+}
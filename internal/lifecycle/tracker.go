@@ -0,0 +1,82 @@
+package lifecycle
+
+import (
+	"context"
+	"time"
+
+	"github.com/abhisheksainimitawa/job-aggregator/internal/repository"
+	"github.com/abhisheksainimitawa/job-aggregator/pkg/logger"
+)
+
+// Tracker marks listings closed once their source stops returning them
+type Tracker struct {
+	repo       *repository.JobRepository
+	closeAfter int
+	verifier   *Verifier
+}
+
+// NewTracker creates a new lifecycle tracker. Listings missing from
+// closeAfter consecutive completed runs of their source are closed. If
+// verifier is non-nil, their URLs are re-fetched first and listings that
+// are still live stay open.
+func NewTracker(repo *repository.JobRepository, closeAfter int, verifier *Verifier) *Tracker {
+	if closeAfter < 1 {
+		closeAfter = 1
+	}
+	return &Tracker{
+		repo:       repo,
+		closeAfter: closeAfter,
+		verifier:   verifier,
+	}
+}
+
+// Complete updates listing state after a scrape run that started at
+// runStart. Only sources that completed without error are considered, so
+// a failing scraper does not close its listings.
+func (t *Tracker) Complete(ctx context.Context, sources []string, runStart time.Time) error {
+	for _, source := range sources {
+		if _, err := t.repo.MarkMissed(ctx, source, runStart); err != nil {
+			return err
+		}
+
+		candidates, err := t.repo.ExpiryCandidates(ctx, source, t.closeAfter)
+		if err != nil {
+			return err
+		}
+		if len(candidates) == 0 {
+			continue
+		}
+
+		toClose := make([]int64, 0, len(candidates))
+		stillOpen := make([]int64, 0)
+		for _, job := range candidates {
+			if t.verifier == nil {
+				toClose = append(toClose, job.ID)
+				continue
+			}
+
+			verdict, err := t.verifier.Check(ctx, job.URL)
+			if err != nil {
+				logger.Error("Failed to verify job %d: %v", job.ID, err)
+			}
+			if verdict == VerdictOpen {
+				stillOpen = append(stillOpen, job.ID)
+			} else {
+				// Unknown falls back to the missed-runs rule
+				toClose = append(toClose, job.ID)
+			}
+		}
+
+		closed, err := t.repo.CloseJobs(ctx, toClose, time.Now())
+		if err != nil {
+			return err
+		}
+		if err := t.repo.ResetMissed(ctx, stillOpen); err != nil {
+			return err
+		}
+
+		logger.Info("%s: closed %d jobs, %d still live", source, closed, len(stillOpen))
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Verdict is the outcome of re-fetching a listing
+type Verdict int
+
+const (
+	// VerdictUnknown means the page could not be classified
+	VerdictUnknown Verdict = iota
+	// VerdictOpen means the listing is still live
+	VerdictOpen
+	// VerdictClosed means the listing is gone or no longer accepting applications
+	VerdictClosed
+)
+
+// maxBodySize caps how much of a listing page is scanned for markers
+const maxBodySize = 512 * 1024
+
+// closedMarkers are phrases job boards show on expired listings
+var closedMarkers = []string{
+	"no longer accepting applications",
+	"no longer accepting",
+	"this job has expired",
+	"this job is no longer available",
+	"job is no longer available",
+	"this position has been filled",
+	"position has been filled",
+	"this posting has been closed",
+	"job posting has expired",
+}
+
+// Verifier re-fetches listing URLs to confirm they are closed
+type Verifier struct {
+	client *http.Client
+}
+
+// NewVerifier creates a new verifier with the given request timeout
+func NewVerifier(timeout time.Duration) *Verifier {
+	return &Verifier{
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Check fetches a listing URL. 404 and 410 responses and pages showing a
+// closed marker are closed; other successful pages are open.
+func (v *Verifier) Check(ctx context.Context, url string) (Verdict, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return VerdictUnknown, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return VerdictUnknown, fmt.Errorf("failed to fetch listing: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return VerdictClosed, nil
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		return VerdictUnknown, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	if err != nil {
+		return VerdictUnknown, fmt.Errorf("failed to read listing: %w", err)
+	}
+
+	page := strings.ToLower(string(body))
+	for _, marker := range closedMarkers {
+		if strings.Contains(page, marker) {
+			return VerdictClosed, nil
+		}
+	}
+
+	return VerdictOpen, nil
+}
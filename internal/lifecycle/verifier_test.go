@@ -0,0 +1,52 @@
+package lifecycle
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestVerifier_Check(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/open":
+			w.Write([]byte("<h1>Go Developer</h1><button>Apply now</button>"))
+		case "/expired":
+			w.Write([]byte("<p>This job is No Longer Accepting Applications.</p>"))
+		case "/gone":
+			w.WriteHeader(http.StatusGone)
+		case "/error":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		path    string
+		want    Verdict
+		wantErr bool
+	}{
+		{"/open", VerdictOpen, false},
+		{"/expired", VerdictClosed, false},
+		{"/gone", VerdictClosed, false},
+		{"/missing", VerdictClosed, false},
+		{"/error", VerdictUnknown, true},
+	}
+
+	v := NewVerifier(5 * time.Second)
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := v.Check(context.Background(), server.URL+tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Check() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,389 @@
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abhisheksainimitawa/job-aggregator/pkg/logger"
+)
+
+//go:embed migrations/*.sql
+var embedded embed.FS
+
+// lockKey identifies the advisory lock held while migrating
+const lockKey = 4815162342
+
+// noTransaction marks a migration that must run outside a transaction,
+// e.g. for CREATE INDEX CONCURRENTLY
+const noTransaction = "-- migrate:no-transaction"
+
+// fileRe matches migration file names like 0007_job_clusters.up.sql
+var fileRe = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration is one versioned schema change
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string // SHA-256 of Up
+}
+
+// Status is the state of one migration in a database
+type Status struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// applied is a row of schema_migrations
+type applied struct {
+	name      string
+	checksum  string
+	appliedAt time.Time
+}
+
+// Migrator applies migrations to a database
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// New creates a migrator for the migrations embedded in the binary
+func New(db *sql.DB) (*Migrator, error) {
+	sub, err := fs.Sub(embedded, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open embedded migrations: %w", err)
+	}
+	return NewWithFS(db, sub)
+}
+
+// NewWithFS creates a migrator for the migrations in fsys
+func NewWithFS(db *sql.DB, fsys fs.FS) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Load reads migrations from the root of fsys, ordered by version. Every
+// version needs both an up and a down file.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		m := fileRe.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || m == nil {
+			continue
+		}
+
+		version, _ := strconv.ParseInt(m[1], 10, 64)
+		data, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		} else if mig.Name != m[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, mig.Name, m[2])
+		}
+
+		if m[3] == "up" {
+			mig.Up = string(data)
+			mig.Checksum = fmt.Sprintf("%x", sha256.Sum256(data))
+		} else {
+			mig.Down = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == "" || mig.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both up and down files", mig.Version, mig.Name)
+		}
+		migrations = append(migrations, *mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Up applies up to n pending migrations, or all of them if n <= 0, and
+// returns how many were applied
+func (m *Migrator) Up(ctx context.Context, n int) (int, error) {
+	count := 0
+	err := m.withLock(ctx, func(conn *sql.Conn, done map[int64]applied) error {
+		for _, mig := range m.migrations {
+			if n > 0 && count >= n {
+				break
+			}
+			if _, ok := done[mig.Version]; ok {
+				continue
+			}
+
+			logger.Info("Applying migration %d_%s", mig.Version, mig.Name)
+			err := run(ctx, conn, mig.Up,
+				"INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES ($1, $2, $3, NOW())",
+				mig.Version, mig.Name, mig.Checksum,
+			)
+			if err != nil {
+				return fmt.Errorf("migration %d_%s failed: %w", mig.Version, mig.Name, err)
+			}
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Down reverts the last n applied migrations (at least one) and returns
+// how many were reverted
+func (m *Migrator) Down(ctx context.Context, n int) (int, error) {
+	if n < 1 {
+		n = 1
+	}
+
+	count := 0
+	err := m.withLock(ctx, func(conn *sql.Conn, done map[int64]applied) error {
+		for i := len(m.migrations) - 1; i >= 0 && count < n; i-- {
+			mig := m.migrations[i]
+			if _, ok := done[mig.Version]; !ok {
+				continue
+			}
+
+			logger.Info("Reverting migration %d_%s", mig.Version, mig.Name)
+			err := run(ctx, conn, mig.Down, "DELETE FROM schema_migrations WHERE version = $1", mig.Version)
+			if err != nil {
+				return fmt.Errorf("reverting migration %d_%s failed: %w", mig.Version, mig.Name, err)
+			}
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Status reports which migrations have been applied
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	done, err := m.applied(ctx, m.db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, len(m.migrations))
+	for i, mig := range m.migrations {
+		statuses[i] = Status{Migration: mig}
+		if a, ok := done[mig.Version]; ok {
+			statuses[i].Applied = true
+			statuses[i].AppliedAt = a.appliedAt
+		}
+	}
+	return statuses, nil
+}
+
+// Check returns an error unless every migration has been applied with
+// an unchanged checksum. The binaries call it at startup.
+func (m *Migrator) Check(ctx context.Context) error {
+	done, err := m.applied(ctx, m.db)
+	if err != nil {
+		return err
+	}
+	if err := m.verify(done); err != nil {
+		return err
+	}
+
+	pending := 0
+	for _, mig := range m.migrations {
+		if _, ok := done[mig.Version]; !ok {
+			pending++
+		}
+	}
+	if pending > 0 {
+		return fmt.Errorf("database schema is behind: %d pending migrations", pending)
+	}
+	return nil
+}
+
+// withLock runs fn on a single connection holding the migration advisory
+// lock, after verifying checksums of the applied migrations
+func (m *Migrator) withLock(ctx context.Context, fn func(*sql.Conn, map[int64]applied) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockKey); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", lockKey)
+
+	_, err = conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			checksum VARCHAR(64) NOT NULL,
+			applied_at TIMESTAMP NOT NULL DEFAULT NOW()
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	done, err := m.applied(ctx, conn)
+	if err != nil {
+		return err
+	}
+	if err := m.verify(done); err != nil {
+		return err
+	}
+
+	return fn(conn, done)
+}
+
+// querier is satisfied by *sql.DB and *sql.Conn
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// applied reads schema_migrations. A missing table means nothing has been
+// applied yet.
+func (m *Migrator) applied(ctx context.Context, db querier) (map[int64]applied, error) {
+	var exists bool
+	rows, err := db.QueryContext(ctx, "SELECT to_regclass('schema_migrations') IS NOT NULL")
+	if err != nil {
+		return nil, fmt.Errorf("failed to check schema_migrations: %w", err)
+	}
+	if rows.Next() {
+		err = rows.Scan(&exists)
+	}
+	rows.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check schema_migrations: %w", err)
+	}
+
+	done := make(map[int64]applied)
+	if !exists {
+		return done, nil
+	}
+
+	rows, err = db.QueryContext(ctx, "SELECT version, name, checksum, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var version int64
+		var a applied
+		if err := rows.Scan(&version, &a.name, &a.checksum, &a.appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan schema_migrations: %w", err)
+		}
+		done[version] = a
+	}
+	return done, rows.Err()
+}
+
+// verify rejects applied migrations that were edited afterwards or that
+// this binary does not know about
+func (m *Migrator) verify(done map[int64]applied) error {
+	known := make(map[int64]Migration, len(m.migrations))
+	for _, mig := range m.migrations {
+		known[mig.Version] = mig
+	}
+
+	versions := make([]int64, 0, len(done))
+	for v := range done {
+		versions = append(versions, v)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+
+	for _, v := range versions {
+		mig, ok := known[v]
+		if !ok {
+			return fmt.Errorf("database has migration %d_%s which this build does not know; upgrade the binary", v, done[v].name)
+		}
+		if mig.Checksum != done[v].checksum {
+			return fmt.Errorf("checksum mismatch for migration %d_%s: applied migrations must not be edited", v, mig.Name)
+		}
+	}
+	return nil
+}
+
+// run executes a migration script and records it, inside a transaction
+// unless the script opts out
+func run(ctx context.Context, conn *sql.Conn, script, record string, args ...interface{}) error {
+	if strings.HasPrefix(strings.TrimSpace(script), noTransaction) {
+		if _, err := conn.ExecContext(ctx, script); err != nil {
+			return err
+		}
+		_, err := conn.ExecContext(ctx, record, args...)
+		return err
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Create writes empty up and down files for a new migration in dir,
+// numbered after the highest existing version
+func Create(dir, name string) (string, string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = regexp.MustCompile(`[^a-z0-9]+`).ReplaceAllString(name, "_")
+	name = strings.Trim(name, "_")
+	if name == "" {
+		return "", "", fmt.Errorf("migration name is required")
+	}
+
+	migrations, err := Load(os.DirFS(dir))
+	if err != nil {
+		return "", "", err
+	}
+
+	var next int64 = 1
+	if len(migrations) > 0 {
+		next = migrations[len(migrations)-1].Version + 1
+	}
+
+	base := filepath.Join(dir, fmt.Sprintf("%04d_%s", next, name))
+	up, down := base+".up.sql", base+".down.sql"
+	for _, path := range []string{up, down} {
+		if err := os.WriteFile(path, []byte("-- "+filepath.Base(path)+"\n"), 0o644); err != nil {
+			return "", "", fmt.Errorf("failed to create migration: %w", err)
+		}
+	}
+
+	return up, down, nil
+}
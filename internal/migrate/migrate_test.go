@@ -0,0 +1,90 @@
+package migrate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0002_add_tags.up.sql":      {Data: []byte("CREATE TABLE tags ();")},
+		"0002_add_tags.down.sql":    {Data: []byte("DROP TABLE tags;")},
+		"0001_create_jobs.up.sql":   {Data: []byte("CREATE TABLE jobs ();")},
+		"0001_create_jobs.down.sql": {Data: []byte("DROP TABLE jobs;")},
+		"README.md":                 {Data: []byte("ignored")},
+	}
+
+	migrations, err := Load(fsys)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(migrations) != 2 {
+		t.Fatalf("Expected 2 migrations, got %d", len(migrations))
+	}
+	if migrations[0].Version != 1 || migrations[0].Name != "create_jobs" || migrations[1].Version != 2 {
+		t.Errorf("Migrations out of order: %+v", migrations)
+	}
+	if migrations[0].Down != "DROP TABLE jobs;" {
+		t.Errorf("Down = %q", migrations[0].Down)
+	}
+	if migrations[0].Checksum == "" || migrations[0].Checksum == migrations[1].Checksum {
+		t.Errorf("Unexpected checksums %q and %q", migrations[0].Checksum, migrations[1].Checksum)
+	}
+}
+
+func TestLoadMissingDown(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0001_create_jobs.up.sql": {Data: []byte("CREATE TABLE jobs ();")},
+	}
+	if _, err := Load(fsys); err == nil {
+		t.Error("Expected error for migration without down file")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	m := &Migrator{migrations: []Migration{{Version: 1, Name: "create_jobs", Checksum: "abc"}}}
+
+	if err := m.verify(map[int64]applied{1: {checksum: "abc"}}); err != nil {
+		t.Errorf("verify() error = %v", err)
+	}
+	if err := m.verify(map[int64]applied{1: {checksum: "edited"}}); err == nil {
+		t.Error("Expected checksum mismatch error")
+	}
+	if err := m.verify(map[int64]applied{2: {name: "future"}}); err == nil {
+		t.Error("Expected error for unknown applied migration")
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	m, err := New(nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	for i, mig := range m.migrations {
+		if mig.Version != int64(i+1) {
+			t.Errorf("Migration %s has version %d, want %d", mig.Name, mig.Version, i+1)
+		}
+	}
+}
+
+func TestCreate(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"0001_create_jobs.up.sql", "0001_create_jobs.down.sql"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("-- x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	up, down, err := Create(dir, "Add Search Index")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if filepath.Base(up) != "0002_add_search_index.up.sql" || filepath.Base(down) != "0002_add_search_index.down.sql" {
+		t.Errorf("Create() = %s, %s", up, down)
+	}
+	if _, err := Load(os.DirFS(dir)); err != nil {
+		t.Errorf("Load() after Create error = %v", err)
+	}
+}
@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS jobs (
+	id BIGSERIAL PRIMARY KEY,
+	title VARCHAR(255) NOT NULL,
+	company VARCHAR(255) NOT NULL,
+	location VARCHAR(255) NOT NULL,
+	salary VARCHAR(100),
+	description TEXT NOT NULL,
+	url TEXT NOT NULL,
+	source VARCHAR(50) NOT NULL,
+	remote_ok BOOLEAN DEFAULT FALSE,
+	job_type VARCHAR(50) NOT NULL,
+	posted_at TIMESTAMP NOT NULL,
+	scraped_at TIMESTAMP NOT NULL,
+	hash VARCHAR(64) UNIQUE NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_jobs_title ON jobs(title);
+CREATE INDEX IF NOT EXISTS idx_jobs_company ON jobs(company);
+CREATE INDEX IF NOT EXISTS idx_jobs_location ON jobs(location);
+CREATE INDEX IF NOT EXISTS idx_jobs_source ON jobs(source);
+CREATE INDEX IF NOT EXISTS idx_jobs_posted_at ON jobs(posted_at DESC);
+CREATE INDEX IF NOT EXISTS idx_jobs_remote_ok ON jobs(remote_ok);
+CREATE INDEX IF NOT EXISTS idx_jobs_job_type ON jobs(job_type);
+CREATE INDEX IF NOT EXISTS idx_jobs_hash ON jobs(hash);
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS job_tags (
+	job_id BIGINT NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+	tag VARCHAR(64) NOT NULL,
+	PRIMARY KEY (job_id, tag)
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_tags_tag ON job_tags(tag);
@@ -0,0 +1,3 @@
+ALTER TABLE jobs DROP COLUMN IF EXISTS role_family;
+ALTER TABLE jobs DROP COLUMN IF EXISTS seniority;
+ALTER TABLE jobs DROP COLUMN IF EXISTS normalized_title;
@@ -0,0 +1,6 @@
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS normalized_title VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS seniority VARCHAR(20) NOT NULL DEFAULT '';
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS role_family VARCHAR(30) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_jobs_seniority ON jobs(seniority);
+CREATE INDEX IF NOT EXISTS idx_jobs_role_family ON jobs(role_family);
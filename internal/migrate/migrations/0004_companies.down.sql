@@ -0,0 +1,3 @@
+ALTER TABLE jobs DROP COLUMN IF EXISTS company_id;
+DROP TABLE IF EXISTS company_aliases;
+DROP TABLE IF EXISTS companies;
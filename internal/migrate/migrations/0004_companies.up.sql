@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS companies (
+	id BIGSERIAL PRIMARY KEY,
+	name VARCHAR(255) NOT NULL,
+	normalized_name VARCHAR(255) UNIQUE NOT NULL,
+	domain VARCHAR(255),
+	metadata JSONB,
+	created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS company_aliases (
+	alias VARCHAR(255) PRIMARY KEY,
+	name VARCHAR(255) NOT NULL,
+	company_id BIGINT NOT NULL REFERENCES companies(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_company_aliases_company_id ON company_aliases(company_id);
+
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS company_id BIGINT REFERENCES companies(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_jobs_company_id ON jobs(company_id);
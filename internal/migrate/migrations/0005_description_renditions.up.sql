@@ -0,0 +1,2 @@
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS description_html TEXT NOT NULL DEFAULT '';
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS description_markdown TEXT NOT NULL DEFAULT '';
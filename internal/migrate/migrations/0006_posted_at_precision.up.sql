@@ -0,0 +1 @@
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS posted_at_precision VARCHAR(16) NOT NULL DEFAULT 'unknown';
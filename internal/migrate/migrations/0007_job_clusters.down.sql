@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS job_fingerprint_bands;
+ALTER TABLE jobs DROP COLUMN IF EXISTS cluster_id;
+ALTER TABLE jobs DROP COLUMN IF EXISTS fingerprint;
@@ -0,0 +1,13 @@
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS fingerprint BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS cluster_id BIGINT REFERENCES jobs(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_jobs_cluster_id ON jobs(cluster_id);
+
+CREATE TABLE IF NOT EXISTS job_fingerprint_bands (
+	band SMALLINT NOT NULL,
+	value INTEGER NOT NULL,
+	job_id BIGINT NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+	PRIMARY KEY (band, value, job_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_fingerprint_bands_job_id ON job_fingerprint_bands(job_id);
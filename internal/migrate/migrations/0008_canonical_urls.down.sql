@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_jobs_source_native_id;
+ALTER TABLE jobs DROP COLUMN IF EXISTS native_id;
+ALTER TABLE jobs DROP COLUMN IF EXISTS canonical_url;
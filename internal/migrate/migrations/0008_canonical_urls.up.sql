@@ -0,0 +1,4 @@
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS canonical_url TEXT NOT NULL DEFAULT '';
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS native_id VARCHAR(128) NOT NULL DEFAULT '';
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_jobs_source_native_id ON jobs(source, native_id) WHERE native_id <> '';
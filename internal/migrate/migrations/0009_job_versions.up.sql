@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS job_versions (
+	id BIGSERIAL PRIMARY KEY,
+	job_id BIGINT NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+	changed_at TIMESTAMP NOT NULL DEFAULT NOW(),
+	changes JSONB NOT NULL,
+	changed_fields TEXT[] NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_versions_job_id ON job_versions(job_id, changed_at DESC);
+CREATE INDEX IF NOT EXISTS idx_job_versions_changed_fields ON job_versions USING GIN(changed_fields);
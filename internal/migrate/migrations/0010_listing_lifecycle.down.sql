@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_jobs_open_last_seen;
+DROP INDEX IF EXISTS idx_jobs_status;
+ALTER TABLE jobs DROP COLUMN IF EXISTS missed_runs;
+ALTER TABLE jobs DROP COLUMN IF EXISTS closed_at;
+ALTER TABLE jobs DROP COLUMN IF EXISTS last_seen_at;
+ALTER TABLE jobs DROP COLUMN IF EXISTS first_seen_at;
+ALTER TABLE jobs DROP COLUMN IF EXISTS status;
@@ -0,0 +1,11 @@
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS status VARCHAR(16) NOT NULL DEFAULT 'open';
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS first_seen_at TIMESTAMP;
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS last_seen_at TIMESTAMP;
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS closed_at TIMESTAMP;
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS missed_runs INTEGER NOT NULL DEFAULT 0;
+
+UPDATE jobs SET first_seen_at = created_at WHERE first_seen_at IS NULL;
+UPDATE jobs SET last_seen_at = scraped_at WHERE last_seen_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_jobs_status ON jobs(status);
+CREATE INDEX IF NOT EXISTS idx_jobs_open_last_seen ON jobs(source, last_seen_at) WHERE status = 'open';
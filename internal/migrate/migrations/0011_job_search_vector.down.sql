@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_jobs_search_vector;
+ALTER TABLE jobs DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,10 @@
+-- Weighted full-text document: title (A) > company (B) > description (C).
+-- A generated column keeps it current on every insert and update.
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS search_vector tsvector
+	GENERATED ALWAYS AS (
+		setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+		setweight(to_tsvector('english', coalesce(company, '')), 'B') ||
+		setweight(to_tsvector('english', coalesce(description, '')), 'C')
+	) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_jobs_search_vector ON jobs USING GIN(search_vector);
@@ -0,0 +1,9 @@
+-- Backs the newest-first search order and its keyset cursors. The
+-- expressions must match newestKey in the job repository.
+CREATE INDEX IF NOT EXISTS idx_jobs_newest ON jobs (
+	(DATE(posted_at)) DESC,
+	(CASE posted_at_precision
+		WHEN 'exact' THEN 3 WHEN 'day' THEN 2 WHEN 'approximate' THEN 1 ELSE 0 END) DESC,
+	posted_at DESC,
+	id DESC
+);
@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS idx_jobs_geo_point;
+DROP INDEX IF EXISTS idx_jobs_salary_min;
+DROP INDEX IF EXISTS idx_jobs_salary_desc;
+DROP INDEX IF EXISTS idx_jobs_salary_asc;
+DROP INDEX IF EXISTS idx_jobs_company_sort;
+DROP INDEX IF EXISTS idx_jobs_updated_at;
+DROP INDEX IF EXISTS idx_jobs_scraped_at;
+ALTER TABLE jobs DROP COLUMN IF EXISTS geo_point;
+ALTER TABLE jobs DROP COLUMN IF EXISTS longitude;
+ALTER TABLE jobs DROP COLUMN IF EXISTS latitude;
+ALTER TABLE jobs DROP COLUMN IF EXISTS salary_currency;
+ALTER TABLE jobs DROP COLUMN IF EXISTS salary_max;
+ALTER TABLE jobs DROP COLUMN IF EXISTS salary_min;
@@ -0,0 +1,20 @@
+-- Structured salary, annualized by pkg/salary at ingestion
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS salary_min BIGINT;
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS salary_max BIGINT;
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS salary_currency VARCHAR(3) NOT NULL DEFAULT '';
+
+-- Coordinates of the job location, see internal/geo. geo_point backs
+-- nearest-first ordering with a GiST index.
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS latitude DOUBLE PRECISION;
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS longitude DOUBLE PRECISION;
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS geo_point point
+	GENERATED ALWAYS AS (point(longitude, latitude)) STORED;
+
+-- One index per sort key, with id as the tie-breaker
+CREATE INDEX IF NOT EXISTS idx_jobs_scraped_at ON jobs(scraped_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_jobs_updated_at ON jobs(updated_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_jobs_company_sort ON jobs(company, id);
+CREATE INDEX IF NOT EXISTS idx_jobs_salary_asc ON jobs(salary_max ASC NULLS LAST, id ASC);
+CREATE INDEX IF NOT EXISTS idx_jobs_salary_desc ON jobs(salary_max DESC NULLS LAST, id DESC);
+CREATE INDEX IF NOT EXISTS idx_jobs_salary_min ON jobs(salary_min);
+CREATE INDEX IF NOT EXISTS idx_jobs_geo_point ON jobs USING GIST(geo_point);
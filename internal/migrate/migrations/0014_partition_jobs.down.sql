@@ -0,0 +1,82 @@
+-- Merges the attached partitions back into a plain jobs table. Partitions
+-- detached by an unfinished archive are left alone and their jobs dropped.
+DROP TRIGGER IF EXISTS jobs_sync_keys ON jobs;
+DROP FUNCTION IF EXISTS sync_job_keys();
+
+ALTER TABLE job_tags DROP CONSTRAINT job_tags_job_id_fkey;
+ALTER TABLE job_fingerprint_bands DROP CONSTRAINT job_fingerprint_bands_job_id_fkey;
+ALTER TABLE job_versions DROP CONSTRAINT job_versions_job_id_fkey;
+
+ALTER TABLE jobs RENAME TO jobs_partitioned;
+ALTER SEQUENCE jobs_id_seq OWNED BY NONE;
+
+CREATE TABLE jobs (LIKE jobs_partitioned INCLUDING DEFAULTS INCLUDING GENERATED INCLUDING CONSTRAINTS);
+
+INSERT INTO jobs (
+	id, title, company, location, salary, description, url, source, remote_ok, job_type,
+	posted_at, scraped_at, hash, created_at, updated_at, normalized_title, seniority, role_family,
+	company_id, description_html, description_markdown, posted_at_precision, fingerprint, cluster_id,
+	canonical_url, native_id, status, first_seen_at, last_seen_at, closed_at, missed_runs,
+	salary_min, salary_max, salary_currency, latitude, longitude
+)
+SELECT
+	id, title, company, location, salary, description, url, source, remote_ok, job_type,
+	posted_at, scraped_at, hash, created_at, updated_at, normalized_title, seniority, role_family,
+	company_id, description_html, description_markdown, posted_at_precision, fingerprint, cluster_id,
+	canonical_url, native_id, status, first_seen_at, last_seen_at, closed_at, missed_runs,
+	salary_min, salary_max, salary_currency, latitude, longitude
+FROM jobs_partitioned;
+
+DROP TABLE jobs_partitioned;
+ALTER SEQUENCE jobs_id_seq OWNED BY jobs.id;
+
+DELETE FROM job_tags WHERE job_id NOT IN (SELECT id FROM jobs);
+DELETE FROM job_fingerprint_bands WHERE job_id NOT IN (SELECT id FROM jobs);
+DELETE FROM job_versions WHERE job_id NOT IN (SELECT id FROM jobs);
+UPDATE jobs SET cluster_id = NULL WHERE cluster_id NOT IN (SELECT id FROM jobs);
+
+ALTER TABLE jobs ADD PRIMARY KEY (id);
+ALTER TABLE jobs ADD CONSTRAINT jobs_hash_key UNIQUE (hash);
+ALTER TABLE jobs ADD CONSTRAINT jobs_company_id_fkey FOREIGN KEY (company_id) REFERENCES companies(id) ON DELETE SET NULL;
+ALTER TABLE jobs ADD CONSTRAINT jobs_cluster_id_fkey FOREIGN KEY (cluster_id) REFERENCES jobs(id) ON DELETE SET NULL;
+
+ALTER TABLE job_tags ADD CONSTRAINT job_tags_job_id_fkey
+	FOREIGN KEY (job_id) REFERENCES jobs(id) ON DELETE CASCADE;
+ALTER TABLE job_fingerprint_bands ADD CONSTRAINT job_fingerprint_bands_job_id_fkey
+	FOREIGN KEY (job_id) REFERENCES jobs(id) ON DELETE CASCADE;
+ALTER TABLE job_versions ADD CONSTRAINT job_versions_job_id_fkey
+	FOREIGN KEY (job_id) REFERENCES jobs(id) ON DELETE CASCADE;
+
+CREATE INDEX idx_jobs_title ON jobs(title);
+CREATE INDEX idx_jobs_company ON jobs(company);
+CREATE INDEX idx_jobs_location ON jobs(location);
+CREATE INDEX idx_jobs_source ON jobs(source);
+CREATE INDEX idx_jobs_posted_at ON jobs(posted_at DESC);
+CREATE INDEX idx_jobs_remote_ok ON jobs(remote_ok);
+CREATE INDEX idx_jobs_job_type ON jobs(job_type);
+CREATE INDEX idx_jobs_hash ON jobs(hash);
+CREATE INDEX idx_jobs_seniority ON jobs(seniority);
+CREATE INDEX idx_jobs_role_family ON jobs(role_family);
+CREATE INDEX idx_jobs_company_id ON jobs(company_id);
+CREATE INDEX idx_jobs_cluster_id ON jobs(cluster_id);
+CREATE UNIQUE INDEX idx_jobs_source_native_id ON jobs(source, native_id) WHERE native_id <> '';
+CREATE INDEX idx_jobs_status ON jobs(status);
+CREATE INDEX idx_jobs_open_last_seen ON jobs(source, last_seen_at) WHERE status = 'open';
+CREATE INDEX idx_jobs_search_vector ON jobs USING GIN(search_vector);
+CREATE INDEX idx_jobs_newest ON jobs (
+	(DATE(posted_at)) DESC,
+	(CASE posted_at_precision
+		WHEN 'exact' THEN 3 WHEN 'day' THEN 2 WHEN 'approximate' THEN 1 ELSE 0 END) DESC,
+	posted_at DESC,
+	id DESC
+);
+CREATE INDEX idx_jobs_scraped_at ON jobs(scraped_at DESC, id DESC);
+CREATE INDEX idx_jobs_updated_at ON jobs(updated_at DESC, id DESC);
+CREATE INDEX idx_jobs_company_sort ON jobs(company, id);
+CREATE INDEX idx_jobs_salary_asc ON jobs(salary_max ASC NULLS LAST, id ASC);
+CREATE INDEX idx_jobs_salary_desc ON jobs(salary_max DESC NULLS LAST, id DESC);
+CREATE INDEX idx_jobs_salary_min ON jobs(salary_min);
+CREATE INDEX idx_jobs_geo_point ON jobs USING GIST(geo_point);
+
+-- CASCADE drops the foreign keys of detached partitions
+DROP TABLE job_keys CASCADE;
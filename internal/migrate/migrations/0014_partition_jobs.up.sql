@@ -0,0 +1,133 @@
+-- Range-partition jobs by posted_at month (see PartitionRepository).
+-- Unique keys of a partitioned table must include posted_at, so the
+-- globally unique job keys move to job_keys, which a trigger keeps in
+-- sync with jobs and which the other tables now reference. Deleting a
+-- job_keys row deletes the job, its tags and its versions.
+CREATE TABLE job_keys (
+	id BIGINT PRIMARY KEY,
+	hash VARCHAR(64) NOT NULL UNIQUE,
+	source VARCHAR(50) NOT NULL,
+	native_id VARCHAR(128) NOT NULL DEFAULT '',
+	posted_at TIMESTAMP NOT NULL
+);
+
+CREATE UNIQUE INDEX idx_job_keys_source_native_id ON job_keys(source, native_id) WHERE native_id <> '';
+CREATE INDEX idx_job_keys_posted_at ON job_keys(posted_at);
+
+INSERT INTO job_keys (id, hash, source, native_id, posted_at)
+SELECT id, hash, source, native_id, posted_at FROM jobs;
+
+ALTER TABLE job_tags DROP CONSTRAINT job_tags_job_id_fkey;
+ALTER TABLE job_fingerprint_bands DROP CONSTRAINT job_fingerprint_bands_job_id_fkey;
+ALTER TABLE job_versions DROP CONSTRAINT job_versions_job_id_fkey;
+
+ALTER TABLE jobs RENAME TO jobs_unpartitioned;
+ALTER SEQUENCE jobs_id_seq OWNED BY NONE;
+
+CREATE TABLE jobs (LIKE jobs_unpartitioned INCLUDING DEFAULTS INCLUDING GENERATED INCLUDING CONSTRAINTS)
+	PARTITION BY RANGE (posted_at);
+
+-- Rows outside the monthly partitions, e.g. from before the first one
+CREATE TABLE jobs_default PARTITION OF jobs DEFAULT;
+
+-- One partition per month of stored jobs, at most five years back, and
+-- for the next three months. Later months are created by the API.
+DO $$
+DECLARE
+	m DATE;
+BEGIN
+	FOR m IN
+		SELECT generate_series(first, last, INTERVAL '1 month')::date
+		FROM (
+			SELECT date_trunc('month', GREATEST(LEAST(MIN(posted_at), NOW()), NOW() - INTERVAL '5 years')) AS first,
+			       date_trunc('month', NOW()) + INTERVAL '3 months' AS last
+			FROM jobs_unpartitioned
+		) bounds
+	LOOP
+		EXECUTE format(
+			'CREATE TABLE %I PARTITION OF jobs FOR VALUES FROM (%L) TO (%L)',
+			'jobs_' || to_char(m, 'YYYY_MM'), m, (m + INTERVAL '1 month')::date
+		);
+	END LOOP;
+END
+$$;
+
+INSERT INTO jobs (
+	id, title, company, location, salary, description, url, source, remote_ok, job_type,
+	posted_at, scraped_at, hash, created_at, updated_at, normalized_title, seniority, role_family,
+	company_id, description_html, description_markdown, posted_at_precision, fingerprint, cluster_id,
+	canonical_url, native_id, status, first_seen_at, last_seen_at, closed_at, missed_runs,
+	salary_min, salary_max, salary_currency, latitude, longitude
+)
+SELECT
+	id, title, company, location, salary, description, url, source, remote_ok, job_type,
+	posted_at, scraped_at, hash, created_at, updated_at, normalized_title, seniority, role_family,
+	company_id, description_html, description_markdown, posted_at_precision, fingerprint, cluster_id,
+	canonical_url, native_id, status, first_seen_at, last_seen_at, closed_at, missed_runs,
+	salary_min, salary_max, salary_currency, latitude, longitude
+FROM jobs_unpartitioned;
+
+DROP TABLE jobs_unpartitioned;
+ALTER SEQUENCE jobs_id_seq OWNED BY jobs.id;
+
+ALTER TABLE jobs ADD PRIMARY KEY (id, posted_at);
+ALTER TABLE jobs ADD CONSTRAINT jobs_id_fkey FOREIGN KEY (id) REFERENCES job_keys(id) ON DELETE CASCADE;
+ALTER TABLE jobs ADD CONSTRAINT jobs_company_id_fkey FOREIGN KEY (company_id) REFERENCES companies(id) ON DELETE SET NULL;
+ALTER TABLE jobs ADD CONSTRAINT jobs_cluster_id_fkey FOREIGN KEY (cluster_id) REFERENCES job_keys(id) ON DELETE SET NULL;
+
+ALTER TABLE job_tags ADD CONSTRAINT job_tags_job_id_fkey
+	FOREIGN KEY (job_id) REFERENCES job_keys(id) ON DELETE CASCADE;
+ALTER TABLE job_fingerprint_bands ADD CONSTRAINT job_fingerprint_bands_job_id_fkey
+	FOREIGN KEY (job_id) REFERENCES job_keys(id) ON DELETE CASCADE;
+ALTER TABLE job_versions ADD CONSTRAINT job_versions_job_id_fkey
+	FOREIGN KEY (job_id) REFERENCES job_keys(id) ON DELETE CASCADE;
+
+-- The indexes of the earlier migrations, now on every partition. Hash and
+-- native ID lookups go to job_keys.
+CREATE INDEX idx_jobs_title ON jobs(title);
+CREATE INDEX idx_jobs_company ON jobs(company);
+CREATE INDEX idx_jobs_location ON jobs(location);
+CREATE INDEX idx_jobs_source ON jobs(source);
+CREATE INDEX idx_jobs_posted_at ON jobs(posted_at DESC);
+CREATE INDEX idx_jobs_remote_ok ON jobs(remote_ok);
+CREATE INDEX idx_jobs_job_type ON jobs(job_type);
+CREATE INDEX idx_jobs_seniority ON jobs(seniority);
+CREATE INDEX idx_jobs_role_family ON jobs(role_family);
+CREATE INDEX idx_jobs_company_id ON jobs(company_id);
+CREATE INDEX idx_jobs_cluster_id ON jobs(cluster_id);
+CREATE INDEX idx_jobs_status ON jobs(status);
+CREATE INDEX idx_jobs_open_last_seen ON jobs(source, last_seen_at) WHERE status = 'open';
+CREATE INDEX idx_jobs_search_vector ON jobs USING GIN(search_vector);
+CREATE INDEX idx_jobs_newest ON jobs (
+	(DATE(posted_at)) DESC,
+	(CASE posted_at_precision
+		WHEN 'exact' THEN 3 WHEN 'day' THEN 2 WHEN 'approximate' THEN 1 ELSE 0 END) DESC,
+	posted_at DESC,
+	id DESC
+);
+CREATE INDEX idx_jobs_scraped_at ON jobs(scraped_at DESC, id DESC);
+CREATE INDEX idx_jobs_updated_at ON jobs(updated_at DESC, id DESC);
+CREATE INDEX idx_jobs_company_sort ON jobs(company, id);
+CREATE INDEX idx_jobs_salary_asc ON jobs(salary_max ASC NULLS LAST, id ASC);
+CREATE INDEX idx_jobs_salary_desc ON jobs(salary_max DESC NULLS LAST, id DESC);
+CREATE INDEX idx_jobs_salary_min ON jobs(salary_min);
+CREATE INDEX idx_jobs_geo_point ON jobs USING GIST(geo_point);
+
+-- Registers the keys of every inserted job. A hash or native ID that is
+-- already taken fails the insert, as the unique indexes on jobs did.
+CREATE FUNCTION sync_job_keys() RETURNS trigger AS $$
+BEGIN
+	INSERT INTO job_keys (id, hash, source, native_id, posted_at)
+	VALUES (NEW.id, NEW.hash, NEW.source, NEW.native_id, NEW.posted_at)
+	ON CONFLICT (id) DO UPDATE SET
+		hash = EXCLUDED.hash,
+		source = EXCLUDED.source,
+		native_id = EXCLUDED.native_id,
+		posted_at = EXCLUDED.posted_at;
+	RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER jobs_sync_keys
+	BEFORE INSERT OR UPDATE OF hash, source, native_id, posted_at ON jobs
+	FOR EACH ROW EXECUTE FUNCTION sync_job_keys();
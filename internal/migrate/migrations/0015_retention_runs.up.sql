@@ -0,0 +1,12 @@
+-- Audit log of retention runs: what each rule matched or deleted
+CREATE TABLE IF NOT EXISTS retention_runs (
+	id BIGSERIAL PRIMARY KEY,
+	started_at TIMESTAMP NOT NULL,
+	finished_at TIMESTAMP NOT NULL,
+	dry_run BOOLEAN NOT NULL,
+	deleted BIGINT NOT NULL DEFAULT 0,
+	rules JSONB NOT NULL,
+	error TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_retention_runs_started_at ON retention_runs(started_at DESC);
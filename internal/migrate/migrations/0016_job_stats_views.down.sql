@@ -0,0 +1,2 @@
+DROP MATERIALIZED VIEW IF EXISTS job_stats_counts;
+DROP MATERIALIZED VIEW IF EXISTS job_stats_summary;
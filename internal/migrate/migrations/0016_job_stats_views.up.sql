@@ -0,0 +1,42 @@
+-- Precomputed statistics for /api/v1/jobs/stats (see RefreshStats). Both
+-- views have a unique index so they can be refreshed concurrently, without
+-- blocking readers.
+CREATE MATERIALIZED VIEW job_stats_summary AS
+SELECT
+	1 AS id,
+	COUNT(*) AS total_jobs,
+	COUNT(*) FILTER (WHERE remote_ok) AS remote_jobs,
+	COUNT(*) FILTER (WHERE DATE(posted_at) = CURRENT_DATE AND posted_at_precision <> 'unknown') AS today_jobs,
+	MAX(scraped_at) AS last_scraped_at,
+	COUNT(*) FILTER (WHERE status = 'open') AS open_jobs,
+	COUNT(*) FILTER (WHERE status = 'closed') AS closed_jobs,
+	COALESCE(AVG(EXTRACT(EPOCH FROM last_seen_at - first_seen_at)) FILTER (WHERE status = 'closed'), 0) / 86400 AS avg_days_open,
+	COALESCE(PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM last_seen_at - first_seen_at))
+		FILTER (WHERE status = 'closed'), 0) / 86400 AS median_days_open,
+	NOW()::timestamp AS refreshed_at
+FROM jobs;
+
+CREATE UNIQUE INDEX idx_job_stats_summary_id ON job_stats_summary(id);
+
+-- Job counts per value of each dimension. Companies are grouped by their
+-- canonical name so "Google LLC" and "Google Inc." count once.
+CREATE MATERIALIZED VIEW job_stats_counts AS
+SELECT 'source' AS dimension, source AS value, COUNT(*) AS count FROM jobs GROUP BY source
+UNION ALL
+SELECT 'job_type', job_type, COUNT(*) FROM jobs GROUP BY job_type
+UNION ALL
+SELECT 'seniority', seniority, COUNT(*) FROM jobs GROUP BY seniority
+UNION ALL
+SELECT 'role_family', role_family, COUNT(*) FROM jobs GROUP BY role_family
+UNION ALL
+SELECT 'company', COALESCE(c.name, j.company), COUNT(*)
+FROM jobs j
+LEFT JOIN companies c ON c.id = j.company_id
+GROUP BY COALESCE(c.name, j.company)
+UNION ALL
+SELECT 'location', location, COUNT(*) FROM jobs GROUP BY location
+UNION ALL
+SELECT 'skill', tag, COUNT(*) FROM job_tags GROUP BY tag;
+
+CREATE UNIQUE INDEX idx_job_stats_counts_value ON job_stats_counts(dimension, value);
+CREATE INDEX idx_job_stats_counts_rank ON job_stats_counts(dimension, count DESC);
@@ -0,0 +1,14 @@
+-- What each source returned in each scrape run, for health monitoring
+CREATE TABLE IF NOT EXISTS source_runs (
+	id BIGSERIAL PRIMARY KEY,
+	source VARCHAR(50) NOT NULL,
+	started_at TIMESTAMP NOT NULL,
+	latency_ms BIGINT NOT NULL,
+	jobs INT NOT NULL,
+	parse_failures INT NOT NULL DEFAULT 0,
+	fill_rates JSONB NOT NULL DEFAULT '{}',
+	error TEXT NOT NULL DEFAULT '',
+	anomalies TEXT[] NOT NULL DEFAULT '{}'
+);
+
+CREATE INDEX IF NOT EXISTS idx_source_runs_source_started_at ON source_runs(source, started_at DESC);
@@ -0,0 +1,60 @@
+-- Data quality score of each job and the factors behind it (see
+-- internal/quality). Jobs stored earlier score 0 until scraped again.
+ALTER TABLE jobs
+	ADD COLUMN IF NOT EXISTS quality_score SMALLINT NOT NULL DEFAULT 0,
+	ADD COLUMN IF NOT EXISTS quality_reasons JSONB NOT NULL DEFAULT '[]';
+
+CREATE INDEX IF NOT EXISTS idx_jobs_quality_score ON jobs(quality_score);
+
+-- The stats views of 0016 with the average quality and a quality
+-- dimension counting jobs per band of 20 points
+DROP MATERIALIZED VIEW IF EXISTS job_stats_counts;
+DROP MATERIALIZED VIEW IF EXISTS job_stats_summary;
+
+CREATE MATERIALIZED VIEW job_stats_summary AS
+SELECT
+	1 AS id,
+	COUNT(*) AS total_jobs,
+	COUNT(*) FILTER (WHERE remote_ok) AS remote_jobs,
+	COUNT(*) FILTER (WHERE DATE(posted_at) = CURRENT_DATE AND posted_at_precision <> 'unknown') AS today_jobs,
+	MAX(scraped_at) AS last_scraped_at,
+	COUNT(*) FILTER (WHERE status = 'open') AS open_jobs,
+	COUNT(*) FILTER (WHERE status = 'closed') AS closed_jobs,
+	COALESCE(AVG(EXTRACT(EPOCH FROM last_seen_at - first_seen_at)) FILTER (WHERE status = 'closed'), 0) / 86400 AS avg_days_open,
+	COALESCE(PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM last_seen_at - first_seen_at))
+		FILTER (WHERE status = 'closed'), 0) / 86400 AS median_days_open,
+	COALESCE(AVG(quality_score), 0)::float8 AS avg_quality,
+	NOW()::timestamp AS refreshed_at
+FROM jobs;
+
+CREATE UNIQUE INDEX idx_job_stats_summary_id ON job_stats_summary(id);
+
+CREATE MATERIALIZED VIEW job_stats_counts AS
+SELECT 'source' AS dimension, source AS value, COUNT(*) AS count FROM jobs GROUP BY source
+UNION ALL
+SELECT 'job_type', job_type, COUNT(*) FROM jobs GROUP BY job_type
+UNION ALL
+SELECT 'seniority', seniority, COUNT(*) FROM jobs GROUP BY seniority
+UNION ALL
+SELECT 'role_family', role_family, COUNT(*) FROM jobs GROUP BY role_family
+UNION ALL
+SELECT 'quality',
+	CASE
+		WHEN quality_score >= 80 THEN '80-100' WHEN quality_score >= 60 THEN '60-79'
+		WHEN quality_score >= 40 THEN '40-59' WHEN quality_score >= 20 THEN '20-39' ELSE '0-19'
+	END,
+	COUNT(*)
+FROM jobs
+GROUP BY 2
+UNION ALL
+SELECT 'company', COALESCE(c.name, j.company), COUNT(*)
+FROM jobs j
+LEFT JOIN companies c ON c.id = j.company_id
+GROUP BY COALESCE(c.name, j.company)
+UNION ALL
+SELECT 'location', location, COUNT(*) FROM jobs GROUP BY location
+UNION ALL
+SELECT 'skill', tag, COUNT(*) FROM job_tags GROUP BY tag;
+
+CREATE UNIQUE INDEX idx_job_stats_counts_value ON job_stats_counts(dimension, value);
+CREATE INDEX idx_job_stats_counts_rank ON job_stats_counts(dimension, count DESC);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_jobs_flag_unreviewed;
+ALTER TABLE jobs
+	DROP COLUMN IF EXISTS flagged,
+	DROP COLUMN IF EXISTS flag_reasons,
+	DROP COLUMN IF EXISTS flag_review;
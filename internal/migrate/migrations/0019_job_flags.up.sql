@@ -0,0 +1,9 @@
+-- Scam and spam flags of jobs (see internal/scam). flag_review holds an
+-- admin's verdict, scam or legit, which re-scrapes leave alone.
+ALTER TABLE jobs
+	ADD COLUMN IF NOT EXISTS flagged BOOLEAN NOT NULL DEFAULT FALSE,
+	ADD COLUMN IF NOT EXISTS flag_reasons JSONB NOT NULL DEFAULT '[]',
+	ADD COLUMN IF NOT EXISTS flag_review VARCHAR(16) NOT NULL DEFAULT '';
+
+-- The review queue: flagged jobs nobody has reviewed yet
+CREATE INDEX IF NOT EXISTS idx_jobs_flag_unreviewed ON jobs(posted_at DESC) WHERE flagged AND flag_review = '';
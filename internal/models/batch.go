@@ -0,0 +1,15 @@
+package models
+
+// BatchResult reports what storing a batch of scraped jobs did
+type BatchResult struct {
+	Inserted int              `json:"inserted"`
+	Updated  int              `json:"updated"`
+	Rejected []BatchRejection `json:"rejected"`
+}
+
+// BatchRejection is a job of a batch that was not stored
+type BatchRejection struct {
+	Row    int    `json:"row"` // Index of the job in the batch
+	Hash   string `json:"hash"`
+	Reason string `json:"reason"`
+}
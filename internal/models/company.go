@@ -0,0 +1,31 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Company represents a canonical employer that jobs are linked to
+type Company struct {
+	ID        int64           `json:"id" db:"id"`
+	Name      string          `json:"name" db:"name"`
+	Domain    string          `json:"domain,omitempty" db:"domain"`
+	Aliases   []string        `json:"aliases" db:"-"` // Raw spellings, stored in company_aliases
+	Metadata  json.RawMessage `json:"metadata,omitempty" db:"metadata"`
+	JobCount  int64           `json:"job_count" db:"-"`
+	CreatedAt time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// CompanyPage is one page of listed companies
+type CompanyPage struct {
+	Companies []*Company
+	Total     int64 // Number of matching companies
+}
+
+// CompanySearchQuery represents company listing parameters
+type CompanySearchQuery struct {
+	Name  string
+	Page  int
+	Limit int
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// ErrInvalidCursor is returned for cursors that were not issued by the API
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is an opaque position in the newest-first job order, which sorts
+// by posting day, date precision, posted_at and id. Clients only pass
+// back the encoded form from next_cursor or prev_cursor.
+type Cursor struct {
+	PostedAt time.Time `json:"p"`
+	Rank     int       `json:"r"`
+	ID       int64     `json:"i"`
+	Before   bool      `json:"b,omitempty"` // page ends at the position instead of starting after it
+}
+
+// Encode returns the URL-safe form of the cursor
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a cursor produced by Encode
+func DecodeCursor(s string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID <= 0 || c.PostedAt.IsZero() {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// JobPage is one page of search results
+type JobPage struct {
+	Jobs       []*Job
+	Total      int64 // Number of matching jobs
+	TotalExact bool  // False when Total is a planner estimate
+	NextCursor string
+	PrevCursor string
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	c := Cursor{
+		PostedAt: time.Date(2026, 3, 4, 10, 30, 0, 123456000, time.UTC),
+		Rank:     2,
+		ID:       987,
+		Before:   true,
+	}
+
+	decoded, err := DecodeCursor(c.Encode())
+	if err != nil {
+		t.Fatalf("DecodeCursor() error = %v", err)
+	}
+	if !decoded.PostedAt.Equal(c.PostedAt) || decoded.Rank != c.Rank || decoded.ID != c.ID || !decoded.Before {
+		t.Errorf("DecodeCursor() = %+v, want %+v", decoded, c)
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	for _, s := range []string{"", "not base64!", "e30", Cursor{ID: 1}.Encode()} {
+		if _, err := DecodeCursor(s); err != ErrInvalidCursor {
+			t.Errorf("DecodeCursor(%q) error = %v, want ErrInvalidCursor", s, err)
+		}
+	}
+}
@@ -2,6 +2,15 @@ package models
 
 import (
 	"time"
+
+	"github.com/abhisheksainimitawa/job-aggregator/internal/geo"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/querylang"
+)
+
+// Listing statuses
+const (
+	JobStatusOpen   = "open"
+	JobStatusClosed = "closed"
 )
 
 // Job represents a job listing aggregated from various sources
@@ -9,9 +18,10 @@ type Job struct {
 	ID          int64     `json:"id" db:"id"`
 	Title       string    `json:"title" db:"title"`
 	Company     string    `json:"company" db:"company"`
+	CompanyID   *int64    `json:"company_id,omitempty" db:"company_id"` // Canonical company, see companies table
 	Location    string    `json:"location" db:"location"`
 	Salary      string    `json:"salary,omitempty" db:"salary"`
-	Description string    `json:"description" db:"description"`
+	Description string    `json:"description" db:"description"` // Plain text, used for search
 	URL         string    `json:"url" db:"url"`
 	Source      string    `json:"source" db:"source"` // indeed, linkedin, etc.
 	RemoteOk    bool      `json:"remote_ok" db:"remote_ok"`
@@ -21,30 +31,218 @@ type Job struct {
 	Hash        string    `json:"-" db:"hash"` // For deduplication
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	Tags        []string  `json:"tags" db:"-"` // Skill tags, stored in job_tags
+
+	// Sanitized renditions of the description (see internal/sanitize)
+	DescriptionHTML     string `json:"-" db:"description_html"`
+	DescriptionMarkdown string `json:"-" db:"description_markdown"`
+
+	// Canonical posting URL without tracking parameters, and the job ID
+	// assigned by the board (see pkg/urlcanon)
+	CanonicalURL string `json:"canonical_url" db:"canonical_url"`
+	NativeID     string `json:"native_id,omitempty" db:"native_id"`
+
+	// Listing lifecycle (see internal/lifecycle). A job is closed after
+	// its source stops returning it for several runs in a row.
+	Status      string     `json:"status" db:"status"`
+	FirstSeenAt time.Time  `json:"first_seen_at" db:"first_seen_at"`
+	LastSeenAt  time.Time  `json:"last_seen_at" db:"last_seen_at"`
+	ClosedAt    *time.Time `json:"closed_at,omitempty" db:"closed_at"`
+
+	// How far PostedAt can be trusted: exact, day, approximate or unknown
+	// (see pkg/dateparse)
+	PostedAtPrecision string `json:"posted_at_precision" db:"posted_at_precision"`
+
+	// Near-duplicate clustering (see internal/dedup). The canonical job of
+	// a cluster has ClusterID equal to its own ID and lists every source
+	// listing of the cluster in Listings.
+	ClusterID   *int64       `json:"cluster_id,omitempty" db:"cluster_id"`
+	Fingerprint int64        `json:"-" db:"fingerprint"`
+	Listings    []JobListing `json:"listings,omitempty" db:"-"`
+
+	// Salary parsed from Salary as yearly amounts (see pkg/salary), nil
+	// when it could not be parsed
+	SalaryMin      *int64 `json:"salary_min,omitempty" db:"salary_min"`
+	SalaryMax      *int64 `json:"salary_max,omitempty" db:"salary_max"`
+	SalaryCurrency string `json:"salary_currency,omitempty" db:"salary_currency"`
+
+	// Coordinates of Location (see internal/geo), nil when unknown. The
+	// distance from the search's near point is set when sorting by distance.
+	Latitude   *float64 `json:"latitude,omitempty" db:"latitude"`
+	Longitude  *float64 `json:"longitude,omitempty" db:"longitude"`
+	DistanceKm *float64 `json:"distance_km,omitempty" db:"-"`
+
+	// Description excerpt with search keywords wrapped in <mark>, set
+	// only on keyword search results
+	Snippet string `json:"snippet,omitempty" db:"-"`
+
+	// Title normalization (see internal/titles)
+	NormalizedTitle string `json:"normalized_title" db:"normalized_title"`
+	Seniority       string `json:"seniority" db:"seniority"`
+	RoleFamily      string `json:"role_family" db:"role_family"`
+
+	// Data quality from 0 to 100 and the factors behind it (see
+	// internal/quality)
+	QualityScore   int             `json:"quality_score" db:"quality_score"`
+	QualityReasons []QualityReason `json:"quality_reasons,omitempty" db:"quality_reasons"`
+
+	// Scam and spam detection (see internal/scam). FlagReview is an
+	// admin's verdict on the job, which outlives re-scrapes.
+	Flagged     bool     `json:"flagged" db:"flagged"`
+	FlagReasons []string `json:"flag_reasons,omitempty" db:"flag_reasons"`
+	FlagReview  string   `json:"flag_review,omitempty" db:"flag_review"`
+}
+
+// Flag reviews, also the labels of the scam classifier's training data
+const (
+	FlagReviewScam  = "scam"
+	FlagReviewLegit = "legit"
+)
+
+// Suspicious reports whether a job is hidden from search by default:
+// flagged and not cleared by an admin, or confirmed as a scam
+func (j *Job) Suspicious() bool {
+	return j.FlagReview == FlagReviewScam || (j.Flagged && j.FlagReview == "")
+}
+
+// Flag filters of a search. By default suspicious jobs are hidden.
+const (
+	FlaggedInclude    = "include"    // Suspicious jobs too
+	FlaggedOnly       = "only"       // Only suspicious jobs
+	FlaggedUnreviewed = "unreviewed" // Flagged jobs awaiting review
+)
+
+// IsFlaggedFilter reports whether s is a flag filter, "" included
+func IsFlaggedFilter(s string) bool {
+	switch s {
+	case "", FlaggedInclude, FlaggedOnly, FlaggedUnreviewed:
+		return true
+	}
+	return false
+}
+
+// JobListing is one source posting of a canonical job
+type JobListing struct {
+	JobID    int64     `json:"job_id"`
+	Source   string    `json:"source"`
+	URL      string    `json:"url"`
+	PostedAt time.Time `json:"posted_at"`
 }
 
+// Search sort fields. SortNewest is an alias of SortPostedAt.
+const (
+	SortPostedAt  = "posted_at"
+	SortScrapedAt = "scraped_at"
+	SortSalary    = "salary"
+	SortCompany   = "company"
+	SortRelevance = "relevance"
+	SortDistance  = "distance"
+	SortNewest    = "newest"
+)
+
+// Sort directions
+const (
+	OrderAsc  = "asc"
+	OrderDesc = "desc"
+)
+
 // JobSearchQuery represents search parameters
 type JobSearchQuery struct {
-	Keywords  string
-	Location  string
-	Remote    *bool
-	JobType   string
-	Source    string
-	MinSalary int
-	Page      int
-	Limit     int
+	Keywords   string
+	Location   string
+	Remote     *bool
+	JobType    string
+	Source     string
+	MinSalary  int
+	MinQuality int // Jobs must have at least this quality score
+	CompanyID  int64
+	Seniority  string
+	RoleFamily string
+	Skills     []string // Jobs must carry every one of these tags
+	SkillsAny  []string // Jobs must carry at least one of these tags
+
+	SalaryChangedSince time.Time // Salary changed at or after this time
+	Status             string    // open (default), closed or all
+	Flagged            string    // One of the Flagged filters; suspicious jobs are hidden by default
+	PostedAfter        time.Time // Posted at or after this time
+	PostedBefore       time.Time // Posted before this time
+	UpdatedSince       time.Time // Updated at or after this time
+
+	Expr querylang.Node // Advanced query (qx), combined with the filters above
+
+	Sort  string     // One of the Sort constants, posted_at by default
+	Order string     // asc or desc; defaults to asc for company and distance
+	Near  *geo.Point // Origin for sorting by distance
+
+	Page   int
+	Limit  int
+	Cursor *Cursor // Keyset position; replaces Page when set
+}
+
+// SortField returns the effective sort field
+func (q *JobSearchQuery) SortField() string {
+	if q.Sort == "" || q.Sort == SortNewest {
+		return SortPostedAt
+	}
+	return q.Sort
+}
+
+// Descending reports whether results are sorted in descending order
+func (q *JobSearchQuery) Descending() bool {
+	switch q.Order {
+	case OrderAsc:
+		return false
+	case OrderDesc:
+		return true
+	}
+	field := q.SortField()
+	return field != SortCompany && field != SortDistance
+}
+
+// Newest reports whether the query uses the default newest-first order,
+// the only order that supports cursors
+func (q *JobSearchQuery) Newest() bool {
+	return q.SortField() == SortPostedAt && q.Descending()
+}
+
+// FacetFields are the facets a search can request counts for
+var FacetFields = []string{"source", "job_type", "remote_ok", "location", "company", "seniority", "role_family", "skills"}
+
+// FacetCount is the number of jobs with one facet value. Label is the
+// display name when Value is an ID, as for companies.
+type FacetCount struct {
+	Value string `json:"value"`
+	Label string `json:"label,omitempty"`
+	Count int64  `json:"count"`
 }
 
 // JobStats represents aggregated statistics
 type JobStats struct {
-	TotalJobs       int64            `json:"total_jobs"`
-	JobsBySource    map[string]int64 `json:"jobs_by_source"`
-	JobsByType      map[string]int64 `json:"jobs_by_type"`
-	RemoteJobs      int64            `json:"remote_jobs"`
-	TodayJobs       int64            `json:"today_jobs"`
-	LastScrapedAt   time.Time        `json:"last_scraped_at"`
-	TopCompanies    []CompanyCount   `json:"top_companies"`
-	TopLocations    []LocationCount  `json:"top_locations"`
+	TotalJobs        int64            `json:"total_jobs"`
+	JobsBySource     map[string]int64 `json:"jobs_by_source"`
+	JobsByType       map[string]int64 `json:"jobs_by_type"`
+	JobsBySeniority  map[string]int64 `json:"jobs_by_seniority"`
+	JobsByRoleFamily map[string]int64 `json:"jobs_by_role_family"`
+	RemoteJobs       int64            `json:"remote_jobs"`
+	TodayJobs        int64            `json:"today_jobs"`
+	LastScrapedAt    time.Time        `json:"last_scraped_at"`
+	TopCompanies     []CompanyCount   `json:"top_companies"`
+	TopLocations     []LocationCount  `json:"top_locations"`
+	TopSkills        []SkillCount     `json:"top_skills"`
+
+	// Lifecycle of listings; time open runs from first to last sighting
+	OpenJobs       int64   `json:"open_jobs"`
+	ClosedJobs     int64   `json:"closed_jobs"`
+	AvgDaysOpen    float64 `json:"avg_days_open"`
+	MedianDaysOpen float64 `json:"median_days_open"`
+
+	// Quality scores: the average and job counts per QualityBands band
+	AvgQuality    float64          `json:"avg_quality"`
+	JobsByQuality map[string]int64 `json:"jobs_by_quality"`
+
+	// When the figures were computed; stores that precompute them may
+	// lag behind the jobs
+	RefreshedAt time.Time `json:"refreshed_at"`
 }
 
 // CompanyCount represents job count by company
@@ -59,6 +257,12 @@ type LocationCount struct {
 	Count    int64  `json:"count"`
 }
 
+// SkillCount represents job count by skill tag
+type SkillCount struct {
+	Skill string `json:"skill"`
+	Count int64  `json:"count"`
+}
+
 // ScraperStatus represents the current status of the scraper
 type ScraperStatus struct {
 	IsRunning      bool      `json:"is_running"`
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+)
+
+// FieldChange holds the previous and new value of a changed field
+type FieldChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// JobVersion records the fields that changed when a job was re-scraped
+type JobVersion struct {
+	ID        int64                  `json:"id" db:"id"`
+	JobID     int64                  `json:"job_id" db:"job_id"`
+	ChangedAt time.Time              `json:"changed_at" db:"changed_at"`
+	Changes   map[string]FieldChange `json:"changes" db:"changes"`
+}
+
+// DiffJobs returns the tracked fields that differ between a stored job
+// and its re-scraped version, keyed by column name. Fields that are
+// recomputed on every scrape, like relative posted dates, are ignored.
+func DiffJobs(old, new *Job) map[string]FieldChange {
+	changes := make(map[string]FieldChange)
+
+	diffString := func(field, a, b string) {
+		if a != b {
+			changes[field] = FieldChange{Old: a, New: b}
+		}
+	}
+
+	diffString("title", old.Title, new.Title)
+	diffString("company", old.Company, new.Company)
+	diffString("location", old.Location, new.Location)
+	diffString("salary", old.Salary, new.Salary)
+	diffString("description", old.Description, new.Description)
+	diffString("job_type", old.JobType, new.JobType)
+	diffString("canonical_url", old.CanonicalURL, new.CanonicalURL)
+
+	if old.RemoteOk != new.RemoteOk {
+		changes["remote_ok"] = FieldChange{Old: old.RemoteOk, New: new.RemoteOk}
+	}
+
+	return changes
+}
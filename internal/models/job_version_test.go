@@ -0,0 +1,35 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDiffJobs(t *testing.T) {
+	old := &Job{
+		Title:       "Go Developer",
+		Salary:      "$120k - $150k",
+		Description: "Build services",
+		RemoteOk:    false,
+		PostedAt:    time.Now().Add(-48 * time.Hour),
+	}
+	updated := *old
+	updated.Salary = "$140k - $170k"
+	updated.RemoteOk = true
+	updated.PostedAt = time.Now()
+
+	changes := DiffJobs(old, &updated)
+	if len(changes) != 2 {
+		t.Fatalf("Expected 2 changes, got %d: %v", len(changes), changes)
+	}
+	if c := changes["salary"]; c.Old != "$120k - $150k" || c.New != "$140k - $170k" {
+		t.Errorf("salary change = %+v", c)
+	}
+	if c := changes["remote_ok"]; c.Old != false || c.New != true {
+		t.Errorf("remote_ok change = %+v", c)
+	}
+
+	if changes := DiffJobs(old, old); len(changes) != 0 {
+		t.Errorf("Expected no changes for identical jobs, got %v", changes)
+	}
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// JobPartition is a monthly posted_at partition of the jobs table
+type JobPartition struct {
+	Name     string    `json:"name"`
+	Month    time.Time `json:"month"`    // Zero for the default partition
+	Attached bool      `json:"attached"` // False while it is being archived
+	Rows     int64     `json:"rows"`     // Planner estimate
+}
+
+// Default reports whether p is the partition of jobs outside every month
+func (p JobPartition) Default() bool {
+	return p.Month.IsZero()
+}
@@ -0,0 +1,27 @@
+package models
+
+// MaxQualityScore is the best quality score a job can have
+const MaxQualityScore = 100
+
+// QualityReason is one factor of a job's quality score. Points are
+// negative for penalties.
+type QualityReason struct {
+	Factor string `json:"factor"`
+	Points int    `json:"points"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// QualityBands label the ranges of quality scores counted in stats
+var QualityBands = []string{"0-19", "20-39", "40-59", "60-79", "80-100"}
+
+// QualityBand returns the band of a quality score
+func QualityBand(score int) string {
+	i := score / 20
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(QualityBands) {
+		i = len(QualityBands) - 1
+	}
+	return QualityBands[i]
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Columns a retention rule can measure the age of a job from
+const (
+	RetentionFromPosted   = "posted_at"
+	RetentionFromLastSeen = "last_seen_at"
+	RetentionFromClosed   = "closed_at"
+)
+
+// RetentionRule deletes the jobs of a source and status once they are
+// older than AfterDays. Empty Source and Status match any.
+type RetentionRule struct {
+	Name      string `json:"name"`
+	Source    string `json:"source,omitempty"`
+	Status    string `json:"status,omitempty"`
+	AfterDays int    `json:"after_days"`
+
+	// From is the column the age is measured from: posted_at by default,
+	// closed_at for rules on closed jobs
+	From string `json:"from,omitempty"`
+}
+
+// AgeColumn returns the column the age of matching jobs is measured from
+func (r *RetentionRule) AgeColumn() string {
+	if r.From != "" {
+		return r.From
+	}
+	if r.Status == JobStatusClosed {
+		return RetentionFromClosed
+	}
+	return RetentionFromPosted
+}
+
+// Validate checks the rule and names it after its conditions if unnamed
+func (r *RetentionRule) Validate() error {
+	if r.AfterDays < 1 {
+		return fmt.Errorf("retention rule %q: after_days must be at least 1", r.Name)
+	}
+	switch r.Status {
+	case "", JobStatusOpen, JobStatusClosed:
+	default:
+		return fmt.Errorf("retention rule %q: unknown status %q", r.Name, r.Status)
+	}
+	switch r.AgeColumn() {
+	case RetentionFromPosted, RetentionFromLastSeen, RetentionFromClosed:
+	default:
+		return fmt.Errorf("retention rule %q: unknown from %q", r.Name, r.From)
+	}
+
+	if r.Name == "" {
+		r.Name = fmt.Sprintf("source=%s status=%s %s+%dd", orAny(r.Source), orAny(r.Status), r.AgeColumn(), r.AfterDays)
+	}
+	return nil
+}
+
+func orAny(s string) string {
+	if s == "" {
+		return "any"
+	}
+	return s
+}
+
+// RetentionRun is the audit record of a retention run
+type RetentionRun struct {
+	ID         int64                 `json:"id,omitempty"`
+	StartedAt  time.Time             `json:"started_at"`
+	FinishedAt time.Time             `json:"finished_at"`
+	DryRun     bool                  `json:"dry_run"`
+	Deleted    int64                 `json:"deleted"`
+	Rules      []RetentionRuleResult `json:"rules"`
+	Error      string                `json:"error,omitempty"`
+}
+
+// RetentionRuleResult is what one rule of a run matched or deleted.
+// Rules run in order, so a job matching several rules is deleted by the
+// first; a dry run counts it for each.
+type RetentionRuleResult struct {
+	Rule    RetentionRule `json:"rule"`
+	Cutoff  time.Time     `json:"cutoff"`
+	Matched int64         `json:"matched"` // Dry runs only
+	Deleted int64         `json:"deleted"`
+	Batches int           `json:"batches"`
+}
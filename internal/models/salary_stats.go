@@ -0,0 +1,40 @@
+package models
+
+// SalaryGroupFields are the job fields salary statistics can be grouped by
+var SalaryGroupFields = []string{"normalized_title", "seniority", "location", "remote_ok", "company"}
+
+// DefaultSalaryMinSamples is the fewest salaries a group needs to be
+// reported when the query sets no threshold
+const DefaultSalaryMinSamples = 5
+
+// IsSalaryGroupField reports whether name is a supported salary grouping
+func IsSalaryGroupField(name string) bool {
+	for _, field := range SalaryGroupFields {
+		if field == name {
+			return true
+		}
+	}
+	return false
+}
+
+// SalaryStatsQuery selects the jobs whose salaries are summarized and how
+// they are grouped
+type SalaryStatsQuery struct {
+	Filter     *JobSearchQuery // Jobs summarized; paging and sorting are ignored
+	GroupBy    []string        // SalaryGroupFields; none summarizes all jobs at once
+	MinSamples int             // Groups with fewer salaries are left out
+	Limit      int             // Largest groups returned
+}
+
+// SalaryStats summarizes the yearly salaries of one group of jobs in one
+// currency. A job's salary is the midpoint of its range.
+type SalaryStats struct {
+	Group    map[string]string `json:"group"` // Value of each grouping field
+	Currency string            `json:"currency"`
+	Samples  int64             `json:"samples"`
+	P10      int64             `json:"p10"`
+	P25      int64             `json:"p25"`
+	Median   int64             `json:"median"`
+	P75      int64             `json:"p75"`
+	P90      int64             `json:"p90"`
+}
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"time"
+
+	"github.com/abhisheksainimitawa/job-aggregator/pkg/dateparse"
+)
+
+// HealthFields are the job fields whose fill rate is tracked per source run
+var HealthFields = []string{"company", "location", "description", "salary", "posted_at"}
+
+// Source health statuses
+const (
+	SourceHealthy  = "healthy"
+	SourceDegraded = "degraded" // The last run had anomalies
+	SourceFailing  = "failing"  // The last run failed
+)
+
+// SourceRun is what one source returned in one scrape run
+type SourceRun struct {
+	ID            int64              `json:"id"`
+	Source        string             `json:"source"`
+	StartedAt     time.Time          `json:"started_at"`
+	LatencyMs     int64              `json:"latency_ms"`
+	Jobs          int                `json:"jobs"`
+	ParseFailures int                `json:"parse_failures"` // Jobs without a title or URL
+	FillRates     map[string]float64 `json:"fill_rates"`     // Share of jobs with each of HealthFields
+	Error         string             `json:"error,omitempty"`
+	Anomalies     []string           `json:"anomalies,omitempty"` // Deviations from the source's baseline
+}
+
+// ParseFailureRate returns the share of jobs that failed to parse
+func (r *SourceRun) ParseFailureRate() float64 {
+	if r.Jobs == 0 {
+		return 0
+	}
+	return float64(r.ParseFailures) / float64(r.Jobs)
+}
+
+// MeasureSourceRun returns the run of a source that returned jobs, or
+// failed with err, after scraping for latency
+func MeasureSourceRun(source string, startedAt time.Time, latency time.Duration, jobs []*Job, err error) SourceRun {
+	run := SourceRun{
+		Source:    source,
+		StartedAt: startedAt,
+		LatencyMs: latency.Milliseconds(),
+		Jobs:      len(jobs),
+		FillRates: make(map[string]float64, len(HealthFields)),
+	}
+	if err != nil {
+		run.Error = err.Error()
+	}
+
+	filled := make(map[string]int, len(HealthFields))
+	for _, job := range jobs {
+		if job.Title == "" || job.URL == "" {
+			run.ParseFailures++
+		}
+		for field, ok := range map[string]bool{
+			"company":     job.Company != "",
+			"location":    job.Location != "",
+			"description": job.Description != "",
+			"salary":      job.Salary != "",
+			"posted_at":   job.PostedAtPrecision != "" && job.PostedAtPrecision != string(dateparse.PrecisionUnknown),
+		} {
+			if ok {
+				filled[field]++
+			}
+		}
+	}
+	for _, field := range HealthFields {
+		if len(jobs) > 0 {
+			run.FillRates[field] = float64(filled[field]) / float64(len(jobs))
+		}
+	}
+	return run
+}
+
+// HealthBaseline is the average of a source's recent successful runs
+type HealthBaseline struct {
+	Runs             int                `json:"runs"`
+	Jobs             float64            `json:"jobs"`
+	ParseFailureRate float64            `json:"parse_failure_rate"`
+	FillRates        map[string]float64 `json:"fill_rates"`
+	LatencyMs        float64            `json:"latency_ms"`
+}
+
+// SourceHealth is the latest run of a source judged against its baseline
+type SourceHealth struct {
+	Source   string          `json:"source"`
+	Status   string          `json:"status"`
+	LastRun  *SourceRun      `json:"last_run"`
+	Baseline *HealthBaseline `json:"baseline,omitempty"` // Runs before the last one; nil without history
+}
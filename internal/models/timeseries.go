@@ -0,0 +1,69 @@
+package models
+
+import "time"
+
+// Time series intervals
+const (
+	IntervalDay   = "day"
+	IntervalWeek  = "week"
+	IntervalMonth = "month"
+)
+
+// IsInterval reports whether s is a supported time series interval
+func IsInterval(s string) bool {
+	return s == IntervalDay || s == IntervalWeek || s == IntervalMonth
+}
+
+// BucketStart returns the start of the interval containing t, in UTC.
+// Weeks start on Monday.
+func BucketStart(t time.Time, interval string) time.Time {
+	y, m, d := t.UTC().Date()
+	switch interval {
+	case IntervalMonth:
+		return time.Date(y, m, 1, 0, 0, 0, 0, time.UTC)
+	case IntervalWeek:
+		day := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	default:
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// NextBucket returns the start of the interval after the one starting at
+// start
+func NextBucket(start time.Time, interval string) time.Time {
+	switch interval {
+	case IntervalMonth:
+		return start.AddDate(0, 1, 0)
+	case IntervalWeek:
+		return start.AddDate(0, 0, 7)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// TimeSeriesQuery selects the buckets of a job time series and the jobs
+// it counts
+type TimeSeriesQuery struct {
+	Filter   *JobSearchQuery // Jobs counted; paging and sorting are ignored
+	Interval string
+	From     time.Time // Counted from the start of its bucket
+	To       time.Time // Counted to the end of its bucket
+}
+
+// Bounds returns the start of every bucket followed by the end of the
+// last one
+func (q *TimeSeriesQuery) Bounds() []time.Time {
+	bounds := []time.Time{BucketStart(q.From, q.Interval)}
+	for !bounds[len(bounds)-1].After(q.To) {
+		bounds = append(bounds, NextBucket(bounds[len(bounds)-1], q.Interval))
+	}
+	return bounds
+}
+
+// TimeSeriesPoint counts the postings of one bucket of a time series
+type TimeSeriesPoint struct {
+	Start  time.Time `json:"start"`
+	New    int64     `json:"new"`    // Posted during the bucket
+	Active int64     `json:"active"` // Listed at some point during the bucket
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBucketStart(t *testing.T) {
+	// A Thursday evening west of UTC, already Friday in UTC
+	at := time.Date(2024, 2, 29, 20, 30, 0, 0, time.FixedZone("EST", -5*3600))
+
+	tests := []struct {
+		interval string
+		want     time.Time
+	}{
+		{IntervalDay, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{IntervalWeek, time.Date(2024, 2, 26, 0, 0, 0, 0, time.UTC)},
+		{IntervalMonth, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		if got := BucketStart(at, tt.interval); !got.Equal(tt.want) {
+			t.Errorf("BucketStart(%s) = %v, want %v", tt.interval, got, tt.want)
+		}
+	}
+}
+
+func TestTimeSeriesBounds(t *testing.T) {
+	query := &TimeSeriesQuery{
+		Interval: IntervalWeek,
+		From:     time.Date(2024, 3, 6, 12, 0, 0, 0, time.UTC),
+		To:       time.Date(2024, 3, 18, 0, 0, 0, 0, time.UTC),
+	}
+
+	bounds := query.Bounds()
+	want := []time.Time{
+		time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 18, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 25, 0, 0, 0, 0, time.UTC),
+	}
+	if len(bounds) != len(want) {
+		t.Fatalf("Bounds() = %v, want %v", bounds, want)
+	}
+	for i := range want {
+		if !bounds[i].Equal(want[i]) {
+			t.Errorf("Bounds()[%d] = %v, want %v", i, bounds[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,109 @@
+// Package partition maintains the monthly partitions of the jobs table:
+// it creates partitions ahead of time and archives old ones to files.
+package partition
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/abhisheksainimitawa/job-aggregator/internal/repository"
+	"github.com/abhisheksainimitawa/job-aggregator/pkg/logger"
+)
+
+// Archived describes an archived partition
+type Archived struct {
+	Partition string
+	Path      string
+	Jobs      int64
+}
+
+// Archiver moves old partitions into archive files and restores them
+type Archiver struct {
+	repo   *repository.PartitionRepository
+	dir    string
+	format Format
+}
+
+// NewArchiver creates an archiver writing files in format to dir
+func NewArchiver(repo *repository.PartitionRepository, dir string, format Format) *Archiver {
+	return &Archiver{repo: repo, dir: dir, format: format}
+}
+
+// Archive detaches the partition of month, exports its jobs to a file and
+// drops it. The partition stays detached if the export fails; archiving
+// the month again resumes from there.
+func (a *Archiver) Archive(ctx context.Context, month time.Time) (*Archived, error) {
+	name := repository.PartitionName(month)
+	if err := os.MkdirAll(a.dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create archive directory: %w", err)
+	}
+	path := filepath.Join(a.dir, name+a.format.Ext())
+
+	if err := a.repo.Detach(ctx, month); err != nil {
+		return nil, err
+	}
+
+	w, err := Create(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.repo.ExportDetached(ctx, month, w.Write); err != nil {
+		w.Abort()
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	deleted, err := a.repo.DropDetached(ctx, month)
+	if err != nil {
+		return nil, err
+	}
+	if deleted != w.Count() {
+		logger.Error("Archived %d jobs of %s but deleted %d", w.Count(), name, deleted)
+	}
+
+	logger.Info("Archived %d jobs of %s to %s", w.Count(), name, path)
+	return &Archived{Partition: name, Path: path, Jobs: w.Count()}, nil
+}
+
+// ArchiveBefore archives every monthly partition, attached or left
+// detached by an earlier run, of a month before the month of before
+func (a *Archiver) ArchiveBefore(ctx context.Context, before time.Time) ([]*Archived, error) {
+	partitions, err := a.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var archived []*Archived
+	for _, p := range partitions {
+		if p.Default() || !p.Month.Before(repository.MonthOf(before)) {
+			continue
+		}
+		result, err := a.Archive(ctx, p.Month)
+		if err != nil {
+			return archived, err
+		}
+		archived = append(archived, result)
+	}
+	return archived, nil
+}
+
+// Restore loads the jobs of an archive file back into jobs
+func (a *Archiver) Restore(ctx context.Context, path string) (restored, skipped int64, err error) {
+	r, err := Open(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer r.Close()
+
+	if restored, skipped, err = a.repo.Restore(ctx, r.Read); err != nil {
+		return 0, 0, err
+	}
+
+	logger.Info("Restored %d jobs from %s, skipped %d stored again since", restored, path, skipped)
+	return restored, skipped, nil
+}
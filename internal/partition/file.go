@@ -0,0 +1,341 @@
+package partition
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/abhisheksainimitawa/job-aggregator/internal/models"
+	"github.com/parquet-go/parquet-go"
+)
+
+// Format is the file format of an archive
+type Format string
+
+// Archive formats
+const (
+	NDJSON  Format = "ndjson"  // Gzip-compressed newline-delimited JSON
+	Parquet Format = "parquet" // Zstd-compressed Parquet
+)
+
+// extensions are the file name extensions of the formats
+var extensions = map[Format]string{
+	NDJSON:  ".ndjson.gz",
+	Parquet: ".parquet",
+}
+
+// ParseFormat parses a format name
+func ParseFormat(name string) (Format, error) {
+	f := Format(strings.ToLower(name))
+	if _, ok := extensions[f]; !ok {
+		return "", fmt.Errorf("unknown archive format %q, expected ndjson or parquet", name)
+	}
+	return f, nil
+}
+
+// Ext returns the file name extension of the format
+func (f Format) Ext() string {
+	return extensions[f]
+}
+
+// FormatOf returns the format of an archive file from its extension
+func FormatOf(path string) (Format, error) {
+	for f, ext := range extensions {
+		if strings.HasSuffix(path, ext) {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unknown archive file %s, expected a .ndjson.gz or .parquet file", path)
+}
+
+// parquetBatch is the number of records buffered per Parquet read or write
+const parquetBatch = 1024
+
+// Record is an archived job. It holds every stored column, unlike the API
+// representation of a job, so a restore recreates the job as it was.
+type Record struct {
+	ID                  int64                  `json:"id" parquet:"id"`
+	Title               string                 `json:"title" parquet:"title"`
+	Company             string                 `json:"company" parquet:"company,dict"`
+	CompanyID           *int64                 `json:"company_id,omitempty" parquet:"company_id,optional"`
+	Location            string                 `json:"location" parquet:"location,dict"`
+	Salary              string                 `json:"salary" parquet:"salary"`
+	SalaryMin           *int64                 `json:"salary_min,omitempty" parquet:"salary_min,optional"`
+	SalaryMax           *int64                 `json:"salary_max,omitempty" parquet:"salary_max,optional"`
+	SalaryCurrency      string                 `json:"salary_currency" parquet:"salary_currency,dict"`
+	Description         string                 `json:"description" parquet:"description"`
+	DescriptionHTML     string                 `json:"description_html" parquet:"description_html"`
+	DescriptionMarkdown string                 `json:"description_markdown" parquet:"description_markdown"`
+	URL                 string                 `json:"url" parquet:"url"`
+	CanonicalURL        string                 `json:"canonical_url" parquet:"canonical_url"`
+	Source              string                 `json:"source" parquet:"source,dict"`
+	NativeID            string                 `json:"native_id" parquet:"native_id"`
+	Hash                string                 `json:"hash" parquet:"hash"`
+	RemoteOk            bool                   `json:"remote_ok" parquet:"remote_ok"`
+	JobType             string                 `json:"job_type" parquet:"job_type,dict"`
+	PostedAt            time.Time              `json:"posted_at" parquet:"posted_at,timestamp(microsecond)"`
+	PostedAtPrecision   string                 `json:"posted_at_precision" parquet:"posted_at_precision,dict"`
+	ScrapedAt           time.Time              `json:"scraped_at" parquet:"scraped_at,timestamp(microsecond)"`
+	CreatedAt           time.Time              `json:"created_at" parquet:"created_at,timestamp(microsecond)"`
+	UpdatedAt           time.Time              `json:"updated_at" parquet:"updated_at,timestamp(microsecond)"`
+	Status              string                 `json:"status" parquet:"status,dict"`
+	FirstSeenAt         time.Time              `json:"first_seen_at" parquet:"first_seen_at,timestamp(microsecond)"`
+	LastSeenAt          time.Time              `json:"last_seen_at" parquet:"last_seen_at,timestamp(microsecond)"`
+	ClosedAt            *time.Time             `json:"closed_at,omitempty" parquet:"closed_at,optional"`
+	NormalizedTitle     string                 `json:"normalized_title" parquet:"normalized_title,dict"`
+	Seniority           string                 `json:"seniority" parquet:"seniority,dict"`
+	RoleFamily          string                 `json:"role_family" parquet:"role_family,dict"`
+	ClusterID           *int64                 `json:"cluster_id,omitempty" parquet:"cluster_id,optional"`
+	Fingerprint         int64                  `json:"fingerprint" parquet:"fingerprint"`
+	Latitude            *float64               `json:"latitude,omitempty" parquet:"latitude,optional"`
+	Longitude           *float64               `json:"longitude,omitempty" parquet:"longitude,optional"`
+	QualityScore        int                    `json:"quality_score" parquet:"quality_score"`
+	QualityReasons      []models.QualityReason `json:"quality_reasons,omitempty" parquet:"quality_reasons,list"`
+	Flagged             bool                   `json:"flagged" parquet:"flagged"`
+	FlagReasons         []string               `json:"flag_reasons,omitempty" parquet:"flag_reasons,list"`
+	FlagReview          string                 `json:"flag_review" parquet:"flag_review,dict"`
+	Tags                []string               `json:"tags" parquet:"tags,list"`
+}
+
+// NewRecord returns the archive record of a stored job
+func NewRecord(job *models.Job) Record {
+	return Record{
+		ID: job.ID, Title: job.Title, Company: job.Company, CompanyID: job.CompanyID,
+		Location: job.Location, Salary: job.Salary, SalaryMin: job.SalaryMin, SalaryMax: job.SalaryMax,
+		SalaryCurrency: job.SalaryCurrency, Description: job.Description,
+		DescriptionHTML: job.DescriptionHTML, DescriptionMarkdown: job.DescriptionMarkdown,
+		URL: job.URL, CanonicalURL: job.CanonicalURL, Source: job.Source, NativeID: job.NativeID,
+		Hash: job.Hash, RemoteOk: job.RemoteOk, JobType: job.JobType,
+		PostedAt: job.PostedAt, PostedAtPrecision: job.PostedAtPrecision, ScrapedAt: job.ScrapedAt,
+		CreatedAt: job.CreatedAt, UpdatedAt: job.UpdatedAt, Status: job.Status,
+		FirstSeenAt: job.FirstSeenAt, LastSeenAt: job.LastSeenAt, ClosedAt: job.ClosedAt,
+		NormalizedTitle: job.NormalizedTitle, Seniority: job.Seniority, RoleFamily: job.RoleFamily,
+		ClusterID: job.ClusterID, Fingerprint: job.Fingerprint,
+		Latitude: job.Latitude, Longitude: job.Longitude, Tags: job.Tags,
+		QualityScore: job.QualityScore, QualityReasons: job.QualityReasons,
+		Flagged: job.Flagged, FlagReasons: job.FlagReasons, FlagReview: job.FlagReview,
+	}
+}
+
+// Job returns the job of an archive record
+func (r *Record) Job() *models.Job {
+	return &models.Job{
+		ID: r.ID, Title: r.Title, Company: r.Company, CompanyID: r.CompanyID,
+		Location: r.Location, Salary: r.Salary, SalaryMin: r.SalaryMin, SalaryMax: r.SalaryMax,
+		SalaryCurrency: r.SalaryCurrency, Description: r.Description,
+		DescriptionHTML: r.DescriptionHTML, DescriptionMarkdown: r.DescriptionMarkdown,
+		URL: r.URL, CanonicalURL: r.CanonicalURL, Source: r.Source, NativeID: r.NativeID,
+		Hash: r.Hash, RemoteOk: r.RemoteOk, JobType: r.JobType,
+		PostedAt: r.PostedAt, PostedAtPrecision: r.PostedAtPrecision, ScrapedAt: r.ScrapedAt,
+		CreatedAt: r.CreatedAt, UpdatedAt: r.UpdatedAt, Status: r.Status,
+		FirstSeenAt: r.FirstSeenAt, LastSeenAt: r.LastSeenAt, ClosedAt: r.ClosedAt,
+		NormalizedTitle: r.NormalizedTitle, Seniority: r.Seniority, RoleFamily: r.RoleFamily,
+		ClusterID: r.ClusterID, Fingerprint: r.Fingerprint,
+		Latitude: r.Latitude, Longitude: r.Longitude, Tags: r.Tags,
+		QualityScore: r.QualityScore, QualityReasons: r.QualityReasons,
+		Flagged: r.Flagged, FlagReasons: r.FlagReasons, FlagReview: r.FlagReview,
+	}
+}
+
+// Writer writes jobs to an archive file. Jobs go to a temporary file that
+// Close renames into place, so a failed archive never leaves a partial
+// file under the final name.
+type Writer struct {
+	path  string
+	file  *os.File
+	buf   *bufio.Writer
+	count int64
+
+	// Set depending on the format
+	gzip    *gzip.Writer
+	json    *json.Encoder
+	parquet *parquet.GenericWriter[Record]
+	pending []Record
+}
+
+// Create starts an archive file at path, in the format of its extension
+func Create(path string) (*Writer, error) {
+	format, err := FormatOf(path)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Create(path + ".tmp")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create archive: %w", err)
+	}
+
+	w := &Writer{path: path, file: file, buf: bufio.NewWriter(file)}
+	switch format {
+	case NDJSON:
+		w.gzip = gzip.NewWriter(w.buf)
+		w.json = json.NewEncoder(w.gzip)
+	case Parquet:
+		w.parquet = parquet.NewGenericWriter[Record](w.buf, parquet.Compression(&parquet.Zstd))
+	}
+	return w, nil
+}
+
+// Write appends a job to the archive
+func (w *Writer) Write(job *models.Job) error {
+	w.count++
+	if w.json != nil {
+		if err := w.json.Encode(NewRecord(job)); err != nil {
+			return fmt.Errorf("failed to write archive: %w", err)
+		}
+		return nil
+	}
+
+	w.pending = append(w.pending, NewRecord(job))
+	if len(w.pending) < parquetBatch {
+		return nil
+	}
+	return w.flushParquet()
+}
+
+// flushParquet writes the buffered Parquet records
+func (w *Writer) flushParquet() error {
+	if _, err := w.parquet.Write(w.pending); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	w.pending = w.pending[:0]
+	return nil
+}
+
+// Count returns the number of jobs written
+func (w *Writer) Count() int64 {
+	return w.count
+}
+
+// Close finishes the archive, syncs it to disk and moves it to its path
+func (w *Writer) Close() error {
+	if err := w.finish(); err != nil {
+		w.Abort()
+		return err
+	}
+	if err := w.file.Close(); err != nil {
+		os.Remove(w.file.Name())
+		return fmt.Errorf("failed to close archive: %w", err)
+	}
+	if err := os.Rename(w.file.Name(), w.path); err != nil {
+		os.Remove(w.file.Name())
+		return fmt.Errorf("failed to move archive into place: %w", err)
+	}
+	return nil
+}
+
+// finish flushes the format and buffers of an archive and syncs it
+func (w *Writer) finish() error {
+	if w.gzip != nil {
+		if err := w.gzip.Close(); err != nil {
+			return fmt.Errorf("failed to compress archive: %w", err)
+		}
+	}
+	if w.parquet != nil {
+		if len(w.pending) > 0 {
+			if err := w.flushParquet(); err != nil {
+				return err
+			}
+		}
+		if err := w.parquet.Close(); err != nil {
+			return fmt.Errorf("failed to write archive: %w", err)
+		}
+	}
+	if err := w.buf.Flush(); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := w.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync archive: %w", err)
+	}
+	return nil
+}
+
+// Abort discards the archive
+func (w *Writer) Abort() {
+	w.file.Close()
+	os.Remove(w.file.Name())
+}
+
+// Reader reads the jobs of an archive file
+type Reader struct {
+	file *os.File
+
+	// Set depending on the format
+	gzip    *gzip.Reader
+	json    *json.Decoder
+	parquet *parquet.GenericReader[Record]
+	batch   []Record
+	next    int
+}
+
+// Open opens an archive file, in the format of its extension
+func Open(path string) (*Reader, error) {
+	format, err := FormatOf(path)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+
+	r := &Reader{file: file}
+	switch format {
+	case NDJSON:
+		if r.gzip, err = gzip.NewReader(bufio.NewReader(file)); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to decompress archive: %w", err)
+		}
+		r.json = json.NewDecoder(r.gzip)
+	case Parquet:
+		r.parquet = parquet.NewGenericReader[Record](file)
+	}
+	return r, nil
+}
+
+// Read returns the next job of the archive, or io.EOF after the last
+func (r *Reader) Read() (*models.Job, error) {
+	if r.json != nil {
+		var record Record
+		if err := r.json.Decode(&record); err != nil {
+			if err == io.EOF {
+				return nil, io.EOF
+			}
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+		return record.Job(), nil
+	}
+
+	if r.next == len(r.batch) {
+		// A fresh batch, so records never share slices with earlier ones
+		r.batch = make([]Record, parquetBatch)
+		n, err := r.parquet.Read(r.batch)
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+		r.batch, r.next = r.batch[:n], 0
+		if n == 0 {
+			return nil, io.EOF
+		}
+	}
+
+	r.next++
+	return r.batch[r.next-1].Job(), nil
+}
+
+// Close closes the archive file
+func (r *Reader) Close() error {
+	if r.parquet != nil {
+		r.parquet.Close()
+	}
+	if r.gzip != nil {
+		r.gzip.Close()
+	}
+	return r.file.Close()
+}
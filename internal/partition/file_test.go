@@ -0,0 +1,112 @@
+package partition
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/abhisheksainimitawa/job-aggregator/internal/models"
+)
+
+func TestArchiveRoundTrip(t *testing.T) {
+	companyID, salaryMin, lat := int64(7), int64(120000), 37.77
+	posted := time.Date(2024, 3, 14, 9, 30, 0, 0, time.UTC)
+	closed := posted.AddDate(0, 1, 0)
+	jobs := []*models.Job{
+		{
+			ID: 1, Title: "Backend Engineer", Company: "Acme", CompanyID: &companyID,
+			Location: "San Francisco, CA", SalaryMin: &salaryMin, SalaryCurrency: "USD",
+			URL: "https://example.com/1", Source: "indeed", NativeID: "abc", Hash: "h1",
+			RemoteOk: true, PostedAt: posted, PostedAtPrecision: "exact",
+			ScrapedAt: posted, CreatedAt: posted, UpdatedAt: posted,
+			Status: models.JobStatusClosed, FirstSeenAt: posted, LastSeenAt: closed, ClosedAt: &closed,
+			Fingerprint: -42, Latitude: &lat, Tags: []string{"go", "postgresql"},
+			QualityScore: 77, QualityReasons: []models.QualityReason{{Factor: "salary", Points: 20}, {Factor: "spam", Points: -10, Detail: "repeated exclamation marks"}},
+			Flagged: true, FlagReasons: []string{`payment_request: "registration fee"`}, FlagReview: models.FlagReviewLegit,
+		},
+		{
+			ID: 2, Title: "Data Analyst", Company: "Globex", Source: "linkedin", Hash: "h2",
+			PostedAt: posted.Add(time.Hour), ScrapedAt: posted, CreatedAt: posted, UpdatedAt: posted,
+			Status: models.JobStatusOpen, FirstSeenAt: posted, LastSeenAt: posted,
+		},
+	}
+
+	for _, format := range []Format{NDJSON, Parquet} {
+		t.Run(string(format), func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "jobs_2024_03"+format.Ext())
+
+			w, err := Create(path)
+			if err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+			for _, job := range jobs {
+				if err := w.Write(job); err != nil {
+					t.Fatalf("Write() error = %v", err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+			if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+				t.Errorf("temporary file left behind")
+			}
+
+			r, err := Open(path)
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			defer r.Close()
+
+			for i, want := range jobs {
+				got, err := r.Read()
+				if err != nil {
+					t.Fatalf("Read() %d error = %v", i, err)
+				}
+				if len(got.Tags) == 0 {
+					got.Tags = nil
+				}
+				if len(got.QualityReasons) == 0 {
+					got.QualityReasons = nil
+				}
+				if len(got.FlagReasons) == 0 {
+					got.FlagReasons = nil
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("Read() %d = %+v, want %+v", i, got, want)
+				}
+			}
+			if _, err := r.Read(); err != io.EOF {
+				t.Errorf("Read() after last job error = %v, want io.EOF", err)
+			}
+		})
+	}
+}
+
+func TestWriterAbort(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs_2024_03.parquet")
+	w, err := Create(path)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	w.Abort()
+
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 0 {
+		t.Errorf("Abort() left %d files, want none", len(entries))
+	}
+}
+
+func TestFormatOf(t *testing.T) {
+	if f, err := FormatOf("archive/jobs_2024_03.ndjson.gz"); err != nil || f != NDJSON {
+		t.Errorf("FormatOf(ndjson) = %q, %v", f, err)
+	}
+	if f, err := FormatOf("jobs_2024_03.parquet"); err != nil || f != Parquet {
+		t.Errorf("FormatOf(parquet) = %q, %v", f, err)
+	}
+	if _, err := FormatOf("jobs_2024_03.csv"); err == nil {
+		t.Error("FormatOf(csv) error = nil, want error")
+	}
+}
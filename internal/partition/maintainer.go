@@ -0,0 +1,52 @@
+package partition
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/abhisheksainimitawa/job-aggregator/internal/repository"
+	"github.com/abhisheksainimitawa/job-aggregator/pkg/logger"
+)
+
+// Maintainer creates the partitions of coming months before jobs posted
+// in them arrive, so they do not pile up in the default partition
+type Maintainer struct {
+	repo        *repository.PartitionRepository
+	monthsAhead int
+}
+
+// NewMaintainer creates a maintainer keeping partitions for the current
+// month and monthsAhead months after it
+func NewMaintainer(repo *repository.PartitionRepository, monthsAhead int) *Maintainer {
+	if monthsAhead < 1 {
+		monthsAhead = 1
+	}
+	return &Maintainer{repo: repo, monthsAhead: monthsAhead}
+}
+
+// Ensure creates the missing partitions
+func (m *Maintainer) Ensure(ctx context.Context) error {
+	created, err := m.repo.EnsureMonths(ctx, time.Now(), m.monthsAhead)
+	if len(created) > 0 {
+		logger.Info("Created job partitions %s", strings.Join(created, ", "))
+	}
+	return err
+}
+
+// Run calls Ensure every interval until ctx is done
+func (m *Maintainer) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.Ensure(ctx); err != nil {
+				logger.Error("Failed to create job partitions: %v", err)
+			}
+		}
+	}
+}
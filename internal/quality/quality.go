@@ -0,0 +1,257 @@
+// Package quality scores how complete and trustworthy a scraped job is.
+// Points are awarded for filled fields, a substantial description, a
+// salary, an exact posting date and the job being listed on several
+// boards; spam signals cost points.
+package quality
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/abhisheksainimitawa/job-aggregator/internal/companies"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/models"
+	"github.com/abhisheksainimitawa/job-aggregator/pkg/dateparse"
+)
+
+// Score factors
+const (
+	FactorCompleteness = "completeness"
+	FactorDescription  = "description"
+	FactorSalary       = "salary"
+	FactorPostedAt     = "posted_at"
+	FactorSources      = "sources"
+	FactorSpam         = "spam"
+)
+
+// fieldPoints are awarded for each filled required field
+const fieldPoints = 6
+
+// descriptionPoints are awarded by description length in characters,
+// longest first
+var descriptionPoints = []struct {
+	length int
+	points int
+}{
+	{1000, 20},
+	{300, 12},
+	{100, 5},
+}
+
+// Salary points for a parsed range and for text that could not be parsed
+const (
+	parsedSalaryPoints = 20
+	salaryTextPoints   = 8
+)
+
+// precisionPoints are awarded by how exactly the posting date is known
+var precisionPoints = map[string]int{
+	string(dateparse.PrecisionExact):       15,
+	string(dateparse.PrecisionDay):         10,
+	string(dateparse.PrecisionApproximate): 5,
+}
+
+// Points for a job listed on two, and on three or more sources
+const (
+	twoSourcesPoints   = 10
+	manySourcesPoints  = 15
+	manySourcesMinimum = 3
+)
+
+// spamRule penalizes text matching its pattern
+type spamRule struct {
+	pattern *regexp.Regexp
+	points  int
+	detail  string
+}
+
+// spamRules look at the title and description of a job. Scam signals like
+// earnings promises are left to the scam detector, whose flag costs
+// flaggedPoints.
+var spamRules = []spamRule{
+	{regexp.MustCompile(`!{2,}`), -10, "repeated exclamation marks"},
+}
+
+// flaggedPoints are lost by jobs the scam detector flagged
+const flaggedPoints = -30
+
+// Shouting titles: at least this many letters, nearly all capitals
+const (
+	shoutingMinLetters = 10
+	shoutingShare      = 0.8
+	shoutingPoints     = -15
+)
+
+// Score rates a job from 0 to 100 and returns the factors behind the
+// score. sources is the number of boards the job was found on. Jobs are
+// checked for scams first, as a scam flag lowers the score.
+func Score(job *models.Job, sources int) (int, []models.QualityReason) {
+	reasons := []models.QualityReason{
+		completeness(job),
+		description(job),
+		salary(job),
+		postedAt(job),
+		listedOn(sources),
+	}
+	reasons = append(reasons, spam(job)...)
+
+	score := 0
+	for _, r := range reasons {
+		score += r.Points
+	}
+	if score < 0 {
+		score = 0
+	}
+	if score > models.MaxQualityScore {
+		score = models.MaxQualityScore
+	}
+	return score, reasons
+}
+
+// Apply scores a batch of jobs, each listed on the given number of
+// sources
+func Apply(jobs []*models.Job, sources []int) {
+	for i, job := range jobs {
+		job.QualityScore, job.QualityReasons = Score(job, sources[i])
+	}
+}
+
+// Sources returns for each job the number of distinct sources in the
+// batch listing a job with the same normalized title and company. Stores
+// that cluster jobs count the sources of the whole cluster instead.
+func Sources(jobs []*models.Job) []int {
+	bySource := make(map[string]map[string]bool)
+	keys := make([]string, len(jobs))
+	for i, job := range jobs {
+		keys[i] = listingKey(job)
+		if bySource[keys[i]] == nil {
+			bySource[keys[i]] = make(map[string]bool)
+		}
+		bySource[keys[i]][job.Source] = true
+	}
+
+	counts := make([]int, len(jobs))
+	for i, key := range keys {
+		counts[i] = len(bySource[key])
+	}
+	return counts
+}
+
+// listingKey identifies the same opening across boards
+func listingKey(job *models.Job) string {
+	title := job.NormalizedTitle
+	if title == "" {
+		title = strings.ToLower(strings.TrimSpace(job.Title))
+	}
+	return title + "\x00" + companies.Normalize(job.Company)
+}
+
+// completeness awards points for each required field that is filled
+func completeness(job *models.Job) models.QualityReason {
+	fields := []struct {
+		name  string
+		value string
+	}{
+		{"title", job.Title},
+		{"company", job.Company},
+		{"location", job.Location},
+		{"description", job.Description},
+		{"url", job.URL},
+	}
+
+	var missing []string
+	for _, f := range fields {
+		if strings.TrimSpace(f.value) == "" {
+			missing = append(missing, f.name)
+		}
+	}
+
+	r := models.QualityReason{Factor: FactorCompleteness, Points: fieldPoints * (len(fields) - len(missing))}
+	if len(missing) > 0 {
+		r.Detail = "missing " + strings.Join(missing, ", ")
+	}
+	return r
+}
+
+// description awards points for a substantial description
+func description(job *models.Job) models.QualityReason {
+	length := utf8.RuneCountInString(strings.TrimSpace(job.Description))
+	r := models.QualityReason{Factor: FactorDescription, Detail: fmt.Sprintf("%d characters", length)}
+	for _, p := range descriptionPoints {
+		if length >= p.length {
+			r.Points = p.points
+			break
+		}
+	}
+	return r
+}
+
+// salary awards points for a salary, more if it was parsed
+func salary(job *models.Job) models.QualityReason {
+	switch {
+	case job.SalaryMin != nil:
+		return models.QualityReason{Factor: FactorSalary, Points: parsedSalaryPoints}
+	case strings.TrimSpace(job.Salary) != "":
+		return models.QualityReason{Factor: FactorSalary, Points: salaryTextPoints, Detail: "could not be parsed"}
+	}
+	return models.QualityReason{Factor: FactorSalary, Detail: "missing"}
+}
+
+// postedAt awards points by the precision of the posting date
+func postedAt(job *models.Job) models.QualityReason {
+	precision := job.PostedAtPrecision
+	if precision == "" {
+		precision = string(dateparse.PrecisionUnknown)
+	}
+	return models.QualityReason{Factor: FactorPostedAt, Points: precisionPoints[precision], Detail: precision}
+}
+
+// listedOn awards points for a job listed on several sources
+func listedOn(sources int) models.QualityReason {
+	r := models.QualityReason{Factor: FactorSources, Detail: fmt.Sprintf("listed on %d sources", sources)}
+	switch {
+	case sources >= manySourcesMinimum:
+		r.Points = manySourcesPoints
+	case sources == 2:
+		r.Points = twoSourcesPoints
+	case sources == 1:
+		r.Detail = "listed on 1 source"
+	}
+	return r
+}
+
+// spam penalizes scam flags and signals of spam in the title and
+// description
+func spam(job *models.Job) []models.QualityReason {
+	var reasons []models.QualityReason
+	if job.Flagged {
+		reasons = append(reasons, models.QualityReason{Factor: FactorSpam, Points: flaggedPoints, Detail: "flagged as a possible scam"})
+	}
+	if shouting(job.Title) {
+		reasons = append(reasons, models.QualityReason{Factor: FactorSpam, Points: shoutingPoints, Detail: "title in capitals"})
+	}
+
+	text := job.Title + "\n" + job.Description
+	for _, rule := range spamRules {
+		if rule.pattern.MatchString(text) {
+			reasons = append(reasons, models.QualityReason{Factor: FactorSpam, Points: rule.points, Detail: rule.detail})
+		}
+	}
+	return reasons
+}
+
+// shouting reports whether a title is written in capitals
+func shouting(title string) bool {
+	letters, upper := 0, 0
+	for _, r := range title {
+		if unicode.IsLetter(r) {
+			letters++
+			if unicode.IsUpper(r) {
+				upper++
+			}
+		}
+	}
+	return letters >= shoutingMinLetters && float64(upper) >= shoutingShare*float64(letters)
+}
@@ -0,0 +1,87 @@
+package quality
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/abhisheksainimitawa/job-aggregator/internal/models"
+)
+
+func TestScore(t *testing.T) {
+	salaryMin := int64(90000)
+	complete := models.Job{
+		Title: "Senior Go Developer", Company: "Acme", Location: "Berlin", URL: "https://example.com/1",
+		Description: strings.Repeat("Build distributed systems in Go. ", 40), PostedAtPrecision: "exact",
+		Salary: "€90,000 - €120,000", SalaryMin: &salaryMin,
+	}
+
+	tests := []struct {
+		name    string
+		job     func(job *models.Job)
+		sources int
+		want    int
+	}{
+		{"complete on three sources", func(job *models.Job) {}, 3, 100},
+		{"complete on two sources", func(job *models.Job) {}, 2, 95},
+		{"complete on one source", func(job *models.Job) {}, 1, 85},
+		{"unparsed salary", func(job *models.Job) { job.SalaryMin = nil }, 1, 73},
+		{"approximate date", func(job *models.Job) { job.PostedAtPrecision = "approximate" }, 1, 75},
+		{"short description", func(job *models.Job) { job.Description = strings.Repeat("x", 150) }, 1, 70},
+		{"missing location", func(job *models.Job) { job.Location = "" }, 1, 79},
+		{"shouting title", func(job *models.Job) { job.Title = "URGENT HIRING NOW" }, 1, 70},
+		{"exclamation marks", func(job *models.Job) { job.Description += " Apply today!!" }, 1, 75},
+		{"flagged as a scam", func(job *models.Job) { job.Flagged = true }, 1, 55},
+		{"bare listing", func(job *models.Job) {
+			*job = models.Job{Title: "EARN $500 PER DAY!!!", Company: "x", URL: "u"}
+		}, 1, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := complete
+			tt.job(&job)
+			got, reasons := Score(&job, tt.sources)
+			if got != tt.want {
+				t.Errorf("Score = %d, want %d (reasons %+v)", got, tt.want, reasons)
+			}
+		})
+	}
+}
+
+func TestScoreReasons(t *testing.T) {
+	job := &models.Job{Title: "Go Developer", Company: "Acme", URL: "u", Salary: "competitive"}
+	_, reasons := Score(job, 1)
+
+	want := map[string]string{
+		FactorCompleteness: "missing location, description",
+		FactorDescription:  "0 characters",
+		FactorSalary:       "could not be parsed",
+		FactorPostedAt:     "unknown",
+		FactorSources:      "listed on 1 source",
+	}
+	if len(reasons) != len(want) {
+		t.Fatalf("got %d reasons, want %d: %+v", len(reasons), len(want), reasons)
+	}
+	for _, r := range reasons {
+		if r.Detail != want[r.Factor] {
+			t.Errorf("%s detail = %q, want %q", r.Factor, r.Detail, want[r.Factor])
+		}
+	}
+}
+
+func TestSources(t *testing.T) {
+	jobs := []*models.Job{
+		{Title: "Go Developer", Company: "Acme GmbH", Source: "indeed"},
+		{Title: "Go Developer", Company: "Acme", Source: "linkedin"},
+		{Title: "Go Developer", Company: "Acme", Source: "linkedin"},
+		{Title: "Go Developer", Company: "Beta", Source: "indeed"},
+	}
+
+	got := Sources(jobs)
+	want := []int{2, 2, 2, 1}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Sources()[%d] = %d, want %d", i, got[i], want[i])
+		}
+	}
+}
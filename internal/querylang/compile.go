@@ -0,0 +1,128 @@
+package querylang
+
+import (
+	"fmt"
+	"strings"
+)
+
+// flipped reverses comparisons for ages: posted<7d means newer than 7
+// days, i.e. posted_at > now-7d
+var flipped = map[string]string{">": "<", ">=": "<=", "<": ">", "<=": ">="}
+
+// Compile turns a parsed query into a parameterized SQL condition on the
+// jobs table. Parameters are numbered from argStart; the returned args
+// fill them in order.
+func Compile(n Node, argStart int) (string, []interface{}) {
+	c := &compiler{argPos: argStart}
+	return c.compile(n), c.args
+}
+
+type compiler struct {
+	argPos int
+	args   []interface{}
+}
+
+// arg adds a parameter and returns its placeholder
+func (c *compiler) arg(v interface{}) string {
+	c.args = append(c.args, v)
+	c.argPos++
+	return fmt.Sprintf("$%d", c.argPos-1)
+}
+
+func (c *compiler) compile(n Node) string {
+	switch n := n.(type) {
+	case *And:
+		return "(" + c.compile(n.Left) + " AND " + c.compile(n.Right) + ")"
+	case *Or:
+		return "(" + c.compile(n.Left) + " OR " + c.compile(n.Right) + ")"
+	case *Not:
+		return "NOT " + c.compile(n.X)
+	case *Term:
+		return c.term(n)
+	}
+	return "TRUE"
+}
+
+func (c *compiler) term(t *Term) string {
+	if t.Field == "" {
+		fn := "plainto_tsquery"
+		if t.Quoted {
+			fn = "phraseto_tsquery"
+		}
+		return fmt.Sprintf("jobs.search_vector @@ %s('english', %s)", fn, c.arg(t.Value))
+	}
+
+	f := fields[t.Field]
+	switch f.kind {
+	case kindText:
+		return fmt.Sprintf("jobs.%s ILIKE %s", f.column, c.arg("%"+escapeLike(t.Value)+"%"))
+
+	case kindKeyword:
+		return fmt.Sprintf("LOWER(jobs.%s) = LOWER(%s)", f.column, c.arg(t.Value))
+
+	case kindSource:
+		return fmt.Sprintf(`EXISTS (SELECT 1 FROM jobs ql WHERE (ql.id = jobs.id OR ql.cluster_id = jobs.id) AND LOWER(ql.source) = LOWER(%s))`, c.arg(t.Value))
+
+	case kindTag:
+		return fmt.Sprintf("EXISTS (SELECT 1 FROM job_tags qt WHERE qt.job_id = jobs.id AND qt.tag = %s)", c.arg(strings.ToLower(t.Value)))
+
+	case kindBool:
+		return fmt.Sprintf("jobs.%s = %s", f.column, c.arg(t.parsed))
+
+	case kindStatus:
+		return fmt.Sprintf("jobs.status = %s", c.arg(strings.ToLower(t.Value)))
+
+	case kindNumber:
+		// A salary range matches ":" when it contains the amount; lower
+		// bounds compare with its maximum and upper bounds with its minimum
+		switch t.Op {
+		case ">", ">=":
+			return fmt.Sprintf("jobs.salary_max %s %s", t.Op, c.arg(t.parsed))
+		case "<", "<=":
+			return fmt.Sprintf("jobs.salary_min %s %s", t.Op, c.arg(t.parsed))
+		}
+		v := c.arg(t.parsed)
+		return fmt.Sprintf("(jobs.salary_min <= %s AND jobs.salary_max >= %s)", v, v)
+
+	case kindDate:
+		return c.date(f.column, t.Op, t.parsed.(dateValue))
+	}
+	return "TRUE"
+}
+
+// date compiles a date comparison. A calendar day covers all of it, so
+// posted:2026-01-31 matches the whole day and posted>2026-01-31 starts
+// the day after.
+func (c *compiler) date(column, op string, d dateValue) string {
+	col := "jobs." + column
+
+	if !d.day {
+		switch {
+		case op == ":" || op == "=":
+			// posted:7d means within the last 7 days, and a time means
+			// at or after it
+			op = ">="
+		case d.age:
+			op = flipped[op]
+		}
+		return fmt.Sprintf("%s %s %s", col, op, c.arg(d.time))
+	}
+
+	start, end := d.time, d.time.AddDate(0, 0, 1)
+	switch op {
+	case ">":
+		return fmt.Sprintf("%s >= %s", col, c.arg(end))
+	case ">=":
+		return fmt.Sprintf("%s >= %s", col, c.arg(start))
+	case "<":
+		return fmt.Sprintf("%s < %s", col, c.arg(start))
+	case "<=":
+		return fmt.Sprintf("%s < %s", col, c.arg(end))
+	}
+	return fmt.Sprintf("(%s >= %s AND %s < %s)", col, c.arg(start), col, c.arg(end))
+}
+
+// escapeLike escapes the LIKE wildcards in a literal
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
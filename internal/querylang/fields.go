@@ -0,0 +1,181 @@
+package querylang
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// fieldKind determines the operators, values and SQL of a field
+type fieldKind int
+
+const (
+	kindText    fieldKind = iota // substring match
+	kindKeyword                  // case-insensitive equality
+	kindSource                   // equality on any listing of the cluster
+	kindTag                      // skill tag
+	kindBool
+	kindNumber
+	kindDate
+	kindStatus
+)
+
+type field struct {
+	name   string
+	column string
+	kind   fieldKind
+}
+
+// fields are the queryable fields keyed by name
+var fields = map[string]field{
+	"title":       {"title", "title", kindText},
+	"company":     {"company", "company", kindText},
+	"location":    {"location", "location", kindText},
+	"description": {"description", "description", kindText},
+	"source":      {"source", "source", kindSource},
+	"type":        {"type", "job_type", kindKeyword},
+	"seniority":   {"seniority", "seniority", kindKeyword},
+	"role":        {"role", "role_family", kindKeyword},
+	"currency":    {"currency", "salary_currency", kindKeyword},
+	"skill":       {"skill", "", kindTag},
+	"remote":      {"remote", "remote_ok", kindBool},
+	"salary":      {"salary", "", kindNumber},
+	"posted":      {"posted", "posted_at", kindDate},
+	"scraped":     {"scraped", "scraped_at", kindDate},
+	"updated":     {"updated", "updated_at", kindDate},
+	"status":      {"status", "status", kindStatus},
+}
+
+// aliases map alternative spellings to field names
+var aliases = map[string]string{
+	"job_type":    "type",
+	"role_family": "role",
+	"skills":      "skill",
+	"tag":         "skill",
+	"remote_ok":   "remote",
+	"posted_at":   "posted",
+}
+
+func lookupField(name string) (field, bool) {
+	name = strings.ToLower(name)
+	if alias, ok := aliases[name]; ok {
+		name = alias
+	}
+	f, ok := fields[name]
+	return f, ok
+}
+
+func fieldNames() []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var (
+	numberRe   = regexp.MustCompile(`^(\d+(?:\.\d+)?)([km]?)$`)
+	relativeRe = regexp.MustCompile(`^(\d+)([hdwm])$`)
+)
+
+// validate checks a term's operator and value against its field and
+// stores the typed value
+func validate(t *Term, now time.Time) error {
+	if t.Field == "" {
+		if t.Op != ":" {
+			return errorf(t.Pos, "comparison needs a field")
+		}
+		return nil
+	}
+
+	f := fields[t.Field]
+	ordered := f.kind == kindNumber || f.kind == kindDate
+	if t.Op != ":" && t.Op != "=" && !ordered {
+		return errorf(t.Pos, "%s does not support %s", t.Field, t.Op)
+	}
+
+	value := strings.ToLower(t.Value)
+	switch f.kind {
+	case kindBool:
+		switch value {
+		case "true", "yes":
+			t.parsed = true
+		case "false", "no":
+			t.parsed = false
+		default:
+			return errorf(t.Pos, "%s expects true or false, got %q", t.Field, t.Value)
+		}
+
+	case kindNumber:
+		m := numberRe.FindStringSubmatch(value)
+		if m == nil {
+			return errorf(t.Pos, "%s expects a number like 150000 or 150k, got %q", t.Field, t.Value)
+		}
+		n, _ := strconv.ParseFloat(m[1], 64)
+		switch m[2] {
+		case "k":
+			n *= 1000
+		case "m":
+			n *= 1000000
+		}
+		t.parsed = int64(n)
+
+	case kindDate:
+		d, err := parseDate(value, now)
+		if err != nil {
+			return errorf(t.Pos, "%s expects a date like 2026-01-31 or an age like 7d, got %q", t.Field, t.Value)
+		}
+		t.parsed = d
+
+	case kindStatus:
+		if value != "open" && value != "closed" {
+			return errorf(t.Pos, "status expects open or closed, got %q", t.Value)
+		}
+
+	default:
+		if strings.TrimSpace(t.Value) == "" {
+			return errorf(t.Pos, "empty value for %s", t.Field)
+		}
+	}
+	return nil
+}
+
+// dateValue is a parsed date: a calendar day, an instant, or an age
+// measured back from now
+type dateValue struct {
+	time time.Time
+	day  bool
+	age  bool
+}
+
+// parseDate parses YYYY-MM-DD, an RFC 3339 time, or an age like 7d (hours,
+// days, weeks or months before now)
+func parseDate(value string, now time.Time) (dateValue, error) {
+	if m := relativeRe.FindStringSubmatch(value); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		var t time.Time
+		switch m[2] {
+		case "h":
+			t = now.Add(-time.Duration(n) * time.Hour)
+		case "d":
+			t = now.AddDate(0, 0, -n)
+		case "w":
+			t = now.AddDate(0, 0, -7*n)
+		case "m":
+			t = now.AddDate(0, -n, 0)
+		}
+		return dateValue{time: t, age: true}, nil
+	}
+
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return dateValue{time: t, day: true}, nil
+	}
+	t, err := time.Parse(time.RFC3339, strings.ToUpper(value))
+	if err != nil {
+		return dateValue{}, err
+	}
+	return dateValue{time: t}, nil
+}
@@ -0,0 +1,183 @@
+package querylang
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// tokenKind identifies a lexical token
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokWord
+	tokString
+	tokAnd
+	tokOr
+	tokNot
+	tokLParen
+	tokRParen
+	tokColon
+	tokCompare
+)
+
+func (k tokenKind) String() string {
+	switch k {
+	case tokEOF:
+		return "end of query"
+	case tokWord:
+		return "word"
+	case tokString:
+		return "quoted string"
+	case tokAnd:
+		return "AND"
+	case tokOr:
+		return "OR"
+	case tokNot:
+		return "NOT"
+	case tokLParen:
+		return "'('"
+	case tokRParen:
+		return "')'"
+	case tokColon:
+		return "':'"
+	case tokCompare:
+		return "comparison"
+	}
+	return "token"
+}
+
+// token is a lexical token; Pos is its byte offset in the query
+type token struct {
+	kind  tokenKind
+	text  string
+	pos   int
+	glued bool // no whitespace before the token
+}
+
+// Error is a syntax or value error at a byte offset of the query
+type Error struct {
+	Pos int
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s at position %d", e.Msg, e.Pos+1)
+}
+
+// Caret returns the query with a marker under the offending position
+func (e *Error) Caret(query string) string {
+	pos := e.Pos
+	if pos > len(query) {
+		pos = len(query)
+	}
+	return query + "\n" + strings.Repeat(" ", len([]rune(query[:pos]))) + "^"
+}
+
+func errorf(pos int, format string, args ...interface{}) *Error {
+	return &Error{Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// isWordRune reports whether r can be part of an unquoted word
+func isWordRune(r rune) bool {
+	return !unicode.IsSpace(r) && !strings.ContainsRune(`():"<>=`, r)
+}
+
+// lex splits a query into tokens. A leading "-" directly before a word,
+// string or parenthesis negates it like NOT.
+func lex(query string) ([]token, error) {
+	var tokens []token
+	runes := []rune(query)
+	offsets := make([]int, len(runes)+1)
+	for i, off := 0, 0; i < len(runes); i++ {
+		offsets[i] = off
+		off += len(string(runes[i]))
+		offsets[i+1] = off
+	}
+
+	glued := false
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		start := offsets[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			glued = false
+			continue
+
+		case r == '(':
+			tokens = append(tokens, token{tokLParen, "(", start, glued})
+			i++
+
+		case r == ')':
+			tokens = append(tokens, token{tokRParen, ")", start, glued})
+			i++
+
+		case r == ':':
+			tokens = append(tokens, token{tokColon, ":", start, glued})
+			i++
+
+		case r == '<' || r == '>' || r == '=':
+			op := string(r)
+			i++
+			if r != '=' && i < len(runes) && runes[i] == '=' {
+				op += "="
+				i++
+			}
+			tokens = append(tokens, token{tokCompare, op, start, glued})
+
+		case r == '"':
+			var sb strings.Builder
+			i++
+			closed := false
+			for i < len(runes) {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					sb.WriteRune(runes[i+1])
+					i += 2
+					continue
+				}
+				if runes[i] == '"' {
+					closed = true
+					i++
+					break
+				}
+				sb.WriteRune(runes[i])
+				i++
+			}
+			if !closed {
+				return nil, errorf(start, "unterminated quoted string")
+			}
+			tokens = append(tokens, token{tokString, sb.String(), start, glued})
+
+		case r == '-' && !glued && i+1 < len(runes) && (isWordRune(runes[i+1]) || runes[i+1] == '"' || runes[i+1] == '('):
+			tokens = append(tokens, token{tokNot, "-", start, glued})
+			i++
+			glued = true
+			continue
+
+		default:
+			j := i
+			for j < len(runes) && isWordRune(runes[j]) {
+				j++
+			}
+			text := string(runes[i:j])
+			kind := tokWord
+			switch text {
+			case "AND":
+				kind = tokAnd
+			case "OR":
+				kind = tokOr
+			case "NOT":
+				kind = tokNot
+			}
+			tokens = append(tokens, token{kind, text, start, glued})
+			i = j
+		}
+		glued = true
+	}
+
+	tokens = append(tokens, token{tokEOF, "", len(query), false})
+	return tokens, nil
+}
@@ -0,0 +1,253 @@
+package querylang
+
+import (
+	"strings"
+	"time"
+)
+
+// Node is a node of a parsed query: *And, *Or, *Not or *Term
+type Node interface {
+	node()
+}
+
+// And matches jobs matching both sides
+type And struct {
+	Left, Right Node
+}
+
+// Or matches jobs matching either side
+type Or struct {
+	Left, Right Node
+}
+
+// Not matches jobs not matching X
+type Not struct {
+	X Node
+}
+
+// Term is a single condition. Field is "" for full-text terms.
+type Term struct {
+	Field  string
+	Op     string // ":", "=", ">", ">=", "<" or "<="
+	Value  string
+	Quoted bool
+	Pos    int // byte offset of the value in the query
+
+	parsed interface{} // typed value for number, bool and date fields
+}
+
+func (*And) node()  {}
+func (*Or) node()   {}
+func (*Not) node()  {}
+func (*Term) node() {}
+
+// Walk calls fn for every term of the query, in order
+func Walk(n Node, fn func(*Term)) {
+	switch n := n.(type) {
+	case *And:
+		Walk(n.Left, fn)
+		Walk(n.Right, fn)
+	case *Or:
+		Walk(n.Left, fn)
+		Walk(n.Right, fn)
+	case *Not:
+		Walk(n.X, fn)
+	case *Term:
+		fn(n)
+	}
+}
+
+// HasField reports whether any term of the query matches field
+func HasField(n Node, field string) bool {
+	found := false
+	Walk(n, func(t *Term) {
+		found = found || t.Field == field
+	})
+	return found
+}
+
+// Parse parses a query such as
+//
+//	title:(go OR golang) AND NOT company:"Acme" AND salary>=150k AND remote:true
+//
+// Terms next to each other are combined with AND; AND binds tighter than
+// OR. Errors are *Error values carrying the offending position.
+func Parse(query string) (Node, error) {
+	return parseAt(query, time.Now())
+}
+
+func parseAt(query string, now time.Time) (Node, error) {
+	tokens, err := lex(query)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens, now: now}
+	if p.peek().kind == tokEOF {
+		return nil, errorf(0, "empty query")
+	}
+
+	n, err := p.parseOr("")
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		if t.kind == tokRParen {
+			return nil, errorf(t.pos, "unmatched ')'")
+		}
+		return nil, errorf(t.pos, "unexpected %s", describe(t))
+	}
+	return n, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	now    time.Time
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// parseOr parses OR-separated terms. field is the field of an enclosing
+// group like title:(...), "" at the top level.
+func (p *parser) parseOr(field string) (Node, error) {
+	left, err := p.parseAnd(field)
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokOr {
+		p.next()
+		right, err := p.parseAnd(field)
+		if err != nil {
+			return nil, err
+		}
+		left = &Or{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd(field string) (Node, error) {
+	left, err := p.parseUnary(field)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch p.peek().kind {
+		case tokAnd:
+			p.next()
+		case tokWord, tokString, tokNot, tokLParen:
+			// Implicit AND
+		default:
+			return left, nil
+		}
+		right, err := p.parseUnary(field)
+		if err != nil {
+			return nil, err
+		}
+		left = &And{Left: left, Right: right}
+	}
+}
+
+func (p *parser) parseUnary(field string) (Node, error) {
+	if p.peek().kind == tokNot {
+		p.next()
+		x, err := p.parseUnary(field)
+		if err != nil {
+			return nil, err
+		}
+		return &Not{X: x}, nil
+	}
+	return p.parsePrimary(field)
+}
+
+func (p *parser) parsePrimary(field string) (Node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokLParen:
+		return p.parseGroup(t, field)
+
+	case tokWord:
+		if k := p.peek().kind; k == tokColon || k == tokCompare {
+			return p.parseField(t, field)
+		}
+		return p.term(field, ":", t)
+
+	case tokString:
+		return p.term(field, ":", t)
+
+	case tokEOF:
+		return nil, errorf(t.pos, "unexpected end of query, expected a term")
+	}
+	return nil, errorf(t.pos, "unexpected %s", describe(t))
+}
+
+// parseGroup parses a parenthesized expression after its "("
+func (p *parser) parseGroup(open token, field string) (Node, error) {
+	n, err := p.parseOr(field)
+	if err != nil {
+		return nil, err
+	}
+	if t := p.next(); t.kind != tokRParen {
+		if t.kind == tokEOF {
+			return nil, errorf(open.pos, "unclosed '('")
+		}
+		return nil, errorf(t.pos, "expected ')', got %s", describe(t))
+	}
+	return n, nil
+}
+
+// parseField parses "name:value", "name:(...)" or "name>=value" after name
+func (p *parser) parseField(name token, outer string) (Node, error) {
+	if outer != "" {
+		return nil, errorf(name.pos, "field %q inside %s:(...) group", name.text, outer)
+	}
+
+	f, ok := lookupField(name.text)
+	if !ok {
+		return nil, errorf(name.pos, "unknown field %q, expected one of %s", name.text, strings.Join(fieldNames(), ", "))
+	}
+
+	op := p.next()
+	value := p.next()
+	switch value.kind {
+	case tokWord, tokString:
+		return p.term(f.name, op.text, value)
+	case tokLParen:
+		if op.kind != tokColon {
+			return nil, errorf(value.pos, "%s cannot be followed by a group", op.text)
+		}
+		return p.parseGroup(value, f.name)
+	case tokEOF:
+		return nil, errorf(value.pos, "missing value for %s", name.text)
+	}
+	return nil, errorf(value.pos, "expected a value for %s, got %s", name.text, describe(value))
+}
+
+// term builds and validates a term from a value token
+func (p *parser) term(field, op string, value token) (Node, error) {
+	t := &Term{Field: field, Op: op, Value: value.text, Quoted: value.kind == tokString, Pos: value.pos}
+	if err := validate(t, p.now); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func describe(t token) string {
+	switch t.kind {
+	case tokWord, tokCompare:
+		return "'" + t.text + "'"
+	case tokString:
+		return "\"" + t.text + "\""
+	}
+	return t.kind.String()
+}
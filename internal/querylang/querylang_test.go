@@ -0,0 +1,149 @@
+package querylang
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var now = time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+func compileQuery(t *testing.T, query string) (string, []interface{}) {
+	t.Helper()
+	n, err := parseAt(query, now)
+	if err != nil {
+		t.Fatalf("Parse(%q) error = %v", query, err)
+	}
+	return Compile(n, 1)
+}
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		query string
+		sql   string
+		args  []interface{}
+	}{
+		{
+			`title:(go OR golang) AND NOT company:"Acme" AND salary>=150k AND remote:true`,
+			`((((jobs.title ILIKE $1 OR jobs.title ILIKE $2) AND NOT jobs.company ILIKE $3) AND jobs.salary_max >= $4) AND jobs.remote_ok = $5)`,
+			[]interface{}{"%go%", "%golang%", "%Acme%", int64(150000), true},
+		},
+		{
+			`kubernetes "site reliability" -manager`,
+			`((jobs.search_vector @@ plainto_tsquery('english', $1) AND jobs.search_vector @@ phraseto_tsquery('english', $2)) AND NOT jobs.search_vector @@ plainto_tsquery('english', $3))`,
+			[]interface{}{"kubernetes", "site reliability", "manager"},
+		},
+		{
+			`a OR b c`,
+			`(jobs.search_vector @@ plainto_tsquery('english', $1) OR (jobs.search_vector @@ plainto_tsquery('english', $2) AND jobs.search_vector @@ plainto_tsquery('english', $3)))`,
+			[]interface{}{"a", "b", "c"},
+		},
+		{
+			`salary:120k`,
+			`(jobs.salary_min <= $1 AND jobs.salary_max >= $1)`,
+			[]interface{}{int64(120000)},
+		},
+		{
+			`posted:2026-03-01`,
+			`(jobs.posted_at >= $1 AND jobs.posted_at < $2)`,
+			[]interface{}{time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			`posted<7d`,
+			`jobs.posted_at > $1`,
+			[]interface{}{now.AddDate(0, 0, -7)},
+		},
+		{
+			`source:indeed skill:Go title:100%_off`,
+			`((EXISTS (SELECT 1 FROM jobs ql WHERE (ql.id = jobs.id OR ql.cluster_id = jobs.id) AND LOWER(ql.source) = LOWER($1)) AND EXISTS (SELECT 1 FROM job_tags qt WHERE qt.job_id = jobs.id AND qt.tag = $2)) AND jobs.title ILIKE $3)`,
+			[]interface{}{"indeed", "go", `%100\%\_off%`},
+		},
+		{
+			`job_type:contract`,
+			`LOWER(jobs.job_type) = LOWER($1)`,
+			[]interface{}{"contract"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			sql, args := compileQuery(t, tt.query)
+			if sql != tt.sql {
+				t.Errorf("SQL =\n%s\nwant\n%s", sql, tt.sql)
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("args = %#v, want %#v", args, tt.args)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		pos   int
+	}{
+		{``, 0},
+		{`title:(go OR golang`, 6},
+		{`title:go)`, 8},
+		{`salary>=lots`, 8},
+		{`colour:red`, 0},
+		{`remote:maybe`, 7},
+		{`go AND`, 6},
+		{`company:"Acme`, 8},
+		{`title>go`, 6},
+		{`title:(company:x)`, 7},
+		{`status:pending`, 7},
+		{`posted>=yesterday`, 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := Parse(tt.query)
+			var perr *Error
+			if !errors.As(err, &perr) {
+				t.Fatalf("Parse() error = %v, want *Error", err)
+			}
+			if perr.Pos != tt.pos {
+				t.Errorf("Pos = %d, want %d (%v)", perr.Pos, tt.pos, err)
+			}
+		})
+	}
+}
+
+func TestErrorCaret(t *testing.T) {
+	err := &Error{Pos: 8, Msg: "bad value"}
+	want := "salary>=lots\n        ^"
+	if got := err.Caret("salary>=lots"); got != want {
+		t.Errorf("Caret() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWalk(t *testing.T) {
+	n, err := Parse(`skill:golang OR (skill:k8s -title:intern)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var values []string
+	Walk(n, func(term *Term) { values = append(values, term.Field+"="+term.Value) })
+
+	want := []string{"skill=golang", "skill=k8s", "title=intern"}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("Walk() visited %v, want %v", values, want)
+	}
+}
+
+func TestHasField(t *testing.T) {
+	n, err := Parse(`golang OR (NOT status:closed remote:true)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for field, want := range map[string]bool{"status": true, "remote": true, "": true, "title": false} {
+		if got := HasField(n, field); got != want {
+			t.Errorf("HasField(%q) = %v, want %v", field, got, want)
+		}
+	}
+}
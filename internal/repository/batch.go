@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/abhisheksainimitawa/job-aggregator/internal/models"
+)
+
+// hashTakenReason rejects a new job whose native ID is unknown but whose
+// hash already belongs to a stored job of another source or with another
+// native ID
+const hashTakenReason = "hash already belongs to job %d"
+
+// detachedReason rejects a job matching a stored job whose partition is
+// detached for archiving, which cannot be updated until it is restored
+const detachedReason = "matches job %d in a detached partition"
+
+// textField is a text column checked before a job is stored. max is the
+// length limit of its VARCHAR column in characters, 0 for TEXT.
+type textField struct {
+	name     string
+	max      int
+	required bool
+	value    func(job *models.Job) string
+}
+
+// textFields mirror the jobs table of the migrations
+var textFields = []textField{
+	{"title", 255, true, func(j *models.Job) string { return j.Title }},
+	{"company", 255, true, func(j *models.Job) string { return j.Company }},
+	{"location", 255, false, func(j *models.Job) string { return j.Location }},
+	{"salary", 100, false, func(j *models.Job) string { return j.Salary }},
+	{"description", 0, false, func(j *models.Job) string { return j.Description }},
+	{"url", 0, true, func(j *models.Job) string { return j.URL }},
+	{"source", 50, true, func(j *models.Job) string { return j.Source }},
+	{"job_type", 50, false, func(j *models.Job) string { return j.JobType }},
+	{"hash", 64, true, func(j *models.Job) string { return j.Hash }},
+	{"normalized_title", 255, false, func(j *models.Job) string { return j.NormalizedTitle }},
+	{"seniority", 20, false, func(j *models.Job) string { return j.Seniority }},
+	{"role_family", 30, false, func(j *models.Job) string { return j.RoleFamily }},
+	{"description_html", 0, false, func(j *models.Job) string { return j.DescriptionHTML }},
+	{"description_markdown", 0, false, func(j *models.Job) string { return j.DescriptionMarkdown }},
+	{"posted_at_precision", 16, false, func(j *models.Job) string { return j.PostedAtPrecision }},
+	{"canonical_url", 0, false, func(j *models.Job) string { return j.CanonicalURL }},
+	{"native_id", 128, false, func(j *models.Job) string { return j.NativeID }},
+	{"salary_currency", 3, false, func(j *models.Job) string { return j.SalaryCurrency }},
+}
+
+// maxTagLength is the length limit of job_tags.tag
+const maxTagLength = 64
+
+// rejectReason returns why a job cannot be stored, "" if it can. Checking
+// up front keeps one bad row from failing a whole COPY.
+func rejectReason(job *models.Job) string {
+	for _, f := range textFields {
+		v := f.value(job)
+		switch {
+		case f.required && strings.TrimSpace(v) == "":
+			return "missing " + f.name
+		case !utf8.ValidString(v):
+			return f.name + " is not valid UTF-8"
+		case strings.ContainsRune(v, 0):
+			return f.name + " contains a NUL byte"
+		case f.max > 0 && utf8.RuneCountInString(v) > f.max:
+			return fmt.Sprintf("%s longer than %d characters", f.name, f.max)
+		}
+	}
+
+	for _, tag := range job.Tags {
+		if !utf8.ValidString(tag) || strings.ContainsRune(tag, 0) || utf8.RuneCountInString(tag) > maxTagLength {
+			return fmt.Sprintf("invalid tag %q", tag)
+		}
+	}
+	return ""
+}
+
+// screenBatch validates a batch and returns the indexes of the jobs to
+// store, in order, with the rejections of the others. When several jobs
+// share a hash or native ID only the last is kept, since one statement
+// cannot update the same row twice and the last is the freshest.
+func screenBatch(jobs []*models.Job) (accepted []int, rejected []models.BatchRejection) {
+	byHash := make(map[string]int)
+	byNativeID := make(map[string]int)
+
+	for i := len(jobs) - 1; i >= 0; i-- {
+		job := jobs[i]
+		job.ID = 0
+
+		reason := rejectReason(job)
+		if reason == "" {
+			later, dup := byHash[job.Hash]
+			if key := nativeKey(job); key != "" && !dup {
+				later, dup = byNativeID[key]
+			}
+			if dup {
+				reason = fmt.Sprintf("duplicate of row %d in the same batch", later)
+			}
+		}
+		if reason != "" {
+			rejected = append(rejected, models.BatchRejection{Row: i, Hash: job.Hash, Reason: reason})
+			continue
+		}
+
+		byHash[job.Hash] = i
+		if key := nativeKey(job); key != "" {
+			byNativeID[key] = i
+		}
+		accepted = append(accepted, i)
+	}
+
+	sort.Ints(accepted)
+	sortRejections(rejected)
+	return accepted, rejected
+}
+
+// sortRejections orders rejections by batch row
+func sortRejections(rejected []models.BatchRejection) {
+	sort.Slice(rejected, func(a, b int) bool { return rejected[a].Row < rejected[b].Row })
+}
+
+// nativeKey identifies a job by its board-native ID, "" if it has none
+func nativeKey(job *models.Job) string {
+	if job.NativeID == "" {
+		return ""
+	}
+	return job.Source + "\x00" + job.NativeID
+}
@@ -0,0 +1,233 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/abhisheksainimitawa/job-aggregator/internal/models"
+	"github.com/abhisheksainimitawa/job-aggregator/pkg/logger"
+	"github.com/lib/pq"
+)
+
+// companyColumns is the column list selected for every company read
+const companyColumns = `
+	c.id, c.name, COALESCE(c.domain, ''), COALESCE(c.metadata::text, ''), c.created_at, c.updated_at,
+	ARRAY(SELECT name FROM company_aliases a WHERE a.company_id = c.id ORDER BY name),
+	(SELECT COUNT(*) FROM jobs j WHERE j.company_id = c.id) AS job_count
+`
+
+// CompanyRepository handles database operations for companies
+type CompanyRepository struct {
+	db *sql.DB
+}
+
+// NewCompanyRepository creates a new company repository
+func NewCompanyRepository(db *sql.DB) *CompanyRepository {
+	return &CompanyRepository{db: db}
+}
+
+// FindIDByAlias looks up the company registered under a normalized alias
+func (r *CompanyRepository) FindIDByAlias(ctx context.Context, alias string) (int64, bool, error) {
+	var id int64
+	err := r.db.QueryRowContext(ctx,
+		"SELECT company_id FROM company_aliases WHERE alias = $1",
+		alias,
+	).Scan(&id)
+
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to look up company alias: %w", err)
+	}
+
+	return id, true, nil
+}
+
+// FindOrCreate returns the ID of the company with the given normalized
+// name, creating it with the given display name if it does not exist
+func (r *CompanyRepository) FindOrCreate(ctx context.Context, name, normalized string) (int64, error) {
+	var id int64
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO companies (name, normalized_name, created_at, updated_at)
+		VALUES ($1, $2, NOW(), NOW())
+		ON CONFLICT (normalized_name) DO UPDATE SET normalized_name = EXCLUDED.normalized_name
+		RETURNING id
+	`, name, normalized).Scan(&id)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to create company: %w", err)
+	}
+
+	return id, nil
+}
+
+// Upsert creates or updates a company from the alias list, keyed by its
+// normalized name
+func (r *CompanyRepository) Upsert(ctx context.Context, company *models.Company, normalized string) error {
+	var metadata interface{}
+	if len(company.Metadata) > 0 {
+		metadata = string(company.Metadata)
+	}
+
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO companies (name, normalized_name, domain, metadata, created_at, updated_at)
+		VALUES ($1, $2, NULLIF($3, ''), $4::jsonb, NOW(), NOW())
+		ON CONFLICT (normalized_name) DO UPDATE SET
+			name = EXCLUDED.name,
+			domain = COALESCE(EXCLUDED.domain, companies.domain),
+			metadata = COALESCE(EXCLUDED.metadata, companies.metadata),
+			updated_at = NOW()
+		RETURNING id
+	`, company.Name, normalized, company.Domain, metadata).Scan(&company.ID)
+
+	if err != nil {
+		return fmt.Errorf("failed to upsert company: %w", err)
+	}
+
+	return nil
+}
+
+// AddAlias links a normalized alias to a company. When force is false an
+// existing mapping is left untouched.
+func (r *CompanyRepository) AddAlias(ctx context.Context, companyID int64, name, alias string, force bool) error {
+	query := `
+		INSERT INTO company_aliases (alias, name, company_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (alias) DO NOTHING
+	`
+	if force {
+		query = `
+			INSERT INTO company_aliases (alias, name, company_id)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (alias) DO UPDATE SET company_id = EXCLUDED.company_id
+		`
+	}
+
+	if _, err := r.db.ExecContext(ctx, query, alias, name, companyID); err != nil {
+		return fmt.Errorf("failed to add company alias: %w", err)
+	}
+
+	return nil
+}
+
+// FindByID retrieves a company by ID
+func (r *CompanyRepository) FindByID(ctx context.Context, id int64) (*models.Company, error) {
+	query := `SELECT ` + companyColumns + ` FROM companies c WHERE c.id = $1`
+
+	company, err := scanCompany(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("company not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find company: %w", err)
+	}
+
+	return company, nil
+}
+
+// List lists one page of companies ordered by number of jobs, with the
+// number of companies matching the query
+func (r *CompanyRepository) List(ctx context.Context, query *models.CompanySearchQuery) (*models.CompanyPage, error) {
+	where := "1=1"
+	args := []interface{}{}
+	argPos := 1
+
+	if query.Name != "" {
+		where += fmt.Sprintf(` AND (c.name ILIKE $%d OR EXISTS (
+			SELECT 1 FROM company_aliases a WHERE a.company_id = c.id AND a.name ILIKE $%d
+		))`, argPos, argPos)
+		args = append(args, "%"+query.Name+"%")
+		argPos++
+	}
+
+	page := &models.CompanyPage{Companies: make([]*models.Company, 0)}
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM companies c WHERE `+where, args...).Scan(&page.Total); err != nil {
+		return nil, fmt.Errorf("failed to count companies: %w", err)
+	}
+
+	if query.Limit == 0 {
+		query.Limit = 20
+	}
+	sql := `SELECT ` + companyColumns + ` FROM companies c WHERE ` + where +
+		fmt.Sprintf(" ORDER BY job_count DESC, c.name LIMIT $%d OFFSET $%d", argPos, argPos+1)
+	args = append(args, query.Limit, query.Page*query.Limit)
+
+	rows, err := r.db.QueryContext(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list companies: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		company, err := scanCompany(rows)
+		if err != nil {
+			logger.Error("Failed to scan company: %v", err)
+			continue
+		}
+		page.Companies = append(page.Companies, company)
+	}
+
+	return page, nil
+}
+
+// CompanyNames returns the distinct raw company names found on jobs, only
+// on jobs without a company if unlinked is set
+func (r *CompanyRepository) CompanyNames(ctx context.Context, unlinked bool) ([]string, error) {
+	query := "SELECT DISTINCT company FROM jobs"
+	if unlinked {
+		query += " WHERE company_id IS NULL"
+	}
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list company names: %w", err)
+	}
+	defer rows.Close()
+
+	names := make([]string, 0)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err == nil {
+			names = append(names, name)
+		}
+	}
+
+	return names, nil
+}
+
+// LinkJobs links the jobs with the given raw company name to a company,
+// only those without a company if unlinked is set, returning how many
+// jobs changed
+func (r *CompanyRepository) LinkJobs(ctx context.Context, name string, companyID int64, unlinked bool) (int64, error) {
+	query := "UPDATE jobs SET company_id = $1 WHERE company = $2 AND company_id IS DISTINCT FROM $1"
+	if unlinked {
+		query += " AND company_id IS NULL"
+	}
+	result, err := r.db.ExecContext(ctx, query, companyID, name)
+	if err != nil {
+		return 0, fmt.Errorf("failed to link jobs to company: %w", err)
+	}
+
+	count, _ := result.RowsAffected()
+	return count, nil
+}
+
+// scanCompany scans a row selected with companyColumns
+func scanCompany(row rowScanner) (*models.Company, error) {
+	company := &models.Company{}
+	var metadata string
+	err := row.Scan(
+		&company.ID, &company.Name, &company.Domain, &metadata,
+		&company.CreatedAt, &company.UpdatedAt,
+		pq.Array(&company.Aliases), &company.JobCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if metadata != "" {
+		company.Metadata = []byte(metadata)
+	}
+	return company, nil
+}
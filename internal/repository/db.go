@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
 	"github.com/abhisheksainimitawa/job-aggregator/pkg/logger"
 )
 
@@ -28,42 +29,23 @@ func NewDB(dsn string) (*sql.DB, error) {
 	return db, nil
 }
 
-// InitSchema initializes the database schema
-func InitSchema(db *sql.DB) error {
-	schema := `
-		CREATE TABLE IF NOT EXISTS jobs (
-			id BIGSERIAL PRIMARY KEY,
-			title VARCHAR(255) NOT NULL,
-			company VARCHAR(255) NOT NULL,
-			location VARCHAR(255) NOT NULL,
-			salary VARCHAR(100),
-			description TEXT NOT NULL,
-			url TEXT NOT NULL,
-			source VARCHAR(50) NOT NULL,
-			remote_ok BOOLEAN DEFAULT FALSE,
-			job_type VARCHAR(50) NOT NULL,
-			posted_at TIMESTAMP NOT NULL,
-			scraped_at TIMESTAMP NOT NULL,
-			hash VARCHAR(64) UNIQUE NOT NULL,
-			created_at TIMESTAMP NOT NULL DEFAULT NOW(),
-			updated_at TIMESTAMP NOT NULL DEFAULT NOW()
-		);
+// NewSQLiteDB opens an embedded SQLite database file, creating it and its
+// schema if needed. The path ":memory:" opens a private in-memory database.
+func NewSQLiteDB(path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite3", "file:"+path+"?_foreign_keys=on&_busy_timeout=5000")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
 
-		CREATE INDEX IF NOT EXISTS idx_jobs_title ON jobs(title);
-		CREATE INDEX IF NOT EXISTS idx_jobs_company ON jobs(company);
-		CREATE INDEX IF NOT EXISTS idx_jobs_location ON jobs(location);
-		CREATE INDEX IF NOT EXISTS idx_jobs_source ON jobs(source);
-		CREATE INDEX IF NOT EXISTS idx_jobs_posted_at ON jobs(posted_at DESC);
-		CREATE INDEX IF NOT EXISTS idx_jobs_remote_ok ON jobs(remote_ok);
-		CREATE INDEX IF NOT EXISTS idx_jobs_job_type ON jobs(job_type);
-		CREATE INDEX IF NOT EXISTS idx_jobs_hash ON jobs(hash);
-	`
+	// SQLite has a single writer, and an in-memory database only lives as
+	// long as its connection
+	db.SetMaxOpenConns(1)
 
-	_, err := db.Exec(schema)
-	if err != nil {
-		return fmt.Errorf("failed to initialize schema: %w", err)
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create SQLite schema: %w", err)
 	}
 
-	logger.Info("Database schema initialized successfully")
-	return nil
+	logger.Info("SQLite database %s opened successfully", path)
+	return db, nil
 }
@@ -0,0 +1,112 @@
+package repository_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/abhisheksainimitawa/job-aggregator/internal/models"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/repository"
+)
+
+// benchBatchSize is the size of a large scrape run
+const benchBatchSize = 100000
+
+// benchJobs returns n distinct jobs; run makes the hashes of every run
+// unique, while the same run twice re-scrapes the same jobs
+func benchJobs(n, run int) []*models.Job {
+	now := time.Now().UTC()
+	jobs := make([]*models.Job, n)
+	for i := range jobs {
+		salaryMin, salaryMax := int64(50000+i%100*1000), int64(80000+i%100*1000)
+		jobs[i] = &models.Job{
+			Title:             fmt.Sprintf("Backend Engineer %d", i%500),
+			Company:           fmt.Sprintf("Company %d", i%2000),
+			Location:          "Berlin, Germany",
+			Description:       "Build and operate services in Go and PostgreSQL on Kubernetes.",
+			URL:               fmt.Sprintf("https://example.com/jobs/%d/%d", run, i),
+			Source:            []string{"indeed", "linkedin", "remoteok"}[i%3],
+			JobType:           "full-time",
+			PostedAt:          now.Add(-time.Duration(i%720) * time.Hour),
+			ScrapedAt:         now,
+			Hash:              fmt.Sprintf("bench-%d-%d", run, i),
+			PostedAtPrecision: "exact",
+			SalaryMin:         &salaryMin,
+			SalaryMax:         &salaryMax,
+			SalaryCurrency:    "EUR",
+			Tags:              []string{"go", "postgresql", "kubernetes"},
+		}
+		if i%2 == 0 {
+			jobs[i].NativeID = fmt.Sprintf("%d-%d", run, i)
+		}
+	}
+	return jobs
+}
+
+// BenchmarkCreateBatch stores batches of new jobs, then re-scrapes a
+// batch, on every store. Postgres runs when TEST_DATABASE_URL is set.
+func BenchmarkCreateBatch(b *testing.B) {
+	stores := []struct {
+		name     string
+		newStore func(b *testing.B) repository.JobStore
+	}{
+		{"Memory", func(b *testing.B) repository.JobStore {
+			return repository.NewMemoryJobStore()
+		}},
+		{"SQLite", func(b *testing.B) repository.JobStore {
+			db, err := repository.NewSQLiteDB(":memory:")
+			if err != nil {
+				b.Fatalf("NewSQLiteDB() error = %v", err)
+			}
+			b.Cleanup(func() { db.Close() })
+			return repository.NewSQLiteJobStore(db)
+		}},
+		{"Postgres", func(b *testing.B) repository.JobStore {
+			db := postgresDB(b)
+			truncateJobs(b, db)
+			return repository.NewJobRepository(db)
+		}},
+	}
+
+	for _, s := range stores {
+		b.Run(s.name+"/Insert", func(b *testing.B) {
+			store := s.newStore(b)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				jobs := benchJobs(benchBatchSize, i)
+				b.StartTimer()
+				storeBatch(b, store, jobs)
+			}
+			reportJobsPerSecond(b)
+		})
+
+		b.Run(s.name+"/Update", func(b *testing.B) {
+			store := s.newStore(b)
+			storeBatch(b, store, benchJobs(benchBatchSize, 0))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				jobs := benchJobs(benchBatchSize, 0)
+				b.StartTimer()
+				storeBatch(b, store, jobs)
+			}
+			reportJobsPerSecond(b)
+		})
+	}
+}
+
+func storeBatch(b *testing.B, store repository.JobStore, jobs []*models.Job) {
+	result, err := store.CreateBatch(context.Background(), jobs)
+	if err != nil {
+		b.Fatalf("CreateBatch() error = %v", err)
+	}
+	if len(result.Rejected) != 0 {
+		b.Fatalf("CreateBatch() rejected %+v", result.Rejected[0])
+	}
+}
+
+func reportJobsPerSecond(b *testing.B) {
+	b.ReportMetric(float64(b.N*benchBatchSize)/b.Elapsed().Seconds(), "jobs/s")
+}
@@ -0,0 +1,460 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/abhisheksainimitawa/job-aggregator/internal/models"
+	"github.com/lib/pq"
+)
+
+// stagingColumns are the columns copied into job_staging: the values of
+// jobArgs, then the tags and the row of the job in its batch
+var stagingColumns = append(splitColumns(jobInsertColumns), "tags", "row_num")
+
+// stagedUpdateSet is upsertJobSet reading the new values from job_staging
+var stagedUpdateSet = strings.NewReplacer("EXCLUDED.last_seen_at", "s.scraped_at", "EXCLUDED.", "s.").Replace(upsertJobSet)
+
+// ingestLockKey identifies the advisory lock that serializes batches, so
+// two batches never insert the same new job
+const ingestLockKey = 7355608
+
+// splitColumns splits a column list like jobInsertColumns into names
+func splitColumns(columns string) []string {
+	return strings.Fields(strings.ReplaceAll(columns, ",", " "))
+}
+
+// CreateBatch inserts or updates scraped jobs with a few set-based
+// statements instead of one upsert per job. The batch is copied into a
+// temporary staging table and matched against job_keys: jobs with a
+// board-native ID on (source, native_id), which survives URL and title
+// changes, others on the content hash. A job whose native ID is unknown
+// adopts the stored job of its source with its hash and no native ID,
+// i.e. one scraped before its board exposed IDs. Matched jobs are updated
+// and the rest inserted, then tags and versions are written in bulk. Jobs
+// that cannot be stored, like those matching a job of a detached
+// partition, are reported in the result; any other error rolls back the
+// whole batch.
+func (r *JobRepository) CreateBatch(ctx context.Context, jobs []*models.Job) (*models.BatchResult, error) {
+	accepted, rejected := screenBatch(jobs)
+	result := &models.BatchResult{Rejected: rejected}
+	if len(accepted) == 0 {
+		return result, nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", ingestLockKey); err != nil {
+		return nil, fmt.Errorf("failed to lock ingestion: %w", err)
+	}
+
+	now := time.Now()
+	if err := stageJobs(ctx, tx, jobs, accepted, now); err != nil {
+		return nil, err
+	}
+
+	taken, err := rejectTakenHashes(ctx, tx, jobs)
+	if err != nil {
+		return nil, err
+	}
+	result.Rejected = append(result.Rejected, taken...)
+
+	if err := matchStaged(ctx, tx, "stored_id"); err != nil {
+		return nil, err
+	}
+	detached, err := rejectDetached(ctx, tx, jobs)
+	if err != nil {
+		return nil, err
+	}
+	result.Rejected = append(result.Rejected, detached...)
+
+	stored, err := lockStored(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE jobs j SET `+stagedUpdateSet+`,
+			native_id = CASE WHEN j.native_id = '' THEN s.native_id ELSE j.native_id END
+		FROM job_staging s
+		WHERE j.id = s.stored_id
+	`); err != nil {
+		return nil, fmt.Errorf("failed to update jobs: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO jobs (`+jobInsertColumns+`, first_seen_at, last_seen_at)
+		SELECT `+jobInsertColumns+`, scraped_at, scraped_at
+		FROM job_staging
+		WHERE NOT rejected AND stored_id IS NULL
+		ORDER BY row_num
+	`); err != nil {
+		return nil, fmt.Errorf("failed to insert jobs: %w", err)
+	}
+
+	if err := matchStaged(ctx, tx, "job_id"); err != nil {
+		return nil, err
+	}
+	if err := readStaged(ctx, tx, jobs, result); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM job_tags WHERE job_id IN (SELECT job_id FROM job_staging WHERE job_id IS NOT NULL)
+	`); err != nil {
+		return nil, fmt.Errorf("failed to clear job tags: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO job_tags (job_id, tag)
+		SELECT job_id, UNNEST(tags) FROM job_staging WHERE job_id IS NOT NULL
+		ON CONFLICT DO NOTHING
+	`); err != nil {
+		return nil, fmt.Errorf("failed to store job tags: %w", err)
+	}
+
+	if err := recordVersions(ctx, tx, jobs, stored, now); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	sortRejections(result.Rejected)
+	return result, nil
+}
+
+// stageJobs copies the accepted jobs of a batch into job_staging, a
+// temporary table with the column types of jobs that is dropped on commit
+func stageJobs(ctx context.Context, tx *sql.Tx, jobs []*models.Job, rows []int, now time.Time) error {
+	_, err := tx.ExecContext(ctx, `
+		CREATE TEMP TABLE job_staging ON COMMIT DROP AS
+		SELECT `+jobInsertColumns+` FROM jobs WITH NO DATA;
+		ALTER TABLE job_staging
+			ADD COLUMN tags TEXT[],
+			ADD COLUMN row_num INT NOT NULL,
+			ADD COLUMN rejected BOOLEAN NOT NULL DEFAULT FALSE,
+			ADD COLUMN stored_id BIGINT,
+			ADD COLUMN job_id BIGINT
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create staging table: %w", err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("job_staging", stagingColumns...))
+	if err != nil {
+		return fmt.Errorf("failed to prepare copy: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, row := range rows {
+		job := jobs[row]
+		if _, err := stmt.ExecContext(ctx, append(jobArgs(job, now), pq.Array(job.Tags), row)...); err != nil {
+			return fmt.Errorf("failed to copy job: %w", err)
+		}
+	}
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		return fmt.Errorf("failed to copy jobs: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, "ANALYZE job_staging"); err != nil {
+		return fmt.Errorf("failed to analyze staging table: %w", err)
+	}
+	return nil
+}
+
+// rejectTakenHashes rejects staged jobs with an unknown native ID whose
+// hash already belongs to a stored job of another source or with another
+// native ID; inserting them would violate the unique hash index
+func rejectTakenHashes(ctx context.Context, tx *sql.Tx, jobs []*models.Job) ([]models.BatchRejection, error) {
+	rows, err := tx.QueryContext(ctx, `
+		UPDATE job_staging s SET rejected = TRUE
+		FROM job_keys j
+		WHERE s.native_id <> '' AND j.hash = s.hash
+		  AND NOT (j.source = s.source AND j.native_id = '')
+		  AND NOT EXISTS (SELECT 1 FROM job_keys k WHERE k.source = s.source AND k.native_id = s.native_id AND k.native_id <> '')
+		RETURNING s.row_num, j.id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check job hashes: %w", err)
+	}
+	defer rows.Close()
+
+	var rejected []models.BatchRejection
+	for rows.Next() {
+		var row int
+		var owner int64
+		if err := rows.Scan(&row, &owner); err != nil {
+			return nil, fmt.Errorf("failed to scan job hash: %w", err)
+		}
+		rejected = append(rejected, models.BatchRejection{
+			Row: row, Hash: jobs[row].Hash, Reason: fmt.Sprintf(hashTakenReason, owner),
+		})
+	}
+	return rejected, rows.Err()
+}
+
+// rejectDetached rejects staged jobs matching a stored job that is not in
+// jobs because its partition is detached; the update would miss it
+func rejectDetached(ctx context.Context, tx *sql.Tx, jobs []*models.Job) ([]models.BatchRejection, error) {
+	rows, err := tx.QueryContext(ctx, `
+		UPDATE job_staging s SET rejected = TRUE
+		WHERE NOT s.rejected AND s.stored_id IS NOT NULL
+		  AND NOT EXISTS (SELECT 1 FROM jobs j WHERE j.id = s.stored_id)
+		RETURNING s.row_num, s.stored_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check stored jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var rejected []models.BatchRejection
+	for rows.Next() {
+		var row int
+		var id int64
+		if err := rows.Scan(&row, &id); err != nil {
+			return nil, fmt.Errorf("failed to scan stored job: %w", err)
+		}
+		rejected = append(rejected, models.BatchRejection{
+			Row: row, Hash: jobs[row].Hash, Reason: fmt.Sprintf(detachedReason, id),
+		})
+	}
+	return rejected, rows.Err()
+}
+
+// matchStaged sets column of job_staging to the ID of the stored job each
+// staged job matches
+func matchStaged(ctx context.Context, tx *sql.Tx, column string) error {
+	for _, match := range []string{
+		"s.native_id <> '' AND k.source = s.source AND k.native_id = s.native_id AND k.native_id <> ''",
+		"s.native_id = '' AND k.hash = s.hash",
+		"s.native_id <> '' AND s." + column + " IS NULL AND k.hash = s.hash AND k.source = s.source AND k.native_id = ''",
+	} {
+		_, err := tx.ExecContext(ctx, `
+			UPDATE job_staging s SET `+column+` = k.id
+			FROM job_keys k
+			WHERE NOT s.rejected AND `+match)
+		if err != nil {
+			return fmt.Errorf("failed to match staged jobs: %w", err)
+		}
+	}
+	return nil
+}
+
+// lockStored locks the stored jobs a batch updates and returns their
+// versioned fields by batch row
+func lockStored(ctx context.Context, tx *sql.Tx) (map[int]*models.Job, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT s.row_num, j.id, j.title, j.company, j.location, COALESCE(j.salary, ''),
+		       j.description, j.job_type, j.canonical_url, j.remote_ok
+		FROM job_staging s
+		JOIN jobs j ON j.id = s.stored_id
+		ORDER BY j.id
+		FOR UPDATE OF j
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock stored jobs: %w", err)
+	}
+	defer rows.Close()
+
+	stored := make(map[int]*models.Job)
+	for rows.Next() {
+		var row int
+		job := &models.Job{}
+		err := rows.Scan(&row, &job.ID, &job.Title, &job.Company, &job.Location, &job.Salary,
+			&job.Description, &job.JobType, &job.CanonicalURL, &job.RemoteOk)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan stored job: %w", err)
+		}
+		stored[row] = job
+	}
+	return stored, rows.Err()
+}
+
+// readStaged sets the IDs and clusters of the stored jobs of a batch and
+// counts the inserted and updated jobs
+func readStaged(ctx context.Context, tx *sql.Tx, jobs []*models.Job, result *models.BatchResult) error {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT s.row_num, s.job_id, s.stored_id IS NOT NULL, j.cluster_id
+		FROM job_staging s
+		JOIN jobs j ON j.id = s.job_id
+		WHERE NOT s.rejected
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to read stored jobs: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row int
+		var id int64
+		var clusterID *int64
+		var existed bool
+		if err := rows.Scan(&row, &id, &existed, &clusterID); err != nil {
+			return fmt.Errorf("failed to scan stored job: %w", err)
+		}
+		jobs[row].ID, jobs[row].ClusterID = id, clusterID
+		if existed {
+			result.Updated++
+		} else {
+			result.Inserted++
+		}
+	}
+	return rows.Err()
+}
+
+// recordVersions stores the changed fields of the re-scraped jobs of a
+// batch in job_versions
+func recordVersions(ctx context.Context, tx *sql.Tx, jobs []*models.Job, stored map[int]*models.Job, changedAt time.Time) error {
+	if len(stored) == 0 {
+		return nil
+	}
+
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("job_versions", "job_id", "changed_at", "changes", "changed_fields"))
+	if err != nil {
+		return fmt.Errorf("failed to prepare copy: %w", err)
+	}
+	defer stmt.Close()
+
+	for row, old := range stored {
+		changes := models.DiffJobs(old, jobs[row])
+		if len(changes) == 0 {
+			continue
+		}
+
+		data, err := json.Marshal(changes)
+		if err != nil {
+			return fmt.Errorf("failed to encode job changes: %w", err)
+		}
+
+		fields := make([]string, 0, len(changes))
+		for field := range changes {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+
+		if _, err := stmt.ExecContext(ctx, old.ID, changedAt, string(data), pq.Array(fields)); err != nil {
+			return fmt.Errorf("failed to record job version: %w", err)
+		}
+	}
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		return fmt.Errorf("failed to record job versions: %w", err)
+	}
+	return nil
+}
+
+// rekeyBatchSize is the number of jobs re-keyed per statement
+const rekeyBatchSize = 1000
+
+// RekeyListings recomputes the canonical URL, native ID and hash of jobs
+// stored before URLs were canonicalized, which have no canonical URL, so
+// that re-scrapes of them match the stored rows. rekey sets the keys of a
+// job from its scraped fields. A job whose new hash or native ID already
+// belongs to another job keeps its old keys. It returns the number of
+// jobs re-keyed and kept.
+func (r *JobRepository) RekeyListings(ctx context.Context, rekey func(job *models.Job)) (rekeyed, kept int64, err error) {
+	var last int64
+	for {
+		rows, err := r.db.QueryContext(ctx, `
+			SELECT id, title, company, location, url, source
+			FROM jobs
+			WHERE canonical_url = '' AND id > $1
+			ORDER BY id
+			LIMIT $2
+		`, last, rekeyBatchSize)
+		if err != nil {
+			return rekeyed, kept, fmt.Errorf("failed to read jobs to re-key: %w", err)
+		}
+
+		var jobs []*models.Job
+		for rows.Next() {
+			job := &models.Job{}
+			if err := rows.Scan(&job.ID, &job.Title, &job.Company, &job.Location, &job.URL, &job.Source); err != nil {
+				rows.Close()
+				return rekeyed, kept, fmt.Errorf("failed to scan job to re-key: %w", err)
+			}
+			jobs = append(jobs, job)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return rekeyed, kept, fmt.Errorf("failed to read jobs to re-key: %w", err)
+		}
+		if len(jobs) == 0 {
+			return rekeyed, kept, nil
+		}
+		last = jobs[len(jobs)-1].ID
+
+		n, k, err := r.rekey(ctx, jobs, rekey)
+		if err != nil {
+			return rekeyed, kept, err
+		}
+		rekeyed, kept = rekeyed+n, kept+k
+	}
+}
+
+// rekey writes the new keys of one batch of jobs. Of the jobs sharing a
+// new hash or native ID only the first gets it.
+func (r *JobRepository) rekey(ctx context.Context, jobs []*models.Job, rekey func(job *models.Job)) (rekeyed, kept int64, err error) {
+	ids := make([]int64, len(jobs))
+	sources := make([]string, len(jobs))
+	urls := make([]string, len(jobs))
+	hashes := make([]string, len(jobs))
+	nativeIDs := make([]string, len(jobs))
+	unique := make([]bool, len(jobs))
+
+	seen := make(map[string]bool)
+	for i, job := range jobs {
+		rekey(job)
+		ids[i], sources[i], urls[i], hashes[i], nativeIDs[i] = job.ID, job.Source, job.CanonicalURL, job.Hash, job.NativeID
+
+		key := nativeKey(job)
+		unique[i] = !seen[job.Hash] && (key == "" || !seen[key])
+		seen[job.Hash] = true
+		if key != "" {
+			seen[key] = true
+		}
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		UPDATE jobs j SET
+			canonical_url = n.canonical_url,
+			hash = CASE WHEN n.taken THEN j.hash ELSE n.hash END,
+			native_id = CASE WHEN n.taken THEN j.native_id ELSE n.native_id END
+		FROM (
+			SELECT u.id, u.canonical_url, u.hash, u.native_id, NOT u.is_unique OR EXISTS (
+				SELECT 1 FROM job_keys k
+				WHERE k.id <> u.id
+				  AND (k.hash = u.hash OR (u.native_id <> '' AND k.source = u.source AND k.native_id = u.native_id))
+			) AS taken
+			FROM UNNEST($1::bigint[], $2::text[], $3::text[], $4::text[], $5::text[], $6::boolean[])
+				AS u(id, source, canonical_url, hash, native_id, is_unique)
+		) n
+		WHERE j.id = n.id
+		RETURNING n.taken
+	`, pq.Array(ids), pq.Array(sources), pq.Array(urls), pq.Array(hashes), pq.Array(nativeIDs), pq.Array(unique))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to re-key jobs: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var taken bool
+		if err := rows.Scan(&taken); err != nil {
+			return 0, 0, fmt.Errorf("failed to scan re-keyed job: %w", err)
+		}
+		if taken {
+			kept++
+		} else {
+			rekeyed++
+		}
+	}
+	return rekeyed, kept, rows.Err()
+}
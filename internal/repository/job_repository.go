@@ -3,15 +3,132 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
 	"fmt"
+	"html"
 	"strings"
 	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/geo"
 	"github.com/abhisheksainimitawa/job-aggregator/internal/models"
 	"github.com/abhisheksainimitawa/job-aggregator/pkg/logger"
+	"github.com/lib/pq"
 )
 
+// jobColumns is the column list selected for every job read
+const jobColumns = `
+	id, title, company, location, salary, description, url, source,
+	remote_ok, job_type, posted_at, scraped_at, hash, created_at, updated_at,
+	normalized_title, seniority, role_family, company_id,
+	description_html, description_markdown, posted_at_precision,
+	cluster_id, fingerprint, canonical_url, native_id,
+	status, COALESCE(first_seen_at, created_at), COALESCE(last_seen_at, scraped_at), closed_at,
+	salary_min, salary_max, salary_currency, latitude, longitude,
+	quality_score, quality_reasons, flagged, flag_reasons, flag_review,
+	ARRAY(SELECT tag FROM job_tags WHERE job_id = jobs.id ORDER BY tag)
+`
+
+// precisionRank scores posted_at_precision, higher is more trustworthy.
+// precisionRanks is the same mapping for cursors built in Go.
+const precisionRank = `(CASE posted_at_precision
+	WHEN 'exact' THEN 3 WHEN 'day' THEN 2 WHEN 'approximate' THEN 1 ELSE 0 END)`
+
+var precisionRanks = map[string]int{"exact": 3, "day": 2, "approximate": 1}
+
+// newestKey is the newest-first sort key: newest day first and, within a
+// day, exact timestamps before dates estimated from phrases like
+// "3 days ago". idx_jobs_newest indexes the same expressions.
+const newestKey = "(DATE(posted_at), " + precisionRank + ", posted_at, id)"
+
+// qualityBand labels quality_score with its band of models.QualityBands.
+// Migration 0018 buckets the stats view the same way.
+const qualityBand = `(CASE
+	WHEN quality_score >= 80 THEN '80-100' WHEN quality_score >= 60 THEN '60-79'
+	WHEN quality_score >= 40 THEN '40-59' WHEN quality_score >= 20 THEN '20-39' ELSE '0-19' END)`
+
+// exactCountLimit is the number of matches counted exactly; larger totals
+// are estimated by the query planner
+const exactCountLimit = 10000
+
+// tsQuery parses user keywords with web search syntax: quoted phrases,
+// OR and -exclusion
+const tsQuery = "websearch_to_tsquery('english', $%d)"
+
+// headlineOptions configures the highlighted snippets of search results
+const headlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15, MaxFragments=2"
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// jobFields returns the scan destinations of jobColumns, except the tags
+func jobFields(job *models.Job) []interface{} {
+	return []interface{}{
+		&job.ID, &job.Title, &job.Company, &job.Location, &job.Salary,
+		&job.Description, &job.URL, &job.Source, &job.RemoteOk, &job.JobType,
+		&job.PostedAt, &job.ScrapedAt, &job.Hash, &job.CreatedAt, &job.UpdatedAt,
+		&job.NormalizedTitle, &job.Seniority, &job.RoleFamily, &job.CompanyID,
+		&job.DescriptionHTML, &job.DescriptionMarkdown, &job.PostedAtPrecision,
+		&job.ClusterID, &job.Fingerprint, &job.CanonicalURL, &job.NativeID,
+		&job.Status, &job.FirstSeenAt, &job.LastSeenAt, &job.ClosedAt,
+		&job.SalaryMin, &job.SalaryMax, &job.SalaryCurrency, &job.Latitude, &job.Longitude,
+		&job.QualityScore, (*jsonList[models.QualityReason])(&job.QualityReasons),
+		&job.Flagged, (*jsonList[string])(&job.FlagReasons), &job.FlagReview,
+	}
+}
+
+// jsonList reads and writes JSON array columns like quality_reasons
+type jsonList[T any] []T
+
+// Scan decodes the column, which SQLite returns as text
+func (l *jsonList[T]) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	case nil:
+		*l = nil
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into a JSON list", src)
+	}
+
+	var list []T
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("failed to decode JSON list: %w", err)
+	}
+	if len(list) == 0 {
+		list = nil
+	}
+	*l = list
+	return nil
+}
+
+// Value encodes the list, an empty array when there are no elements
+func (l jsonList[T]) Value() (driver.Value, error) {
+	if len(l) == 0 {
+		return "[]", nil
+	}
+	data, err := json.Marshal([]T(l))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode JSON list: %w", err)
+	}
+	return string(data), nil
+}
+
+// scanJob scans a row selected with jobColumns
+func scanJob(row rowScanner) (*models.Job, error) {
+	job := &models.Job{}
+	if err := row.Scan(append(jobFields(job), pq.Array(&job.Tags))...); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
 // JobRepository handles database operations for jobs
 type JobRepository struct {
 	db *sql.DB
@@ -22,152 +139,250 @@ func NewJobRepository(db *sql.DB) *JobRepository {
 	return &JobRepository{db: db}
 }
 
-// Create inserts a new job into the database
-func (r *JobRepository) Create(ctx context.Context, job *models.Job) error {
-	query := `
-		INSERT INTO jobs (title, company, location, salary, description, url, source, 
-		                  remote_ok, job_type, posted_at, scraped_at, hash, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
-		RETURNING id
-	`
-
-	now := time.Now()
-	err := r.db.QueryRowContext(ctx, query,
+// jobInsertColumns are the columns written for a new job, in jobArgs order
+const jobInsertColumns = `
+	title, company, location, salary, description, url, source,
+	remote_ok, job_type, posted_at, scraped_at, hash, created_at, updated_at,
+	normalized_title, seniority, role_family, company_id,
+	description_html, description_markdown, posted_at_precision,
+	canonical_url, native_id, salary_min, salary_max, salary_currency,
+	latitude, longitude, quality_score, quality_reasons, flagged, flag_reasons
+`
+
+// insertJob inserts a job; jobArgs supplies its parameters. A job is first
+// and last seen when it is scraped.
+const insertJob = `
+	INSERT INTO jobs (` + jobInsertColumns + `, first_seen_at, last_seen_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20,
+	        $21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31, $32, $11, $11)
+`
+
+// upsertJobSet is the update applied when a scraped job is already stored.
+// Seeing the job again also reopens it if it had been closed. The flag is
+// recomputed but an admin's review of it stays.
+const upsertJobSet = `
+	updated_at = EXCLUDED.updated_at,
+	scraped_at = EXCLUDED.scraped_at,
+	last_seen_at = EXCLUDED.last_seen_at,
+	missed_runs = 0,
+	status = 'open',
+	closed_at = NULL,
+	title = EXCLUDED.title,
+	company = EXCLUDED.company,
+	location = EXCLUDED.location,
+	salary = EXCLUDED.salary,
+	salary_min = EXCLUDED.salary_min,
+	salary_max = EXCLUDED.salary_max,
+	salary_currency = EXCLUDED.salary_currency,
+	latitude = EXCLUDED.latitude,
+	longitude = EXCLUDED.longitude,
+	description = EXCLUDED.description,
+	description_html = EXCLUDED.description_html,
+	description_markdown = EXCLUDED.description_markdown,
+	remote_ok = EXCLUDED.remote_ok,
+	job_type = EXCLUDED.job_type,
+	url = EXCLUDED.url,
+	canonical_url = EXCLUDED.canonical_url,
+	normalized_title = EXCLUDED.normalized_title,
+	seniority = EXCLUDED.seniority,
+	role_family = EXCLUDED.role_family,
+	company_id = EXCLUDED.company_id,
+	quality_score = EXCLUDED.quality_score,
+	quality_reasons = EXCLUDED.quality_reasons,
+	flagged = EXCLUDED.flagged,
+	flag_reasons = EXCLUDED.flag_reasons
+`
+
+// jobArgs returns the parameters of insertJob for a job
+func jobArgs(job *models.Job, now time.Time) []interface{} {
+	return []interface{}{
 		job.Title, job.Company, job.Location, job.Salary, job.Description,
 		job.URL, job.Source, job.RemoteOk, job.JobType, job.PostedAt,
 		job.ScrapedAt, job.Hash, now, now,
-	).Scan(&job.ID)
+		job.NormalizedTitle, job.Seniority, job.RoleFamily, job.CompanyID,
+		job.DescriptionHTML, job.DescriptionMarkdown, job.PostedAtPrecision,
+		job.CanonicalURL, job.NativeID, job.SalaryMin, job.SalaryMax, job.SalaryCurrency,
+		job.Latitude, job.Longitude, job.QualityScore, jsonList[models.QualityReason](job.QualityReasons),
+		job.Flagged, jsonList[string](job.FlagReasons),
+	}
+}
 
+// Create inserts a new job into the database
+func (r *JobRepository) Create(ctx context.Context, job *models.Job) error {
+	err := r.db.QueryRowContext(ctx, insertJob+" RETURNING id", jobArgs(job, time.Now())...).Scan(&job.ID)
 	if err != nil {
 		return fmt.Errorf("failed to create job: %w", err)
 	}
 
+	if err := replaceTags(ctx, r.db, job.ID, job.Tags); err != nil {
+		return err
+	}
+
 	return nil
 }
 
-// CreateBatch inserts multiple jobs in a single transaction (for performance)
-func (r *JobRepository) CreateBatch(ctx context.Context, jobs []*models.Job) error {
-	if len(jobs) == 0 {
-		return nil
-	}
+// FindByID retrieves a job by ID
+func (r *JobRepository) FindByID(ctx context.Context, id int64) (*models.Job, error) {
+	query := `SELECT ` + jobColumns + ` FROM jobs WHERE id = $1`
 
-	tx, err := r.db.BeginTx(ctx, nil)
+	job, err := scanJob(r.db.QueryRowContext(ctx, query, id))
+
+	if err == sql.ErrNoRows {
+		return nil, ErrJobNotFound
+	}
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		return nil, fmt.Errorf("failed to find job: %w", err)
 	}
-	defer tx.Rollback()
 
-	stmt, err := tx.PrepareContext(ctx, `
-		INSERT INTO jobs (title, company, location, salary, description, url, source, 
-		                  remote_ok, job_type, posted_at, scraped_at, hash, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
-		ON CONFLICT (hash) DO UPDATE SET
-			updated_at = EXCLUDED.updated_at,
-			scraped_at = EXCLUDED.scraped_at
-		RETURNING id
-	`)
-	if err != nil {
-		return fmt.Errorf("failed to prepare statement: %w", err)
+	if err := r.attachListings(ctx, []*models.Job{job}); err != nil {
+		return nil, err
 	}
-	defer stmt.Close()
 
-	now := time.Now()
-	for _, job := range jobs {
-		err := stmt.QueryRowContext(ctx,
-			job.Title, job.Company, job.Location, job.Salary, job.Description,
-			job.URL, job.Source, job.RemoteOk, job.JobType, job.PostedAt,
-			job.ScrapedAt, job.Hash, now, now,
-		).Scan(&job.ID)
+	return job, nil
+}
 
-		if err != nil {
-			logger.Error("Failed to insert job: %v", err)
-			continue
-		}
+// Search searches for jobs based on query parameters
+func (r *JobRepository) Search(ctx context.Context, query *models.JobSearchQuery) ([]*models.Job, error) {
+	if query.Limit == 0 {
+		query.Limit = 20
 	}
+	return r.search(ctx, query, query.Limit)
+}
 
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+// SearchPage searches for one page of jobs and adds the total number of
+// matches and the cursors of the neighbouring pages. Cursors are only
+// issued for the newest-first order.
+func (r *JobRepository) SearchPage(ctx context.Context, query *models.JobSearchQuery) (*models.JobPage, error) {
+	if query.Limit == 0 {
+		query.Limit = 20
 	}
 
-	return nil
-}
+	// One extra row tells whether there is a page beyond this one
+	jobs, err := r.search(ctx, query, query.Limit+1)
+	if err != nil {
+		return nil, err
+	}
 
-// FindByID retrieves a job by ID
-func (r *JobRepository) FindByID(ctx context.Context, id int64) (*models.Job, error) {
-	query := `
-		SELECT id, title, company, location, salary, description, url, source,
-		       remote_ok, job_type, posted_at, scraped_at, hash, created_at, updated_at
-		FROM jobs
-		WHERE id = $1
-	`
+	cursor := query.Cursor
+	if !query.Newest() {
+		cursor = nil
+	}
 
-	job := &models.Job{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&job.ID, &job.Title, &job.Company, &job.Location, &job.Salary,
-		&job.Description, &job.URL, &job.Source, &job.RemoteOk, &job.JobType,
-		&job.PostedAt, &job.ScrapedAt, &job.Hash, &job.CreatedAt, &job.UpdatedAt,
-	)
+	more := len(jobs) > query.Limit
+	if more {
+		if cursor != nil && cursor.Before {
+			jobs = jobs[1:]
+		} else {
+			jobs = jobs[:query.Limit]
+		}
+	}
 
-	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("job not found")
+	page := &models.JobPage{Jobs: jobs}
+	if len(jobs) > 0 && query.Newest() {
+		backward := cursor != nil && cursor.Before
+		if more || backward {
+			page.NextCursor = cursorAt(jobs[len(jobs)-1], false).Encode()
+		}
+		if (backward && more) || (cursor != nil && !backward) || (cursor == nil && query.Page > 0) {
+			page.PrevCursor = cursorAt(jobs[0], true).Encode()
+		}
 	}
+
+	page.Total, page.TotalExact, err = r.count(ctx, query)
 	if err != nil {
-		return nil, fmt.Errorf("failed to find job: %w", err)
+		return nil, err
 	}
 
-	return job, nil
+	return page, nil
 }
 
-// Search searches for jobs based on query parameters
-func (r *JobRepository) Search(ctx context.Context, query *models.JobSearchQuery) ([]*models.Job, error) {
-	sql := `
-		SELECT id, title, company, location, salary, description, url, source,
-		       remote_ok, job_type, posted_at, scraped_at, created_at, updated_at
-		FROM jobs
-		WHERE 1=1
-	`
+// cursorAt returns the cursor positioned at job
+func cursorAt(job *models.Job, before bool) models.Cursor {
+	return models.Cursor{
+		PostedAt: job.PostedAt,
+		Rank:     precisionRanks[job.PostedAtPrecision],
+		ID:       job.ID,
+		Before:   before,
+	}
+}
 
-	args := []interface{}{}
-	argPos := 1
+// orderBy returns the ORDER BY expressions of a search. Every order ends
+// with id so pages are stable, and each is backed by an index (see
+// migrations 0012 and 0013); relevance ranks the rows matched through the
+// full-text index. backward reverses the newest-first order for cursors
+// that page towards newer jobs.
+func orderBy(query *models.JobSearchQuery, keywordsPos, nearPos int, backward bool) string {
+	dir := " ASC"
+	if query.Descending() {
+		dir = " DESC"
+	}
 
-	if query.Keywords != "" {
-		sql += fmt.Sprintf(" AND (title ILIKE $%d OR description ILIKE $%d OR company ILIKE $%d)", argPos, argPos, argPos)
-		args = append(args, "%"+query.Keywords+"%")
-		argPos++
+	switch query.SortField() {
+	case models.SortScrapedAt:
+		return "scraped_at" + dir + ", id" + dir
+	case models.SortSalary:
+		return "salary_max" + dir + " NULLS LAST, id" + dir
+	case models.SortCompany:
+		return "company" + dir + ", id" + dir
+	case models.SortRelevance:
+		if keywordsPos > 0 {
+			return fmt.Sprintf("ts_rank(search_vector, "+tsQuery+")"+dir+", posted_at DESC, id DESC", keywordsPos)
+		}
+	case models.SortDistance:
+		// Jobs without coordinates, like remote ones, come last
+		if nearPos > 0 {
+			return fmt.Sprintf("geo_point <-> point($%d, $%d)"+dir+" NULLS LAST, id", nearPos, nearPos+1)
+		}
 	}
 
-	if query.Location != "" {
-		sql += fmt.Sprintf(" AND location ILIKE $%d", argPos)
-		args = append(args, "%"+query.Location+"%")
-		argPos++
+	if backward || !query.Descending() {
+		return "DATE(posted_at), " + precisionRank + ", posted_at, id"
 	}
+	return "DATE(posted_at) DESC, " + precisionRank + " DESC, posted_at DESC, id DESC"
+}
+
+// search runs a search returning at most limit jobs
+func (r *JobRepository) search(ctx context.Context, query *models.JobSearchQuery, limit int) ([]*models.Job, error) {
+	// Only canonical jobs; duplicates are returned as their listings
+	where, args, keywordsPos := searchFilter(query, "")
+	argPos := len(args) + 1
 
-	if query.Remote != nil {
-		sql += fmt.Sprintf(" AND remote_ok = $%d", argPos)
-		args = append(args, *query.Remote)
-		argPos++
+	// Cursors only apply to the newest-first order
+	cursor := query.Cursor
+	if !query.Newest() {
+		cursor = nil
 	}
 
-	if query.JobType != "" {
-		sql += fmt.Sprintf(" AND job_type = $%d", argPos)
-		args = append(args, query.JobType)
-		argPos++
+	// Keyset pagination: continue after (or end before) the cursor row
+	backward := cursor != nil && cursor.Before
+	if cursor != nil {
+		op := "<"
+		if backward {
+			op = ">"
+		}
+		where += fmt.Sprintf(" AND %s %s (DATE($%d::timestamp), $%d, $%d::timestamp, $%d)",
+			newestKey, op, argPos, argPos+1, argPos, argPos+2)
+		args = append(args, cursor.PostedAt, cursor.Rank, cursor.ID)
+		argPos += 3
 	}
 
-	if query.Source != "" {
-		sql += fmt.Sprintf(" AND source = $%d", argPos)
-		args = append(args, query.Source)
-		argPos++
+	// The near point is only needed to order by distance
+	nearPos := 0
+	if query.SortField() == models.SortDistance && query.Near != nil {
+		args = append(args, query.Near.Lng, query.Near.Lat)
+		nearPos = argPos
+		argPos += 2
 	}
 
-	sql += " ORDER BY posted_at DESC"
+	sql := `SELECT ` + jobColumns + ` FROM jobs WHERE ` + where + " ORDER BY " + orderBy(query, keywordsPos, nearPos, backward)
 
-	// Pagination
-	if query.Limit == 0 {
-		query.Limit = 20
+	// Pagination; a cursor replaces the page offset
+	offset := query.Page * query.Limit
+	if cursor != nil {
+		offset = 0
 	}
 	sql += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argPos, argPos+1)
-	args = append(args, query.Limit, query.Page*query.Limit)
+	args = append(args, limit, offset)
 
 	rows, err := r.db.QueryContext(ctx, sql, args...)
 	if err != nil {
@@ -177,12 +392,7 @@ func (r *JobRepository) Search(ctx context.Context, query *models.JobSearchQuery
 
 	jobs := make([]*models.Job, 0)
 	for rows.Next() {
-		job := &models.Job{}
-		err := rows.Scan(
-			&job.ID, &job.Title, &job.Company, &job.Location, &job.Salary,
-			&job.Description, &job.URL, &job.Source, &job.RemoteOk, &job.JobType,
-			&job.PostedAt, &job.ScrapedAt, &job.CreatedAt, &job.UpdatedAt,
-		)
+		job, err := scanJob(rows)
 		if err != nil {
 			logger.Error("Failed to scan job: %v", err)
 			continue
@@ -190,130 +400,655 @@ func (r *JobRepository) Search(ctx context.Context, query *models.JobSearchQuery
 		jobs = append(jobs, job)
 	}
 
+	if backward {
+		for i, j := 0, len(jobs)-1; i < j; i, j = i+1, j-1 {
+			jobs[i], jobs[j] = jobs[j], jobs[i]
+		}
+	}
+
+	if nearPos > 0 {
+		for _, job := range jobs {
+			if job.Latitude != nil && job.Longitude != nil {
+				d := geo.Distance(*query.Near, geo.Point{Lat: *job.Latitude, Lng: *job.Longitude})
+				job.DistanceKm = &d
+			}
+		}
+	}
+
+	if err := r.attachListings(ctx, jobs); err != nil {
+		return nil, err
+	}
+
+	if query.Keywords != "" {
+		if err := r.attachSnippets(ctx, jobs, query.Keywords); err != nil {
+			return nil, err
+		}
+	}
+
 	return jobs, nil
 }
 
-// GetStats retrieves aggregated job statistics
+// count returns the number of jobs matching a search. Counting stops at
+// exactCountLimit; beyond that the planner's row estimate is returned and
+// exact is false.
+func (r *JobRepository) count(ctx context.Context, query *models.JobSearchQuery) (total int64, exact bool, err error) {
+	where, args, _ := searchFilter(query, "")
+
+	err = r.db.QueryRowContext(ctx, fmt.Sprintf(
+		"SELECT COUNT(*) FROM (SELECT 1 FROM jobs WHERE %s LIMIT %d) matches", where, exactCountLimit+1,
+	), args...).Scan(&total)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to count jobs: %w", err)
+	}
+	if total <= exactCountLimit {
+		return total, true, nil
+	}
+
+	var plan []byte
+	err = r.db.QueryRowContext(ctx, "EXPLAIN (FORMAT JSON) SELECT 1 FROM jobs WHERE "+where, args...).Scan(&plan)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to estimate job count: %w", err)
+	}
+
+	var explain []struct {
+		Plan struct {
+			Rows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal(plan, &explain); err != nil || len(explain) == 0 {
+		return 0, false, fmt.Errorf("failed to parse query plan: %w", err)
+	}
+
+	// The estimate can be below the rows already counted
+	if estimate := int64(explain[0].Plan.Rows); estimate > total {
+		total = estimate
+	}
+	return total, false, nil
+}
+
+// attachSnippets highlights the search keywords in each job's description.
+// It runs on the result page only, since ts_headline is expensive.
+func (r *JobRepository) attachSnippets(ctx context.Context, jobs []*models.Job, keywords string) error {
+	if len(jobs) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(jobs))
+	for i, job := range jobs {
+		ids[i] = job.ID
+	}
+
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT id, ts_headline('english', description, `+tsQuery+`, '%s')
+		FROM jobs
+		WHERE id = ANY($1)
+	`, 2, headlineOptions), pq.Array(ids), keywords)
+	if err != nil {
+		return fmt.Errorf("failed to load search snippets: %w", err)
+	}
+	defer rows.Close()
+
+	snippets := make(map[int64]string, len(jobs))
+	for rows.Next() {
+		var id int64
+		var snippet string
+		if err := rows.Scan(&id, &snippet); err != nil {
+			logger.Error("Failed to scan search snippet: %v", err)
+			continue
+		}
+		snippets[id] = escapeSnippet(snippet)
+	}
+
+	for _, job := range jobs {
+		job.Snippet = snippets[job.ID]
+	}
+	return nil
+}
+
+// escapeSnippet HTML-escapes a headline while keeping its <mark> tags, so
+// descriptions containing markup cannot inject it into clients
+func escapeSnippet(snippet string) string {
+	escaped := html.EscapeString(snippet)
+	escaped = strings.ReplaceAll(escaped, "&lt;mark&gt;", "<mark>")
+	return strings.ReplaceAll(escaped, "&lt;/mark&gt;", "</mark>")
+}
+
+// attachListings loads the source listings of each job's cluster
+func (r *JobRepository) attachListings(ctx context.Context, jobs []*models.Job) error {
+	if len(jobs) == 0 {
+		return nil
+	}
+
+	keys := make([]int64, len(jobs))
+	for i, job := range jobs {
+		keys[i] = job.ID
+		if job.ClusterID != nil {
+			keys[i] = *job.ClusterID
+		}
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT COALESCE(cluster_id, id), id, source, url, posted_at
+		FROM jobs
+		WHERE id = ANY($1) OR cluster_id = ANY($1)
+		ORDER BY posted_at, id
+	`, pq.Array(keys))
+	if err != nil {
+		return fmt.Errorf("failed to load job listings: %w", err)
+	}
+	defer rows.Close()
+
+	listings := make(map[int64][]models.JobListing)
+	for rows.Next() {
+		var key int64
+		var l models.JobListing
+		if err := rows.Scan(&key, &l.JobID, &l.Source, &l.URL, &l.PostedAt); err != nil {
+			logger.Error("Failed to scan job listing: %v", err)
+			continue
+		}
+		listings[key] = append(listings[key], l)
+	}
+
+	for i, job := range jobs {
+		job.Listings = listings[keys[i]]
+	}
+	return nil
+}
+
+// statsTopN is the number of values listed per top-N dimension of the
+// stats; the other dimensions list every value
+var statsTopN = map[string]int{"company": 10, "location": 10, "skill": 25}
+
+// GetStats retrieves job statistics as of the last RefreshStats
 func (r *JobRepository) GetStats(ctx context.Context) (*models.JobStats, error) {
 	stats := &models.JobStats{
-		JobsBySource: make(map[string]int64),
-		JobsByType:   make(map[string]int64),
-		TopCompanies: make([]models.CompanyCount, 0),
-		TopLocations: make([]models.LocationCount, 0),
+		JobsBySource:     make(map[string]int64),
+		JobsByType:       make(map[string]int64),
+		JobsBySeniority:  make(map[string]int64),
+		JobsByRoleFamily: make(map[string]int64),
+		TopCompanies:     make([]models.CompanyCount, 0),
+		TopLocations:     make([]models.LocationCount, 0),
+		TopSkills:        make([]models.SkillCount, 0),
+		JobsByQuality:    qualityCounts(),
 	}
 
-	// Total jobs
-	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM jobs").Scan(&stats.TotalJobs)
+	var lastScrapedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, `
+		SELECT total_jobs, remote_jobs, today_jobs, last_scraped_at, open_jobs, closed_jobs,
+		       avg_days_open, median_days_open, avg_quality, refreshed_at
+		FROM job_stats_summary
+	`).Scan(&stats.TotalJobs, &stats.RemoteJobs, &stats.TodayJobs, &lastScrapedAt, &stats.OpenJobs,
+		&stats.ClosedJobs, &stats.AvgDaysOpen, &stats.MedianDaysOpen, &stats.AvgQuality, &stats.RefreshedAt)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get total jobs: %w", err)
+		return nil, fmt.Errorf("failed to get job stats: %w", err)
 	}
+	stats.LastScrapedAt = lastScrapedAt.Time
 
-	// Jobs by source
-	rows, err := r.db.QueryContext(ctx, "SELECT source, COUNT(*) FROM jobs GROUP BY source")
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT dimension, value, count
+		FROM (
+			SELECT dimension, value, count,
+			       ROW_NUMBER() OVER (PARTITION BY dimension ORDER BY count DESC, value) AS rank
+			FROM job_stats_counts
+		) c
+		WHERE rank <= CASE dimension WHEN 'company' THEN $1 WHEN 'location' THEN $2 WHEN 'skill' THEN $3 ELSE rank END
+		ORDER BY dimension, rank
+	`, statsTopN["company"], statsTopN["location"], statsTopN["skill"])
 	if err != nil {
-		return nil, fmt.Errorf("failed to get jobs by source: %w", err)
+		return nil, fmt.Errorf("failed to get job counts: %w", err)
 	}
 	defer rows.Close()
 
 	for rows.Next() {
-		var source string
+		var dimension, value string
 		var count int64
-		if err := rows.Scan(&source, &count); err == nil {
-			stats.JobsBySource[source] = count
+		if err := rows.Scan(&dimension, &value, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan job count: %w", err)
+		}
+
+		switch dimension {
+		case "source":
+			stats.JobsBySource[value] = count
+		case "job_type":
+			stats.JobsByType[value] = count
+		case "seniority":
+			stats.JobsBySeniority[value] = count
+		case "role_family":
+			stats.JobsByRoleFamily[value] = count
+		case "quality":
+			stats.JobsByQuality[value] = count
+		case "company":
+			stats.TopCompanies = append(stats.TopCompanies, models.CompanyCount{Company: value, Count: count})
+		case "location":
+			stats.TopLocations = append(stats.TopLocations, models.LocationCount{Location: value, Count: count})
+		case "skill":
+			stats.TopSkills = append(stats.TopSkills, models.SkillCount{Skill: value, Count: count})
 		}
 	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read job counts: %w", err)
+	}
+
+	return stats, nil
+}
+
+// qualityCounts returns a zero count for every quality band, so stats
+// list empty bands too
+func qualityCounts() map[string]int64 {
+	counts := make(map[string]int64, len(models.QualityBands))
+	for _, band := range models.QualityBands {
+		counts[band] = 0
+	}
+	return counts
+}
 
-	// Jobs by type
-	rows, err = r.db.QueryContext(ctx, "SELECT job_type, COUNT(*) FROM jobs GROUP BY job_type")
+// RefreshStats recomputes the statistics GetStats returns. The views are
+// refreshed concurrently, so GetStats keeps answering from the previous
+// figures meanwhile.
+func (r *JobRepository) RefreshStats(ctx context.Context) error {
+	for _, view := range []string{"job_stats_counts", "job_stats_summary"} {
+		if _, err := r.db.ExecContext(ctx, "REFRESH MATERIALIZED VIEW CONCURRENTLY "+view); err != nil {
+			return fmt.Errorf("failed to refresh %s: %w", view, err)
+		}
+	}
+	return nil
+}
+
+// History returns the recorded changes of a job, newest first
+func (r *JobRepository) History(ctx context.Context, jobID int64) ([]*models.JobVersion, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, job_id, changed_at, changes
+		FROM job_versions
+		WHERE job_id = $1
+		ORDER BY changed_at DESC, id DESC
+	`, jobID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get jobs by type: %w", err)
+		return nil, fmt.Errorf("failed to get job history: %w", err)
 	}
 	defer rows.Close()
 
+	versions := make([]*models.JobVersion, 0)
 	for rows.Next() {
-		var jobType string
-		var count int64
-		if err := rows.Scan(&jobType, &count); err == nil {
-			stats.JobsByType[jobType] = count
+		v := &models.JobVersion{}
+		var changes []byte
+		if err := rows.Scan(&v.ID, &v.JobID, &v.ChangedAt, &changes); err != nil {
+			logger.Error("Failed to scan job version: %v", err)
+			continue
 		}
+		if err := json.Unmarshal(changes, &v.Changes); err != nil {
+			logger.Error("Failed to decode job version %d: %v", v.ID, err)
+			continue
+		}
+		versions = append(versions, v)
 	}
 
-	// Remote jobs
-	err = r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM jobs WHERE remote_ok = true").Scan(&stats.RemoteJobs)
+	return versions, nil
+}
+
+// MarkMissed counts a missed run for every open job of a source that was
+// not seen since the run started
+func (r *JobRepository) MarkMissed(ctx context.Context, source string, runStart time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE jobs SET missed_runs = missed_runs + 1
+		WHERE source = $1 AND status = 'open' AND last_seen_at < $2
+	`, source, runStart)
 	if err != nil {
-		logger.Error("Failed to get remote jobs count: %v", err)
+		return 0, fmt.Errorf("failed to mark missed jobs: %w", err)
 	}
 
-	// Today's jobs
-	err = r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM jobs WHERE DATE(posted_at) = CURRENT_DATE").Scan(&stats.TodayJobs)
+	count, _ := result.RowsAffected()
+	return count, nil
+}
+
+// ExpiryCandidates returns the open jobs of a source that have been missing
+// for at least minMissed runs
+func (r *JobRepository) ExpiryCandidates(ctx context.Context, source string, minMissed int) ([]*models.Job, error) {
+	query := `SELECT ` + jobColumns + ` FROM jobs WHERE source = $1 AND status = 'open' AND missed_runs >= $2`
+
+	rows, err := r.db.QueryContext(ctx, query, source, minMissed)
 	if err != nil {
-		logger.Error("Failed to get today's jobs count: %v", err)
+		return nil, fmt.Errorf("failed to find expiry candidates: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := make([]*models.Job, 0)
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			logger.Error("Failed to scan job: %v", err)
+			continue
+		}
+		jobs = append(jobs, job)
 	}
 
-	// Last scraped time
-	err = r.db.QueryRowContext(ctx, "SELECT MAX(scraped_at) FROM jobs").Scan(&stats.LastScrapedAt)
+	return jobs, nil
+}
+
+// CloseJobs marks open jobs as closed
+func (r *JobRepository) CloseJobs(ctx context.Context, ids []int64, closedAt time.Time) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE jobs SET status = 'closed', closed_at = $2 WHERE id = ANY($1) AND status = 'open'",
+		pq.Array(ids), closedAt,
+	)
 	if err != nil {
-		logger.Error("Failed to get last scraped time: %v", err)
-	}
-
-	// Top companies
-	rows, err = r.db.QueryContext(ctx, `
-		SELECT company, COUNT(*) as cnt 
-		FROM jobs 
-		GROUP BY company 
-		ORDER BY cnt DESC 
-		LIMIT 10
-	`)
-	if err == nil {
-		defer rows.Close()
-		for rows.Next() {
-			var cc models.CompanyCount
-			if err := rows.Scan(&cc.Company, &cc.Count); err == nil {
-				stats.TopCompanies = append(stats.TopCompanies, cc)
-			}
+		return 0, fmt.Errorf("failed to close jobs: %w", err)
+	}
+
+	count, _ := result.RowsAffected()
+	return count, nil
+}
+
+// ResetMissed clears the missed-run count of jobs confirmed to be live
+func (r *JobRepository) ResetMissed(ctx context.Context, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	if _, err := r.db.ExecContext(ctx, "UPDATE jobs SET missed_runs = 0 WHERE id = ANY($1)", pq.Array(ids)); err != nil {
+		return fmt.Errorf("failed to reset missed runs: %w", err)
+	}
+	return nil
+}
+
+// ClusterCandidate is a stored job that may be a near-duplicate of another
+type ClusterCandidate struct {
+	ID          int64
+	ClusterID   *int64
+	Fingerprint int64
+	CompanyID   *int64
+	Company     string
+}
+
+// JobFingerprint is a stored job's fingerprint and the bands used to look
+// up its near-duplicates
+type JobFingerprint struct {
+	JobID       int64
+	Fingerprint int64
+	Bands       []int
+}
+
+// flattenBands returns the job ID, band number and value of every band of
+// fingerprints as arrays for UNNEST
+func flattenBands(fps []JobFingerprint) (jobIDs, bands, values []int64) {
+	for _, fp := range fps {
+		for band, value := range fp.Bands {
+			jobIDs = append(jobIDs, fp.JobID)
+			bands = append(bands, int64(band))
+			values = append(values, int64(value))
 		}
 	}
+	return jobIDs, bands, values
+}
 
-	// Top locations
-	rows, err = r.db.QueryContext(ctx, `
-		SELECT location, COUNT(*) as cnt 
-		FROM jobs 
-		GROUP BY location 
-		ORDER BY cnt DESC 
-		LIMIT 10
-	`)
-	if err == nil {
-		defer rows.Close()
-		for rows.Next() {
-			var lc models.LocationCount
-			if err := rows.Scan(&lc.Location, &lc.Count); err == nil {
-				stats.TopLocations = append(stats.TopLocations, lc)
-			}
+// SetFingerprints stores the fingerprints and bands of a batch of jobs in
+// one transaction, with a statement each for fingerprints, old bands and
+// new bands
+func (r *JobRepository) SetFingerprints(ctx context.Context, fps []JobFingerprint) error {
+	if len(fps) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(fps))
+	fingerprints := make([]int64, len(fps))
+	for i, fp := range fps {
+		ids[i], fingerprints[i] = fp.JobID, fp.Fingerprint
+	}
+	jobIDs, bands, values := flattenBands(fps)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE jobs SET fingerprint = f.fingerprint
+		FROM UNNEST($1::bigint[], $2::bigint[]) AS f(id, fingerprint)
+		WHERE jobs.id = f.id
+	`, pq.Array(ids), pq.Array(fingerprints)); err != nil {
+		return fmt.Errorf("failed to store fingerprints: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM job_fingerprint_bands WHERE job_id = ANY($1::bigint[])", pq.Array(ids)); err != nil {
+		return fmt.Errorf("failed to clear fingerprint bands: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO job_fingerprint_bands (band, value, job_id)
+		SELECT b.band, b.value, b.job_id
+		FROM UNNEST($1::bigint[], $2::smallint[], $3::int[]) AS b(job_id, band, value)
+		ON CONFLICT DO NOTHING
+	`, pq.Array(jobIDs), pq.Array(bands), pq.Array(values)); err != nil {
+		return fmt.Errorf("failed to store fingerprint bands: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// FindClusterCandidates returns, per job of the batch, the other jobs
+// sharing at least one fingerprint band with it, in one query
+func (r *JobRepository) FindClusterCandidates(ctx context.Context, fps []JobFingerprint) (map[int64][]ClusterCandidate, error) {
+	candidates := make(map[int64][]ClusterCandidate, len(fps))
+	if len(fps) == 0 {
+		return candidates, nil
+	}
+	jobIDs, bands, values := flattenBands(fps)
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT DISTINCT q.job_id, j.id, j.cluster_id, j.fingerprint, j.company_id, j.company
+		FROM UNNEST($1::bigint[], $2::smallint[], $3::int[]) AS q(job_id, band, value)
+		JOIN job_fingerprint_bands b ON b.band = q.band AND b.value = q.value AND b.job_id <> q.job_id
+		JOIN jobs j ON j.id = b.job_id
+	`, pq.Array(jobIDs), pq.Array(bands), pq.Array(values))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find cluster candidates: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var jobID int64
+		var c ClusterCandidate
+		if err := rows.Scan(&jobID, &c.ID, &c.ClusterID, &c.Fingerprint, &c.CompanyID, &c.Company); err != nil {
+			logger.Error("Failed to scan cluster candidate: %v", err)
+			continue
 		}
+		candidates[jobID] = append(candidates[jobID], c)
 	}
 
-	return stats, nil
+	return candidates, rows.Err()
 }
 
-// DeleteOld deletes jobs older than the specified number of days
-func (r *JobRepository) DeleteOld(ctx context.Context, days int) (int64, error) {
-	result, err := r.db.ExecContext(ctx,
-		"DELETE FROM jobs WHERE posted_at < NOW() - INTERVAL '$1 days'",
-		days,
-	)
+// SetClusters assigns a batch of jobs to the clusters of canonical jobs,
+// clusterIDs[i] being the cluster of ids[i]
+func (r *JobRepository) SetClusters(ctx context.Context, ids, clusterIDs []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if _, err := r.db.ExecContext(ctx, `
+		UPDATE jobs SET cluster_id = c.cluster_id
+		FROM UNNEST($1::bigint[], $2::bigint[]) AS c(id, cluster_id)
+		WHERE jobs.id = c.id
+	`, pq.Array(ids), pq.Array(clusterIDs)); err != nil {
+		return fmt.Errorf("failed to set job clusters: %w", err)
+	}
+	return nil
+}
+
+// SetCluster assigns a job to the cluster of a canonical job
+func (r *JobRepository) SetCluster(ctx context.Context, jobID, clusterID int64) error {
+	if _, err := r.db.ExecContext(ctx, "UPDATE jobs SET cluster_id = $2 WHERE id = $1", jobID, clusterID); err != nil {
+		return fmt.Errorf("failed to set job cluster: %w", err)
+	}
+	return nil
+}
+
+// ClusterSources returns for each of the given jobs the number of
+// distinct sources listing its cluster, counting listings of earlier runs
+func (r *JobRepository) ClusterSources(ctx context.Context, ids []int64) (map[int64]int, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT j.id, COUNT(DISTINCT l.source)
+		FROM jobs j
+		JOIN jobs l ON l.id = COALESCE(j.cluster_id, j.id) OR l.cluster_id = COALESCE(j.cluster_id, j.id)
+		WHERE j.id = ANY($1)
+		GROUP BY j.id
+	`, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to count cluster sources: %w", err)
+	}
+	defer rows.Close()
+
+	sources := make(map[int64]int, len(ids))
+	for rows.Next() {
+		var id int64
+		var count int
+		if err := rows.Scan(&id, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan cluster sources: %w", err)
+		}
+		sources[id] = count
+	}
+	return sources, rows.Err()
+}
+
+// SetQuality stores the quality scores and reasons of stored jobs
+func (r *JobRepository) SetQuality(ctx context.Context, jobs []*models.Job) error {
+	ids := make([]int64, len(jobs))
+	scores := make([]int64, len(jobs))
+	reasons := make([]string, len(jobs))
+	for i, job := range jobs {
+		data, err := jsonList[models.QualityReason](job.QualityReasons).Value()
+		if err != nil {
+			return err
+		}
+		ids[i], scores[i], reasons[i] = job.ID, int64(job.QualityScore), data.(string)
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE jobs SET quality_score = q.score, quality_reasons = q.reasons::jsonb
+		FROM UNNEST($1::bigint[], $2::int[], $3::text[]) AS q(id, score, reasons)
+		WHERE jobs.id = q.id
+	`, pq.Array(ids), pq.Array(scores), pq.Array(reasons))
+	if err != nil {
+		return fmt.Errorf("failed to store quality scores: %w", err)
+	}
+	return nil
+}
+
+// expiredWhere returns the conditions selecting the jobs matching a
+// retention rule whose age column is before cutoff, and their arguments
+func expiredWhere(rule *models.RetentionRule, cutoff time.Time) (string, []interface{}) {
+	conditions := []string{pq.QuoteIdentifier(rule.AgeColumn()) + " < $1"}
+	args := []interface{}{cutoff}
+	if rule.Source != "" {
+		args = append(args, rule.Source)
+		conditions = append(conditions, fmt.Sprintf("source = $%d", len(args)))
+	}
+	if rule.Status != "" {
+		args = append(args, rule.Status)
+		conditions = append(conditions, fmt.Sprintf("status = $%d", len(args)))
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// CountExpired counts the jobs matching a retention rule whose age column
+// is before cutoff
+func (r *JobRepository) CountExpired(ctx context.Context, rule *models.RetentionRule, cutoff time.Time) (int64, error) {
+	where, args := expiredWhere(rule, cutoff)
+
+	var count int64
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM jobs WHERE "+where, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count expired jobs: %w", err)
+	}
+	return count, nil
+}
+
+// DeleteExpired deletes at most limit of the jobs CountExpired counts.
+// Jobs are deleted through job_keys so their tags and versions go with
+// them; each call is a short transaction of its own. Clusters whose
+// canonical job is deleted move to their earliest surviving listing, so
+// their other listings are not left as canonical jobs of their own.
+func (r *JobRepository) DeleteExpired(ctx context.Context, rule *models.RetentionRule, cutoff time.Time, limit int) (int64, error) {
+	where, args := expiredWhere(rule, cutoff)
+	args = append(args, limit)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf("SELECT id FROM jobs WHERE %s LIMIT $%d", where, len(args)), args...)
 	if err != nil {
-		return 0, fmt.Errorf("failed to delete old jobs: %w", err)
+		return 0, fmt.Errorf("failed to select expired jobs: %w", err)
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan expired job: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to select expired jobs: %w", err)
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	if err := moveClusters(ctx, tx, ids); err != nil {
+		return 0, err
+	}
+
+	result, err := tx.ExecContext(ctx, "DELETE FROM job_keys WHERE id = ANY($1::bigint[])", pq.Array(ids))
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired jobs: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
 	count, _ := result.RowsAffected()
 	return count, nil
 }
 
+// moveClusters hands the clusters headed by jobs about to be deleted to
+// their earliest remaining listing. Otherwise ON DELETE SET NULL would
+// turn every remaining listing into a canonical job of its own.
+func moveClusters(ctx context.Context, tx *sql.Tx, ids []int64) error {
+	// The new canonical job of a cluster points to itself, like the old one
+	_, err := tx.ExecContext(ctx, `
+		WITH heads AS (
+			SELECT DISTINCT ON (m.cluster_id) m.cluster_id AS old_id, m.id AS new_id
+			FROM jobs m
+			WHERE m.cluster_id = ANY($1::bigint[]) AND m.id <> ALL($1::bigint[])
+			ORDER BY m.cluster_id, m.id
+		)
+		UPDATE jobs SET cluster_id = heads.new_id
+		FROM heads
+		WHERE jobs.cluster_id = heads.old_id AND jobs.id <> ALL($1::bigint[])
+	`, pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("failed to move clusters of deleted jobs: %w", err)
+	}
+	return nil
+}
+
 // ExistsByHash checks if a job with the given hash already exists
 func (r *JobRepository) ExistsByHash(ctx context.Context, hash string) (bool, error) {
 	var exists bool
 	err := r.db.QueryRowContext(ctx,
-		"SELECT EXISTS(SELECT 1 FROM jobs WHERE hash = $1)",
+		"SELECT EXISTS(SELECT 1 FROM job_keys WHERE hash = $1)",
 		hash,
 	).Scan(&exists)
 
@@ -323,3 +1058,47 @@ func (r *JobRepository) ExistsByHash(ctx context.Context, hash string) (bool, er
 
 	return exists, nil
 }
+
+// ReviewFlag records an admin's review of a job's flag and returns the job
+func (r *JobRepository) ReviewFlag(ctx context.Context, id int64, review string) (*models.Job, error) {
+	result, err := r.db.ExecContext(ctx, "UPDATE jobs SET flag_review = $2 WHERE id = $1", id, review)
+	if err != nil {
+		return nil, fmt.Errorf("failed to review job flag: %w", err)
+	}
+	if count, _ := result.RowsAffected(); count == 0 {
+		return nil, ErrJobNotFound
+	}
+	return r.FindByID(ctx, id)
+}
+
+// execer is satisfied by both *sql.DB and *sql.Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// queryer is satisfied by both *sql.DB and *sql.Tx
+type queryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// replaceTags overwrites the stored skill tags of a job
+func replaceTags(ctx context.Context, db execer, jobID int64, tags []string) error {
+	if _, err := db.ExecContext(ctx, "DELETE FROM job_tags WHERE job_id = $1", jobID); err != nil {
+		return fmt.Errorf("failed to clear job tags: %w", err)
+	}
+
+	if len(tags) == 0 {
+		return nil
+	}
+
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO job_tags (job_id, tag)
+		SELECT $1, UNNEST($2::text[])
+		ON CONFLICT DO NOTHING
+	`, jobID, pq.Array(tags))
+	if err != nil {
+		return fmt.Errorf("failed to store job tags: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/abhisheksainimitawa/job-aggregator/internal/models"
+	"github.com/lib/pq"
+)
+
+// salaryGroupColumns maps salary grouping fields to their columns, as
+// text, on every SQL store
+var salaryGroupColumns = map[string]string{
+	"normalized_title": "jobs.normalized_title",
+	"seniority":        "jobs.seniority",
+	"location":         "jobs.location",
+	"remote_ok":        "CASE WHEN jobs.remote_ok THEN 'true' ELSE 'false' END",
+	"company":          "jobs.company",
+}
+
+// salaryPercentiles are the percentiles reported by SalaryStats
+var salaryPercentiles = []float64{0.1, 0.25, 0.5, 0.75, 0.9}
+
+// salarySample is the salary of one job with its group
+type salarySample struct {
+	group    []string // Values of the query's GroupBy fields
+	currency string
+	min, max *int64
+}
+
+// midpoint returns the middle of the sample's salary range
+func (s salarySample) midpoint() float64 {
+	lo, hi := s.min, s.max
+	if lo == nil {
+		lo = hi
+	}
+	if hi == nil {
+		hi = lo
+	}
+	return float64(*lo+*hi) / 2
+}
+
+// percentile interpolates the p-th percentile of sorted values like
+// Postgres' percentile_cont
+func percentile(sorted []float64, p float64) float64 {
+	rank := p * float64(len(sorted)-1)
+	lo := int(rank)
+	if lo+1 >= len(sorted) {
+		return sorted[lo]
+	}
+	return sorted[lo] + (rank-float64(lo))*(sorted[lo+1]-sorted[lo])
+}
+
+// newSalaryStats returns the stats of a group from its percentiles
+func newSalaryStats(fields, group []string, currency string, samples int64, p []float64) models.SalaryStats {
+	stats := models.SalaryStats{
+		Group:    make(map[string]string, len(fields)),
+		Currency: currency,
+		Samples:  samples,
+		P10:      int64(math.Round(p[0])),
+		P25:      int64(math.Round(p[1])),
+		Median:   int64(math.Round(p[2])),
+		P75:      int64(math.Round(p[3])),
+		P90:      int64(math.Round(p[4])),
+	}
+	for i, field := range fields {
+		stats.Group[field] = group[i]
+	}
+	return stats
+}
+
+// summarizeSalaries groups salary samples and summarizes each group, as
+// the Postgres SalaryStats query does
+func summarizeSalaries(query *models.SalaryStatsQuery, samples []salarySample) []models.SalaryStats {
+	type bucket struct {
+		group    []string
+		currency string
+		values   []float64
+	}
+	buckets := make(map[string]*bucket)
+	for _, s := range samples {
+		key := strings.Join(append(append([]string{}, s.group...), s.currency), "\x00")
+		b, ok := buckets[key]
+		if !ok {
+			b = &bucket{group: s.group, currency: s.currency}
+			buckets[key] = b
+		}
+		b.values = append(b.values, s.midpoint())
+	}
+
+	var kept []*bucket
+	for _, b := range buckets {
+		if len(b.values) >= query.MinSamples {
+			kept = append(kept, b)
+		}
+	}
+	sort.Slice(kept, func(i, j int) bool {
+		a, b := kept[i], kept[j]
+		if len(a.values) != len(b.values) {
+			return len(a.values) > len(b.values)
+		}
+		for k := range a.group {
+			if a.group[k] != b.group[k] {
+				return a.group[k] < b.group[k]
+			}
+		}
+		return a.currency < b.currency
+	})
+	if query.Limit > 0 && len(kept) > query.Limit {
+		kept = kept[:query.Limit]
+	}
+
+	stats := make([]models.SalaryStats, 0, len(kept))
+	for _, b := range kept {
+		sort.Float64s(b.values)
+		p := make([]float64, len(salaryPercentiles))
+		for i, q := range salaryPercentiles {
+			p[i] = percentile(b.values, q)
+		}
+		stats = append(stats, newSalaryStats(query.GroupBy, b.group, b.currency, int64(len(b.values)), p))
+	}
+	return stats
+}
+
+// salaryGroupSelect returns the grouping columns of a salary query
+func salaryGroupSelect(query *models.SalaryStatsQuery) (string, error) {
+	var columns []string
+	for _, field := range query.GroupBy {
+		column, ok := salaryGroupColumns[field]
+		if !ok {
+			return "", fmt.Errorf("unknown salary grouping %q", field)
+		}
+		columns = append(columns, column+" AS "+pq.QuoteIdentifier(field))
+	}
+	columns = append(columns, "COALESCE(jobs.salary_currency, '') AS currency")
+	return strings.Join(columns, ", "), nil
+}
+
+// SalaryStats computes salary percentiles of the canonical jobs matching
+// the query's filter per group and currency, largest groups first
+func (r *JobRepository) SalaryStats(ctx context.Context, query *models.SalaryStatsQuery) ([]models.SalaryStats, error) {
+	columns, err := salaryGroupSelect(query)
+	if err != nil {
+		return nil, err
+	}
+	where, args, _ := searchFilter(query.Filter, "")
+
+	keys := len(query.GroupBy) + 1
+	positions := make([]string, keys)
+	for i := range positions {
+		positions[i] = strconv.Itoa(i + 1)
+	}
+	groupBy := strings.Join(positions, ", ")
+
+	limit := ""
+	if query.Limit > 0 {
+		limit = fmt.Sprintf("LIMIT %d", query.Limit)
+	}
+	args = append(args, query.MinSamples, pq.Array(salaryPercentiles))
+
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT %s, COUNT(*),
+		       percentile_cont($%d::float8[]) WITHIN GROUP (
+		           ORDER BY (COALESCE(jobs.salary_min, jobs.salary_max) + COALESCE(jobs.salary_max, jobs.salary_min)) / 2.0
+		       )
+		FROM jobs
+		WHERE %s AND (jobs.salary_min IS NOT NULL OR jobs.salary_max IS NOT NULL)
+		GROUP BY %s
+		HAVING COUNT(*) >= $%d
+		ORDER BY %d DESC, %s
+		%s
+	`, columns, len(args), where, groupBy, len(args)-1, keys+1, groupBy, limit), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute salary stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := make([]models.SalaryStats, 0)
+	for rows.Next() {
+		group := make([]string, len(query.GroupBy))
+		var currency string
+		var samples int64
+		var p pq.Float64Array
+
+		dest := make([]interface{}, 0, keys+2)
+		for i := range group {
+			dest = append(dest, &group[i])
+		}
+		dest = append(dest, &currency, &samples, &p)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to scan salary stats: %w", err)
+		}
+		stats = append(stats, newSalaryStats(query.GroupBy, group, currency, samples, p))
+	}
+	return stats, rows.Err()
+}
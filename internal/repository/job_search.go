@@ -0,0 +1,264 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/abhisheksainimitawa/job-aggregator/internal/models"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/querylang"
+	"github.com/abhisheksainimitawa/job-aggregator/pkg/logger"
+	"github.com/lib/pq"
+)
+
+// facetLimit caps the values returned per facet; high-cardinality facets
+// like location and company return their most frequent values
+const facetLimit = 20
+
+// facetDef describes how a facet groups canonical jobs
+type facetDef struct {
+	value string // grouped expression, cast to text
+	label string // aggregate display expression, "" if the value is the label
+	join  string // extra join needed by value and label
+	count string // counted expression
+}
+
+// facetDefs are the supported facets keyed by models.FacetFields entry
+var facetDefs = map[string]facetDef{
+	"source": {
+		// A cluster counts once for every source it was posted on
+		value: "l.source",
+		join:  "JOIN jobs l ON (l.id = jobs.id OR l.cluster_id = jobs.id)",
+		count: "COUNT(DISTINCT jobs.id)",
+	},
+	"job_type":    {value: "jobs.job_type", count: "COUNT(*)"},
+	"remote_ok":   {value: "jobs.remote_ok::text", count: "COUNT(*)"},
+	"location":    {value: "jobs.location", count: "COUNT(*)"},
+	"seniority":   {value: "jobs.seniority", count: "COUNT(*)"},
+	"role_family": {value: "jobs.role_family", count: "COUNT(*)"},
+	"company": {
+		value: "c.id::text",
+		label: "MAX(c.name)",
+		join:  "JOIN companies c ON c.id = jobs.company_id",
+		count: "COUNT(*)",
+	},
+	"skills": {
+		value: "t.tag",
+		join:  "JOIN job_tags t ON t.job_id = jobs.id",
+		count: "COUNT(*)",
+	},
+}
+
+// searchFilter builds the WHERE clause matching canonical jobs for a
+// search query, leaving out the filter named skip so a facet can count
+// the values its own filter would otherwise hide. keywordsPos is the
+// parameter holding the keywords, 0 if there are none.
+func searchFilter(query *models.JobSearchQuery, skip string) (where string, args []interface{}, keywordsPos int) {
+	where = "(jobs.cluster_id IS NULL OR jobs.cluster_id = jobs.id)"
+	argPos := 1
+
+	if query.Keywords != "" {
+		where += fmt.Sprintf(" AND jobs.search_vector @@ "+tsQuery, argPos)
+		args = append(args, query.Keywords)
+		keywordsPos = argPos
+		argPos++
+	}
+
+	if query.Location != "" && skip != "location" {
+		where += fmt.Sprintf(" AND jobs.location ILIKE $%d", argPos)
+		args = append(args, "%"+query.Location+"%")
+		argPos++
+	}
+
+	if query.Remote != nil && skip != "remote_ok" {
+		where += fmt.Sprintf(" AND jobs.remote_ok = $%d", argPos)
+		args = append(args, *query.Remote)
+		argPos++
+	}
+
+	if query.JobType != "" && skip != "job_type" {
+		where += fmt.Sprintf(" AND jobs.job_type = $%d", argPos)
+		args = append(args, query.JobType)
+		argPos++
+	}
+
+	// A cluster matches a source if any of its listings came from it
+	if query.Source != "" && skip != "source" {
+		where += fmt.Sprintf(` AND EXISTS (
+			SELECT 1 FROM jobs sl WHERE (sl.id = jobs.id OR sl.cluster_id = jobs.id) AND sl.source = $%d
+		)`, argPos)
+		args = append(args, query.Source)
+		argPos++
+	}
+
+	if query.CompanyID != 0 && skip != "company" {
+		where += fmt.Sprintf(" AND jobs.company_id = $%d", argPos)
+		args = append(args, query.CompanyID)
+		argPos++
+	}
+
+	if query.Seniority != "" && skip != "seniority" {
+		where += fmt.Sprintf(" AND jobs.seniority = $%d", argPos)
+		args = append(args, query.Seniority)
+		argPos++
+	}
+
+	if query.RoleFamily != "" && skip != "role_family" {
+		where += fmt.Sprintf(" AND jobs.role_family = $%d", argPos)
+		args = append(args, query.RoleFamily)
+		argPos++
+	}
+
+	// Salary changed on any listing of the cluster since the given time
+	if !query.SalaryChangedSince.IsZero() {
+		where += fmt.Sprintf(` AND EXISTS (
+			SELECT 1 FROM job_versions v JOIN jobs vl ON vl.id = v.job_id
+			WHERE (vl.id = jobs.id OR vl.cluster_id = jobs.id)
+			  AND 'salary' = ANY(v.changed_fields) AND v.changed_at >= $%d
+		)`, argPos)
+		args = append(args, query.SalaryChangedSince)
+		argPos++
+	}
+
+	if query.MinSalary > 0 {
+		where += fmt.Sprintf(" AND jobs.salary_max >= $%d", argPos)
+		args = append(args, query.MinSalary)
+		argPos++
+	}
+
+	if query.MinQuality > 0 {
+		where += fmt.Sprintf(" AND jobs.quality_score >= $%d", argPos)
+		args = append(args, query.MinQuality)
+		argPos++
+	}
+
+	if !query.PostedAfter.IsZero() {
+		where += fmt.Sprintf(" AND jobs.posted_at >= $%d", argPos)
+		args = append(args, query.PostedAfter)
+		argPos++
+	}
+
+	if !query.PostedBefore.IsZero() {
+		where += fmt.Sprintf(" AND jobs.posted_at < $%d", argPos)
+		args = append(args, query.PostedBefore)
+		argPos++
+	}
+
+	if !query.UpdatedSince.IsZero() {
+		where += fmt.Sprintf(" AND jobs.updated_at >= $%d", argPos)
+		args = append(args, query.UpdatedSince)
+		argPos++
+	}
+
+	if query.Expr != nil {
+		sql, exprArgs := querylang.Compile(query.Expr, argPos)
+		where += " AND " + sql
+		args = append(args, exprArgs...)
+		argPos += len(exprArgs)
+	}
+
+	// A cluster is open while any of its listings is open. Checking the
+	// canonical job first skips the subquery for most rows.
+	switch query.Status {
+	case "", models.JobStatusOpen:
+		where += ` AND (jobs.status = 'open' OR EXISTS (
+			SELECT 1 FROM jobs ol WHERE ol.cluster_id = jobs.id AND ol.status = 'open'
+		))`
+	case models.JobStatusClosed:
+		where += ` AND jobs.status <> 'open' AND NOT EXISTS (
+			SELECT 1 FROM jobs ol WHERE (ol.id = jobs.id OR ol.cluster_id = jobs.id) AND ol.status = 'open'
+		)`
+	}
+
+	if cond := flaggedCondition(query.Flagged, "jobs."); cond != "" {
+		where += " AND " + cond
+	}
+
+	// All requested skills must be present
+	if len(query.Skills) > 0 && skip != "skills" {
+		where += fmt.Sprintf(` AND jobs.id IN (
+			SELECT job_id FROM job_tags WHERE tag = ANY($%d)
+			GROUP BY job_id HAVING COUNT(*) = $%d
+		)`, argPos, argPos+1)
+		args = append(args, pq.Array(query.Skills), len(query.Skills))
+		argPos += 2
+	}
+
+	// At least one of the requested skills must be present
+	if len(query.SkillsAny) > 0 && skip != "skills" {
+		where += fmt.Sprintf(" AND EXISTS (SELECT 1 FROM job_tags st WHERE st.job_id = jobs.id AND st.tag = ANY($%d))", argPos)
+		args = append(args, pq.Array(query.SkillsAny))
+	}
+
+	return where, args, keywordsPos
+}
+
+// Facets counts canonical jobs per value of each requested facet under the
+// query's filters. Each facet ignores its own filter, so a UI can show how
+// many jobs selecting another value would return.
+func (r *JobRepository) Facets(ctx context.Context, query *models.JobSearchQuery, fields []string) (map[string][]models.FacetCount, error) {
+	for _, field := range fields {
+		if _, ok := facetDefs[field]; !ok {
+			return nil, fmt.Errorf("unknown facet %q", field)
+		}
+	}
+
+	// Each facet is an independent aggregate, so they run concurrently
+	results := make([][]models.FacetCount, len(fields))
+	errs := make([]error, len(fields))
+	var wg sync.WaitGroup
+
+	for i, field := range fields {
+		wg.Add(1)
+		go func(i int, field string) {
+			defer wg.Done()
+
+			def := facetDefs[field]
+			where, args, _ := searchFilter(query, field)
+			label := def.label
+			if label == "" {
+				label = "''"
+			}
+
+			sql := fmt.Sprintf(`
+				SELECT %s, %s, %s FROM jobs %s
+				WHERE %s AND %s <> ''
+				GROUP BY 1
+				ORDER BY 3 DESC, 1
+				LIMIT %d
+			`, def.value, label, def.count, def.join, where, def.value, facetLimit)
+
+			results[i], errs[i] = r.facetCounts(ctx, sql, args)
+		}(i, field)
+	}
+	wg.Wait()
+
+	facets := make(map[string][]models.FacetCount, len(fields))
+	for i, field := range fields {
+		if errs[i] != nil {
+			return nil, fmt.Errorf("failed to count %s facet: %w", field, errs[i])
+		}
+		facets[field] = results[i]
+	}
+
+	return facets, nil
+}
+
+func (r *JobRepository) facetCounts(ctx context.Context, sql string, args []interface{}) ([]models.FacetCount, error) {
+	rows, err := r.db.QueryContext(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make([]models.FacetCount, 0)
+	for rows.Next() {
+		var fc models.FacetCount
+		if err := rows.Scan(&fc.Value, &fc.Label, &fc.Count); err != nil {
+			logger.Error("Failed to scan facet count: %v", err)
+			continue
+		}
+		counts = append(counts, fc)
+	}
+	return counts, rows.Err()
+}
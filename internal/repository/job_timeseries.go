@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/abhisheksainimitawa/job-aggregator/internal/models"
+	"github.com/lib/pq"
+)
+
+// postingSpan is when a job was posted and the period it was listed
+type postingSpan struct {
+	posted time.Time
+	from   time.Time // Earlier of posting and first sighting
+	until  time.Time // Closing, last sighting of a closed job, or now
+}
+
+// newPostingSpan returns the span of a job
+func newPostingSpan(posted, firstSeen, lastSeen time.Time, closedAt *time.Time, status string, now time.Time) postingSpan {
+	span := postingSpan{posted: posted, from: posted, until: now}
+	if firstSeen.Before(span.from) {
+		span.from = firstSeen
+	}
+	switch {
+	case closedAt != nil:
+		span.until = *closedAt
+	case status != models.JobStatusOpen:
+		span.until = lastSeen
+	}
+	return span
+}
+
+// countTimeSeries counts new and active postings per bucket, as the
+// Postgres TimeSeries query does: each span adds one to the bucket it was
+// posted in, and +1/-1 events at its first and after its last active bucket
+// are summed up
+func countTimeSeries(bounds []time.Time, spans []postingSpan) []models.TimeSeriesPoint {
+	n := len(bounds) - 1
+	first, last := bounds[0], bounds[n]
+
+	// bucket returns the number of bucket starts at or before t, the
+	// 1-based bucket of t when it lies within the series
+	bucket := func(t time.Time) int {
+		return sort.Search(n, func(i int) bool { return bounds[i].After(t) })
+	}
+
+	points := make([]models.TimeSeriesPoint, n)
+	deltas := make([]int64, n+1)
+	for _, span := range spans {
+		if !span.posted.Before(first) && span.posted.Before(last) {
+			points[bucket(span.posted)-1].New++
+		}
+		if span.from.Before(last) && !span.until.Before(first) {
+			from, until := bucket(span.from), bucket(span.until)
+			if from < 1 {
+				from = 1
+			}
+			if from <= until {
+				deltas[from-1]++
+				deltas[until]--
+			}
+		}
+	}
+
+	var active int64
+	for i := range points {
+		active += deltas[i]
+		points[i].Start = bounds[i]
+		points[i].Active = active
+	}
+	return points
+}
+
+// timestampArray returns times as a text array for a ::timestamp[] cast
+func timestampArray(times []time.Time) interface{} {
+	values := make([]string, len(times))
+	for i, t := range times {
+		values[i] = t.UTC().Format("2006-01-02 15:04:05.999999")
+	}
+	return pq.Array(values)
+}
+
+// TimeSeries counts the canonical jobs matching the query's filter that
+// were posted, and that were listed, during each bucket
+func (r *JobRepository) TimeSeries(ctx context.Context, query *models.TimeSeriesQuery) ([]models.TimeSeriesPoint, error) {
+	bounds := query.Bounds()
+	where, args, _ := searchFilter(query.Filter, "")
+	first, last := len(args)+1, len(args)+2
+	starts := len(args) + 3
+	args = append(args, bounds[0], bounds[len(bounds)-1], timestampArray(bounds[:len(bounds)-1]))
+
+	// Spans outside the series are left out. width_bucket finds the bucket
+	// of a time among the bucket starts, so new postings are one GROUP BY
+	// and active postings a running sum of +1 at a span's first and -1
+	// after its last active bucket.
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`
+		WITH spans AS (
+			SELECT posted_at,
+			       active_from < $%[3]d AND active_until >= $%[2]d AS active,
+			       GREATEST(width_bucket(active_from, $%[4]d::timestamp[]), 1) AS first_bucket,
+			       width_bucket(active_until, $%[4]d::timestamp[]) AS last_bucket
+			FROM (
+				SELECT jobs.posted_at,
+				       LEAST(jobs.posted_at, jobs.first_seen_at) AS active_from,
+				       COALESCE(jobs.closed_at, CASE WHEN jobs.status = 'open' THEN NOW()::timestamp ELSE jobs.last_seen_at END) AS active_until
+				FROM jobs
+				WHERE %[1]s
+			) s
+			WHERE (active_from < $%[3]d AND active_until >= $%[2]d) OR (posted_at >= $%[2]d AND posted_at < $%[3]d)
+		),
+		events AS (
+			SELECT width_bucket(posted_at, $%[4]d::timestamp[]) AS bucket, 1 AS new, 0 AS delta
+			FROM spans WHERE posted_at >= $%[2]d AND posted_at < $%[3]d
+			UNION ALL
+			SELECT first_bucket, 0, 1 FROM spans WHERE active AND first_bucket <= last_bucket
+			UNION ALL
+			SELECT last_bucket + 1, 0, -1 FROM spans WHERE active AND first_bucket <= last_bucket
+		),
+		counts AS (
+			SELECT bucket, SUM(new) AS new, SUM(delta) AS delta FROM events GROUP BY bucket
+		)
+		SELECT b.start, COALESCE(c.new, 0), (SUM(COALESCE(c.delta, 0)) OVER (ORDER BY b.i))::bigint
+		FROM unnest($%[4]d::timestamp[]) WITH ORDINALITY AS b(start, i)
+		LEFT JOIN counts c ON c.bucket = b.i
+		ORDER BY b.i
+	`, where, first, last, starts), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count time series: %w", err)
+	}
+	defer rows.Close()
+
+	points := make([]models.TimeSeriesPoint, 0, len(bounds)-1)
+	for rows.Next() {
+		var p models.TimeSeriesPoint
+		if err := rows.Scan(&p.Start, &p.New, &p.Active); err != nil {
+			return nil, fmt.Errorf("failed to scan time series: %w", err)
+		}
+		p.Start = p.Start.UTC()
+		points = append(points, p)
+	}
+	return points, rows.Err()
+}
@@ -0,0 +1,587 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/abhisheksainimitawa/job-aggregator/internal/models"
+)
+
+// MemoryJobStore is a JobStore kept in process memory, for tests and
+// running the API without a database. Jobs are copied in and out, so
+// callers never share them with the store.
+type MemoryJobStore struct {
+	mu         sync.RWMutex
+	jobs       map[int64]*models.Job
+	byHash     map[string]int64
+	byNativeID map[string]int64
+	nextID     int64
+}
+
+// NewMemoryJobStore creates a new, empty in-memory job store
+func NewMemoryJobStore() *MemoryJobStore {
+	return &MemoryJobStore{
+		jobs:       make(map[int64]*models.Job),
+		byHash:     make(map[string]int64),
+		byNativeID: make(map[string]int64),
+	}
+}
+
+// Create inserts a new job
+func (s *MemoryJobStore) Create(ctx context.Context, job *models.Job) error {
+	if reason := rejectReason(job); reason != "" {
+		return fmt.Errorf("failed to create job: %s", reason)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.byHash[job.Hash]; ok {
+		return fmt.Errorf("failed to create job: duplicate hash %q", job.Hash)
+	}
+	if key := nativeKey(job); key != "" {
+		if _, ok := s.byNativeID[key]; ok {
+			return fmt.Errorf("failed to create job: duplicate native ID %q", job.NativeID)
+		}
+	}
+
+	s.insert(job, time.Now())
+	return nil
+}
+
+// insert stores a copy of a new job and sets its ID
+func (s *MemoryJobStore) insert(job *models.Job, now time.Time) {
+	s.nextID++
+	job.ID = s.nextID
+
+	stored := cloneJob(job)
+	stored.CreatedAt, stored.UpdatedAt = now, now
+	stored.FirstSeenAt, stored.LastSeenAt = job.ScrapedAt, job.ScrapedAt
+	stored.Status = models.JobStatusOpen
+	stored.ClosedAt = nil
+	stored.ClusterID = nil
+	stored.Listings = nil
+	stored.FlagReview = ""
+
+	s.jobs[stored.ID] = stored
+	s.byHash[stored.Hash] = stored.ID
+	if key := nativeKey(stored); key != "" {
+		s.byNativeID[key] = stored.ID
+	}
+}
+
+// CreateBatch inserts or updates multiple jobs, with the same matching,
+// rejections and updated fields as JobRepository.CreateBatch
+func (s *MemoryJobStore) CreateBatch(ctx context.Context, jobs []*models.Job) (*models.BatchResult, error) {
+	accepted, rejected := screenBatch(jobs)
+	result := &models.BatchResult{Rejected: rejected}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for _, row := range accepted {
+		job := jobs[row]
+		id, ok := s.byHash[job.Hash]
+		if key := nativeKey(job); key != "" {
+			taken, hashUsed := id, ok
+			if id, ok = s.byNativeID[key]; !ok && hashUsed {
+				// A job stored before its board exposed IDs adopts this one
+				if owner := s.jobs[taken]; owner.Source == job.Source && owner.NativeID == "" {
+					id, ok = taken, true
+				}
+			}
+			if !ok && hashUsed {
+				result.Rejected = append(result.Rejected, models.BatchRejection{
+					Row: row, Hash: job.Hash, Reason: fmt.Sprintf(hashTakenReason, taken),
+				})
+				continue
+			}
+		}
+		if !ok {
+			s.insert(job, now)
+			result.Inserted++
+			continue
+		}
+
+		stored := s.jobs[id]
+		update := cloneJob(job)
+
+		// Identity, first sighting, clustering and flag reviews survive
+		// re-scrapes
+		update.ID = stored.ID
+		update.Hash = stored.Hash
+		update.Source = stored.Source
+		if stored.NativeID != "" {
+			update.NativeID = stored.NativeID
+		} else if key := nativeKey(update); key != "" {
+			s.byNativeID[key] = id
+		}
+		update.PostedAt = stored.PostedAt
+		update.PostedAtPrecision = stored.PostedAtPrecision
+		update.CreatedAt = stored.CreatedAt
+		update.FirstSeenAt = stored.FirstSeenAt
+		update.ClusterID = stored.ClusterID
+		update.Fingerprint = stored.Fingerprint
+		update.FlagReview = stored.FlagReview
+
+		update.UpdatedAt = now
+		update.LastSeenAt = job.ScrapedAt
+		update.Status = models.JobStatusOpen
+		update.ClosedAt = nil
+		update.Listings = nil
+
+		s.jobs[id] = update
+		job.ID, job.ClusterID = stored.ID, stored.ClusterID
+		result.Updated++
+	}
+
+	sortRejections(result.Rejected)
+	return result, nil
+}
+
+// FindByID retrieves a job by ID
+func (s *MemoryJobStore) FindByID(ctx context.Context, id int64) (*models.Job, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	return cloneJob(job), nil
+}
+
+// Search searches for jobs based on query parameters
+func (s *MemoryJobStore) Search(ctx context.Context, query *models.JobSearchQuery) ([]*models.Job, error) {
+	if err := checkPortable(query); err != nil {
+		return nil, err
+	}
+	if query.Limit == 0 {
+		query.Limit = 20
+	}
+
+	s.mu.RLock()
+	matches := make([]*models.Job, 0)
+	for _, job := range s.jobs {
+		if memoryMatches(job, query) {
+			matches = append(matches, job)
+		}
+	}
+
+	sortJobs(matches, query)
+
+	jobs := make([]*models.Job, 0, query.Limit)
+	for i := query.Page * query.Limit; i >= 0 && i < len(matches) && len(jobs) < query.Limit; i++ {
+		jobs = append(jobs, cloneJob(matches[i]))
+	}
+	s.mu.RUnlock()
+
+	return jobs, nil
+}
+
+// TimeSeries counts the jobs matching the query's filter that were
+// posted, and that were listed, during each bucket
+func (s *MemoryJobStore) TimeSeries(ctx context.Context, query *models.TimeSeriesQuery) ([]models.TimeSeriesPoint, error) {
+	if err := checkPortable(query.Filter); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	now := time.Now()
+	var spans []postingSpan
+	for _, job := range s.jobs {
+		if memoryMatches(job, query.Filter) {
+			spans = append(spans, newPostingSpan(job.PostedAt, job.FirstSeenAt, job.LastSeenAt, job.ClosedAt, job.Status, now))
+		}
+	}
+	s.mu.RUnlock()
+
+	return countTimeSeries(query.Bounds(), spans), nil
+}
+
+// SalaryStats computes salary percentiles of the jobs matching the
+// query's filter per group and currency
+func (s *MemoryJobStore) SalaryStats(ctx context.Context, query *models.SalaryStatsQuery) ([]models.SalaryStats, error) {
+	if err := checkPortable(query.Filter); err != nil {
+		return nil, err
+	}
+	for _, field := range query.GroupBy {
+		if !models.IsSalaryGroupField(field) {
+			return nil, fmt.Errorf("unknown salary grouping %q", field)
+		}
+	}
+
+	s.mu.RLock()
+	var samples []salarySample
+	for _, job := range s.jobs {
+		if (job.SalaryMin == nil && job.SalaryMax == nil) || !memoryMatches(job, query.Filter) {
+			continue
+		}
+		sample := salarySample{currency: job.SalaryCurrency, min: job.SalaryMin, max: job.SalaryMax}
+		for _, field := range query.GroupBy {
+			sample.group = append(sample.group, salaryGroupValue(job, field))
+		}
+		samples = append(samples, sample)
+	}
+	s.mu.RUnlock()
+
+	return summarizeSalaries(query, samples), nil
+}
+
+// salaryGroupValue returns the value of a salary grouping field of a job
+func salaryGroupValue(job *models.Job, field string) string {
+	switch field {
+	case "normalized_title":
+		return job.NormalizedTitle
+	case "seniority":
+		return job.Seniority
+	case "location":
+		return job.Location
+	case "remote_ok":
+		return strconv.FormatBool(job.RemoteOk)
+	default:
+		return job.Company
+	}
+}
+
+// memoryMatches reports whether a job passes the filters of a search, as
+// sqliteFilter does
+func memoryMatches(job *models.Job, query *models.JobSearchQuery) bool {
+	if job.ClusterID != nil && *job.ClusterID != job.ID {
+		return false
+	}
+
+	text := strings.ToLower(job.Title + "\n" + job.Company + "\n" + job.Description)
+	for _, term := range keywordTerms(query.Keywords) {
+		if !strings.Contains(text, term) {
+			return false
+		}
+	}
+
+	switch {
+	case query.Location != "" && !strings.Contains(strings.ToLower(job.Location), strings.ToLower(query.Location)),
+		query.Remote != nil && job.RemoteOk != *query.Remote,
+		query.JobType != "" && job.JobType != query.JobType,
+		query.Source != "" && job.Source != query.Source,
+		query.CompanyID != 0 && (job.CompanyID == nil || *job.CompanyID != query.CompanyID),
+		query.Seniority != "" && job.Seniority != query.Seniority,
+		query.RoleFamily != "" && job.RoleFamily != query.RoleFamily,
+		query.MinSalary > 0 && (job.SalaryMax == nil || *job.SalaryMax < int64(query.MinSalary)),
+		job.QualityScore < query.MinQuality,
+		!query.PostedAfter.IsZero() && job.PostedAt.Before(query.PostedAfter),
+		!query.PostedBefore.IsZero() && !job.PostedAt.Before(query.PostedBefore),
+		!query.UpdatedSince.IsZero() && job.UpdatedAt.Before(query.UpdatedSince):
+		return false
+	}
+
+	switch query.Status {
+	case "", models.JobStatusOpen:
+		if job.Status != models.JobStatusOpen {
+			return false
+		}
+	case models.JobStatusClosed:
+		if job.Status == models.JobStatusOpen {
+			return false
+		}
+	}
+
+	switch query.Flagged {
+	case "":
+		if job.Suspicious() {
+			return false
+		}
+	case models.FlaggedOnly:
+		if !job.Suspicious() {
+			return false
+		}
+	case models.FlaggedUnreviewed:
+		if !job.Flagged || job.FlagReview != "" {
+			return false
+		}
+	}
+
+	tags := make(map[string]bool, len(job.Tags))
+	for _, tag := range job.Tags {
+		tags[tag] = true
+	}
+	for _, skill := range query.Skills {
+		if !tags[skill] {
+			return false
+		}
+	}
+	if len(query.SkillsAny) > 0 {
+		for _, skill := range query.SkillsAny {
+			if tags[skill] {
+				return true
+			}
+		}
+		return false
+	}
+
+	return true
+}
+
+// sortJobs sorts search matches, as orderBy does
+func sortJobs(jobs []*models.Job, query *models.JobSearchQuery) {
+	desc := query.Descending()
+	less := func(cmp int, a, b *models.Job) bool {
+		if cmp == 0 {
+			cmp = compareInt64(a.ID, b.ID)
+		}
+		if desc {
+			return cmp > 0
+		}
+		return cmp < 0
+	}
+
+	sort.Slice(jobs, func(i, j int) bool {
+		a, b := jobs[i], jobs[j]
+		switch query.SortField() {
+		case models.SortScrapedAt:
+			return less(a.ScrapedAt.Compare(b.ScrapedAt), a, b)
+		case models.SortSalary:
+			// Jobs without a salary come last in either direction
+			if (a.SalaryMax == nil) != (b.SalaryMax == nil) {
+				return b.SalaryMax == nil
+			}
+			if a.SalaryMax == nil {
+				return less(0, a, b)
+			}
+			return less(compareInt64(*a.SalaryMax, *b.SalaryMax), a, b)
+		case models.SortCompany:
+			return less(strings.Compare(a.Company, b.Company), a, b)
+		}
+
+		cmp := strings.Compare(a.PostedAt.UTC().Format("2006-01-02"), b.PostedAt.UTC().Format("2006-01-02"))
+		if cmp == 0 {
+			cmp = compareInt64(int64(precisionRanks[a.PostedAtPrecision]), int64(precisionRanks[b.PostedAtPrecision]))
+		}
+		if cmp == 0 {
+			cmp = a.PostedAt.Compare(b.PostedAt)
+		}
+		return less(cmp, a, b)
+	})
+}
+
+func compareInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// GetStats retrieves aggregated job statistics
+func (s *MemoryJobStore) GetStats(ctx context.Context) (*models.JobStats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stats := &models.JobStats{
+		JobsBySource:     make(map[string]int64),
+		JobsByType:       make(map[string]int64),
+		JobsBySeniority:  make(map[string]int64),
+		JobsByRoleFamily: make(map[string]int64),
+		TopCompanies:     make([]models.CompanyCount, 0),
+		TopLocations:     make([]models.LocationCount, 0),
+		TopSkills:        make([]models.SkillCount, 0),
+		JobsByQuality:    qualityCounts(),
+		RefreshedAt:      time.Now(), // Computed on every call
+	}
+
+	companies := make(map[string]int64)
+	locations := make(map[string]int64)
+	skills := make(map[string]int64)
+	var durations []time.Duration
+	today := time.Now().UTC().Format("2006-01-02")
+
+	for _, job := range s.jobs {
+		stats.TotalJobs++
+		stats.JobsBySource[job.Source]++
+		stats.JobsByType[job.JobType]++
+		stats.JobsBySeniority[job.Seniority]++
+		stats.JobsByRoleFamily[job.RoleFamily]++
+		stats.JobsByQuality[models.QualityBand(job.QualityScore)]++
+		stats.AvgQuality += float64(job.QualityScore)
+		companies[job.Company]++
+		locations[job.Location]++
+		for _, tag := range job.Tags {
+			skills[tag]++
+		}
+
+		if job.RemoteOk {
+			stats.RemoteJobs++
+		}
+		if job.PostedAtPrecision != "unknown" && job.PostedAt.UTC().Format("2006-01-02") == today {
+			stats.TodayJobs++
+		}
+		if job.ScrapedAt.After(stats.LastScrapedAt) {
+			stats.LastScrapedAt = job.ScrapedAt
+		}
+
+		switch job.Status {
+		case models.JobStatusOpen:
+			stats.OpenJobs++
+		case models.JobStatusClosed:
+			stats.ClosedJobs++
+			durations = append(durations, job.LastSeenAt.Sub(job.FirstSeenAt))
+		}
+	}
+	stats.AvgDaysOpen, stats.MedianDaysOpen = daysOpen(durations)
+	if stats.TotalJobs > 0 {
+		stats.AvgQuality /= float64(stats.TotalJobs)
+	}
+
+	for _, c := range topOf(companies, 10) {
+		stats.TopCompanies = append(stats.TopCompanies, models.CompanyCount{Company: c.Value, Count: c.Count})
+	}
+	for _, c := range topOf(locations, 10) {
+		stats.TopLocations = append(stats.TopLocations, models.LocationCount{Location: c.Value, Count: c.Count})
+	}
+	for _, c := range topOf(skills, 25) {
+		stats.TopSkills = append(stats.TopSkills, models.SkillCount{Skill: c.Value, Count: c.Count})
+	}
+
+	return stats, nil
+}
+
+// topOf returns the n most frequent values, ties in value order
+func topOf(counts map[string]int64, n int) []models.FacetCount {
+	top := make([]models.FacetCount, 0, len(counts))
+	for value, count := range counts {
+		top = append(top, models.FacetCount{Value: value, Count: count})
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Count != top[j].Count {
+			return top[i].Count > top[j].Count
+		}
+		return top[i].Value < top[j].Value
+	})
+	if len(top) > n {
+		top = top[:n]
+	}
+	return top
+}
+
+// expired returns the IDs of the jobs matching a retention rule whose age
+// column is before cutoff, in ID order
+func (s *MemoryJobStore) expired(rule *models.RetentionRule, cutoff time.Time) []int64 {
+	var ids []int64
+	for id, job := range s.jobs {
+		if rule.Source != "" && job.Source != rule.Source {
+			continue
+		}
+		if rule.Status != "" && job.Status != rule.Status {
+			continue
+		}
+
+		var age *time.Time
+		switch rule.AgeColumn() {
+		case models.RetentionFromPosted:
+			age = &job.PostedAt
+		case models.RetentionFromLastSeen:
+			age = &job.LastSeenAt
+		case models.RetentionFromClosed:
+			age = job.ClosedAt
+		}
+		if age != nil && age.Before(cutoff) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// CountExpired counts the jobs matching a retention rule whose age column
+// is before cutoff
+func (s *MemoryJobStore) CountExpired(ctx context.Context, rule *models.RetentionRule, cutoff time.Time) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return int64(len(s.expired(rule, cutoff))), nil
+}
+
+// DeleteExpired deletes at most limit of the jobs CountExpired counts
+func (s *MemoryJobStore) DeleteExpired(ctx context.Context, rule *models.RetentionRule, cutoff time.Time, limit int) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := s.expired(rule, cutoff)
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+	for _, id := range ids {
+		job := s.jobs[id]
+		delete(s.jobs, id)
+		delete(s.byHash, job.Hash)
+		if key := nativeKey(job); key != "" {
+			delete(s.byNativeID, key)
+		}
+	}
+
+	// Like ON DELETE SET NULL on cluster_id
+	for _, job := range s.jobs {
+		if job.ClusterID != nil && s.jobs[*job.ClusterID] == nil {
+			job.ClusterID = nil
+		}
+	}
+
+	return int64(len(ids)), nil
+}
+
+// ExistsByHash checks if a job with the given hash already exists
+func (s *MemoryJobStore) ExistsByHash(ctx context.Context, hash string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, ok := s.byHash[hash]
+	return ok, nil
+}
+
+// ReviewFlag records an admin's review of a job's flag and returns the job
+func (s *MemoryJobStore) ReviewFlag(ctx context.Context, id int64, review string) (*models.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	job.FlagReview = review
+	return cloneJob(job), nil
+}
+
+// cloneJob returns a deep copy of a job, with its tags sorted as the
+// database stores return them
+func cloneJob(job *models.Job) *models.Job {
+	c := *job
+	c.Tags = append(make([]string, 0, len(job.Tags)), job.Tags...)
+	sort.Strings(c.Tags)
+	c.Listings = append([]models.JobListing(nil), job.Listings...)
+	c.CompanyID = copyPtr(job.CompanyID)
+	c.ClusterID = copyPtr(job.ClusterID)
+	c.ClosedAt = copyPtr(job.ClosedAt)
+	c.SalaryMin = copyPtr(job.SalaryMin)
+	c.SalaryMax = copyPtr(job.SalaryMax)
+	c.Latitude = copyPtr(job.Latitude)
+	c.Longitude = copyPtr(job.Longitude)
+	c.DistanceKm = copyPtr(job.DistanceKm)
+	c.QualityReasons = append([]models.QualityReason(nil), job.QualityReasons...)
+	c.FlagReasons = append([]string(nil), job.FlagReasons...)
+	return &c
+}
+
+func copyPtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
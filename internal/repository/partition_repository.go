@@ -0,0 +1,430 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/abhisheksainimitawa/job-aggregator/internal/models"
+	"github.com/lib/pq"
+)
+
+// ErrPartitionNotFound is returned for months without a partition
+var ErrPartitionNotFound = errors.New("partition not found")
+
+// ErrPartitionDetached is returned when a month's partition was detached
+// by an archive that has not finished
+var ErrPartitionDetached = errors.New("partition is detached for archival")
+
+// partitionLayout names the monthly partitions of jobs, e.g. jobs_2024_05
+const partitionLayout = "jobs_2006_01"
+
+// defaultPartition holds jobs posted outside every monthly partition
+const defaultPartition = "jobs_default"
+
+// restoreColumns are the columns of an archived job, in restoreArgs order
+const restoreColumns = `id, ` + jobInsertColumns + `,
+	cluster_id, fingerprint, status, first_seen_at, last_seen_at, closed_at, flag_review
+`
+
+// PartitionRepository manages the monthly posted_at partitions of jobs
+// created by migration 0014
+type PartitionRepository struct {
+	db *sql.DB
+}
+
+// NewPartitionRepository creates a new partition repository
+func NewPartitionRepository(db *sql.DB) *PartitionRepository {
+	return &PartitionRepository{db: db}
+}
+
+// PartitionName returns the name of the partition of jobs posted in the
+// month of t
+func PartitionName(t time.Time) string {
+	return MonthOf(t).Format(partitionLayout)
+}
+
+// MonthOf returns the first instant of the month of t, in UTC like the
+// stored posted_at values
+func MonthOf(t time.Time) time.Time {
+	y, m, _ := t.UTC().Date()
+	return time.Date(y, m, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// List returns the monthly partitions, including detached ones awaiting
+// archival, and the default partition, oldest first
+func (r *PartitionRepository) List(ctx context.Context) ([]models.JobPartition, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT c.relname, c.relispartition, GREATEST(c.reltuples, 0)::bigint
+		FROM pg_class c
+		WHERE c.relkind = 'r' AND pg_table_is_visible(c.oid)
+		  AND c.relname ~ '^jobs_([0-9]{4}_[0-9]{2}|default)$'
+		ORDER BY c.relname
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list partitions: %w", err)
+	}
+	defer rows.Close()
+
+	var partitions []models.JobPartition
+	for rows.Next() {
+		var p models.JobPartition
+		if err := rows.Scan(&p.Name, &p.Attached, &p.Rows); err != nil {
+			return nil, fmt.Errorf("failed to scan partition: %w", err)
+		}
+		if p.Name != defaultPartition {
+			if p.Month, err = time.Parse(partitionLayout, p.Name); err != nil {
+				return nil, fmt.Errorf("failed to parse partition %s: %w", p.Name, err)
+			}
+		}
+		partitions = append(partitions, p)
+	}
+	return partitions, rows.Err()
+}
+
+// EnsureMonths creates the missing partitions for the month of from and
+// the following months and returns the names of those it created
+func (r *PartitionRepository) EnsureMonths(ctx context.Context, from time.Time, months int) ([]string, error) {
+	var created []string
+	for i := 0; i <= months; i++ {
+		month := MonthOf(from).AddDate(0, i, 0)
+
+		tx, err := r.db.BeginTx(ctx, nil)
+		if err != nil {
+			return created, fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		ok, err := createPartition(ctx, tx, month)
+		if err == nil {
+			err = tx.Commit()
+		}
+		tx.Rollback()
+		if err != nil {
+			return created, err
+		}
+		if ok {
+			created = append(created, PartitionName(month))
+		}
+	}
+	return created, nil
+}
+
+// partitionState reports whether a partition table exists and whether it
+// is attached to jobs
+func partitionState(ctx context.Context, q queryer, name string) (exists, attached bool, err error) {
+	err = q.QueryRowContext(ctx,
+		"SELECT relispartition FROM pg_class WHERE oid = to_regclass($1)", name,
+	).Scan(&attached)
+	if err == sql.ErrNoRows {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, fmt.Errorf("failed to look up partition %s: %w", name, err)
+	}
+	return true, attached, nil
+}
+
+// createPartition creates the partition of month unless it exists. Jobs
+// of that month in the default partition, e.g. ones posted after their
+// month was archived, are moved into it.
+func createPartition(ctx context.Context, tx *sql.Tx, month time.Time) (bool, error) {
+	name := PartitionName(month)
+	exists, attached, err := partitionState(ctx, tx, name)
+	if err != nil {
+		return false, err
+	}
+	if exists && !attached {
+		return false, fmt.Errorf("%w: %s", ErrPartitionDetached, name)
+	}
+	if exists {
+		return false, nil
+	}
+
+	columns, err := storedColumns(ctx, tx)
+	if err != nil {
+		return false, err
+	}
+
+	from, to := MonthOf(month), MonthOf(month).AddDate(0, 1, 0)
+	table := pq.QuoteIdentifier(name)
+	if _, err := tx.ExecContext(ctx,
+		`CREATE TABLE `+table+` (LIKE jobs INCLUDING DEFAULTS INCLUDING GENERATED INCLUDING CONSTRAINTS)`,
+	); err != nil {
+		return false, fmt.Errorf("failed to create partition %s: %w", name, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		WITH moved AS (
+			DELETE FROM `+defaultPartition+` WHERE posted_at >= $1 AND posted_at < $2
+			RETURNING `+columns+`
+		)
+		INSERT INTO `+table+` (`+columns+`) SELECT `+columns+` FROM moved
+	`, from, to); err != nil {
+		return false, fmt.Errorf("failed to move jobs into partition %s: %w", name, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(`ALTER TABLE jobs ATTACH PARTITION %s FOR VALUES FROM (%s) TO (%s)`,
+		table, pq.QuoteLiteral(from.Format("2006-01-02")), pq.QuoteLiteral(to.Format("2006-01-02"))),
+	); err != nil {
+		return false, fmt.Errorf("failed to attach partition %s: %w", name, err)
+	}
+	return true, nil
+}
+
+// storedColumns returns the columns of jobs that hold data, leaving out
+// the generated ones
+func storedColumns(ctx context.Context, q queryer) (string, error) {
+	var columns string
+	err := q.QueryRowContext(ctx, `
+		SELECT string_agg(quote_ident(attname), ', ' ORDER BY attnum)
+		FROM pg_attribute
+		WHERE attrelid = 'jobs'::regclass AND attnum > 0 AND NOT attisdropped AND attgenerated = ''
+	`).Scan(&columns)
+	if err != nil {
+		return "", fmt.Errorf("failed to read job columns: %w", err)
+	}
+	return columns, nil
+}
+
+// Detach detaches the partition of month from jobs, hiding its jobs from
+// every query. A partition that is already detached is left as it is.
+func (r *PartitionRepository) Detach(ctx context.Context, month time.Time) error {
+	name := PartitionName(month)
+	exists, attached, err := partitionState(ctx, r.db, name)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrPartitionNotFound, name)
+	}
+	if !attached {
+		return nil
+	}
+
+	if _, err := r.db.ExecContext(ctx, `ALTER TABLE jobs DETACH PARTITION `+pq.QuoteIdentifier(name)); err != nil {
+		return fmt.Errorf("failed to detach partition %s: %w", name, err)
+	}
+	return nil
+}
+
+// detached checks that the partition of month exists and is detached
+func (r *PartitionRepository) detached(ctx context.Context, month time.Time) (string, error) {
+	name := PartitionName(month)
+	exists, attached, err := partitionState(ctx, r.db, name)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return "", fmt.Errorf("%w: %s", ErrPartitionNotFound, name)
+	}
+	if attached {
+		return "", fmt.Errorf("partition %s is still attached", name)
+	}
+	return name, nil
+}
+
+// ExportDetached calls fn for every job of the detached partition of
+// month, with its tags, in ID order
+func (r *PartitionRepository) ExportDetached(ctx context.Context, month time.Time, fn func(job *models.Job) error) error {
+	name, err := r.detached(ctx, month)
+	if err != nil {
+		return err
+	}
+
+	// jobColumns reads the tags of jobs.id
+	rows, err := r.db.QueryContext(ctx, `SELECT `+jobColumns+` FROM `+pq.QuoteIdentifier(name)+` AS jobs ORDER BY id`)
+	if err != nil {
+		return fmt.Errorf("failed to read partition %s: %w", name, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return fmt.Errorf("failed to scan job: %w", err)
+		}
+		if err := fn(job); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// DropDetached deletes the jobs of the detached partition of month, with
+// their keys, tags and versions, then drops it. Clusters headed by one of
+// its jobs move to a listing in an attached partition. It returns the
+// number of jobs deleted.
+func (r *PartitionRepository) DropDetached(ctx context.Context, month time.Time) (int64, error) {
+	name, err := r.detached(ctx, month)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	table := pq.QuoteIdentifier(name)
+	var ids []int64
+	if err := tx.QueryRowContext(ctx, `SELECT COALESCE(array_agg(id), '{}') FROM `+table).Scan(pq.Array(&ids)); err != nil {
+		return 0, fmt.Errorf("failed to read jobs of partition %s: %w", name, err)
+	}
+	if err := moveClusters(ctx, tx, ids); err != nil {
+		return 0, err
+	}
+
+	result, err := tx.ExecContext(ctx, "DELETE FROM job_keys WHERE id = ANY($1::bigint[])", pq.Array(ids))
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete jobs of partition %s: %w", name, err)
+	}
+	if _, err := tx.ExecContext(ctx, `DROP TABLE `+table); err != nil {
+		return 0, fmt.Errorf("failed to drop partition %s: %w", name, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	count, _ := result.RowsAffected()
+	return count, nil
+}
+
+// Restore stores archived jobs read from next until it returns io.EOF,
+// keeping their IDs and creating their partitions as needed. Jobs whose
+// ID, hash or native ID was stored again since they were archived are
+// skipped. All jobs are restored in one transaction.
+func (r *PartitionRepository) Restore(ctx context.Context, next func() (*models.Job, error)) (restored, skipped int64, err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		CREATE TEMP TABLE job_restore ON COMMIT DROP AS
+		SELECT `+restoreColumns+` FROM jobs WITH NO DATA;
+		ALTER TABLE job_restore ADD COLUMN tags TEXT[]
+	`); err != nil {
+		return 0, 0, fmt.Errorf("failed to create restore table: %w", err)
+	}
+
+	if err := copyRestored(ctx, tx, next); err != nil {
+		return 0, 0, err
+	}
+
+	months, err := restoredMonths(ctx, tx)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, month := range months {
+		if _, err := createPartition(ctx, tx, month); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		DELETE FROM job_restore s
+		WHERE EXISTS (SELECT 1 FROM job_keys k WHERE k.id = s.id)
+		   OR EXISTS (SELECT 1 FROM job_keys k WHERE k.hash = s.hash)
+		   OR EXISTS (SELECT 1 FROM job_keys k
+		              WHERE s.native_id <> '' AND k.source = s.source AND k.native_id = s.native_id AND k.native_id <> '')
+	`)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to skip stored jobs: %w", err)
+	}
+	skipped, _ = result.RowsAffected()
+
+	// Companies and cluster heads may have been deleted meanwhile
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE job_restore s SET company_id = NULL
+		WHERE company_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM companies c WHERE c.id = s.company_id)
+	`); err != nil {
+		return 0, 0, fmt.Errorf("failed to check companies: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE job_restore s SET cluster_id = NULL
+		WHERE cluster_id IS NOT NULL
+		  AND NOT EXISTS (SELECT 1 FROM job_keys k WHERE k.id = s.cluster_id)
+		  AND NOT EXISTS (SELECT 1 FROM job_restore c WHERE c.id = s.cluster_id)
+	`); err != nil {
+		return 0, 0, fmt.Errorf("failed to check clusters: %w", err)
+	}
+
+	result, err = tx.ExecContext(ctx, `INSERT INTO jobs (`+restoreColumns+`) SELECT `+restoreColumns+` FROM job_restore`)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to restore jobs: %w", err)
+	}
+	restored, _ = result.RowsAffected()
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO job_tags (job_id, tag)
+		SELECT id, UNNEST(tags) FROM job_restore
+		ON CONFLICT DO NOTHING
+	`); err != nil {
+		return 0, 0, fmt.Errorf("failed to restore job tags: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return restored, skipped, nil
+}
+
+// copyRestored copies the jobs read from next into job_restore
+func copyRestored(ctx context.Context, tx *sql.Tx, next func() (*models.Job, error)) error {
+	columns := append(splitColumns(restoreColumns), "tags")
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("job_restore", columns...))
+	if err != nil {
+		return fmt.Errorf("failed to prepare copy: %w", err)
+	}
+	defer stmt.Close()
+
+	for {
+		job, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := stmt.ExecContext(ctx, append(restoreArgs(job), pq.Array(job.Tags))...); err != nil {
+			return fmt.Errorf("failed to copy job %d: %w", job.ID, err)
+		}
+	}
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		return fmt.Errorf("failed to copy jobs: %w", err)
+	}
+	return nil
+}
+
+// restoreArgs returns the values of restoreColumns for an archived job
+func restoreArgs(job *models.Job) []interface{} {
+	args := jobArgs(job, job.UpdatedAt)
+	args[12] = job.CreatedAt // jobArgs sets created_at and updated_at alike
+
+	args = append([]interface{}{job.ID}, args...)
+	return append(args, job.ClusterID, job.Fingerprint, job.Status, job.FirstSeenAt, job.LastSeenAt, job.ClosedAt, job.FlagReview)
+}
+
+// restoredMonths returns the months of the jobs in job_restore
+func restoredMonths(ctx context.Context, tx *sql.Tx) ([]time.Time, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT DISTINCT date_trunc('month', posted_at) FROM job_restore ORDER BY 1`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read restored months: %w", err)
+	}
+	defer rows.Close()
+
+	var months []time.Time
+	for rows.Next() {
+		var month time.Time
+		if err := rows.Scan(&month); err != nil {
+			return nil, fmt.Errorf("failed to scan month: %w", err)
+		}
+		months = append(months, month)
+	}
+	return months, rows.Err()
+}
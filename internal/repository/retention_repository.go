@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/abhisheksainimitawa/job-aggregator/internal/models"
+)
+
+// RetentionRepository stores the audit log of retention runs
+type RetentionRepository struct {
+	db *sql.DB
+}
+
+// NewRetentionRepository creates a new retention repository
+func NewRetentionRepository(db *sql.DB) *RetentionRepository {
+	return &RetentionRepository{db: db}
+}
+
+// Record stores a retention run and sets its ID
+func (r *RetentionRepository) Record(ctx context.Context, run *models.RetentionRun) error {
+	rules, err := json.Marshal(run.Rules)
+	if err != nil {
+		return fmt.Errorf("failed to encode retention rules: %w", err)
+	}
+
+	err = r.db.QueryRowContext(ctx, `
+		INSERT INTO retention_runs (started_at, finished_at, dry_run, deleted, rules, error)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`, run.StartedAt, run.FinishedAt, run.DryRun, run.Deleted, string(rules), run.Error).Scan(&run.ID)
+	if err != nil {
+		return fmt.Errorf("failed to record retention run: %w", err)
+	}
+	return nil
+}
+
+// List returns the latest retention runs, newest first
+func (r *RetentionRepository) List(ctx context.Context, limit int) ([]*models.RetentionRun, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, started_at, finished_at, dry_run, deleted, rules, error
+		FROM retention_runs
+		ORDER BY started_at DESC, id DESC
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list retention runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := make([]*models.RetentionRun, 0)
+	for rows.Next() {
+		run := &models.RetentionRun{}
+		var rules []byte
+		if err := rows.Scan(&run.ID, &run.StartedAt, &run.FinishedAt, &run.DryRun, &run.Deleted, &rules, &run.Error); err != nil {
+			return nil, fmt.Errorf("failed to scan retention run: %w", err)
+		}
+		if err := json.Unmarshal(rules, &run.Rules); err != nil {
+			return nil, fmt.Errorf("failed to decode retention run %d: %w", run.ID, err)
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
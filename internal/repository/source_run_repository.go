@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/abhisheksainimitawa/job-aggregator/internal/models"
+	"github.com/lib/pq"
+)
+
+// SourceRunRepository stores the history of source runs for health
+// monitoring
+type SourceRunRepository struct {
+	db *sql.DB
+}
+
+// NewSourceRunRepository creates a new source run repository
+func NewSourceRunRepository(db *sql.DB) *SourceRunRepository {
+	return &SourceRunRepository{db: db}
+}
+
+// Record stores a source run and sets its ID
+func (r *SourceRunRepository) Record(ctx context.Context, run *models.SourceRun) error {
+	fillRates, err := json.Marshal(run.FillRates)
+	if err != nil {
+		return fmt.Errorf("failed to encode fill rates: %w", err)
+	}
+
+	err = r.db.QueryRowContext(ctx, `
+		INSERT INTO source_runs (source, started_at, latency_ms, jobs, parse_failures, fill_rates, error, anomalies)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+	`, run.Source, run.StartedAt, run.LatencyMs, run.Jobs, run.ParseFailures, string(fillRates),
+		run.Error, pq.Array(run.Anomalies)).Scan(&run.ID)
+	if err != nil {
+		return fmt.Errorf("failed to record source run: %w", err)
+	}
+	return nil
+}
+
+// Recent returns the latest runs of a source, newest first
+func (r *SourceRunRepository) Recent(ctx context.Context, source string, limit int) ([]models.SourceRun, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, source, started_at, latency_ms, jobs, parse_failures, fill_rates, error, anomalies
+		FROM source_runs
+		WHERE source = $1
+		ORDER BY started_at DESC, id DESC
+		LIMIT $2
+	`, source, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list source runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := make([]models.SourceRun, 0)
+	for rows.Next() {
+		var run models.SourceRun
+		var fillRates []byte
+		var anomalies []string
+		err := rows.Scan(&run.ID, &run.Source, &run.StartedAt, &run.LatencyMs, &run.Jobs, &run.ParseFailures,
+			&fillRates, &run.Error, pq.Array(&anomalies))
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan source run: %w", err)
+		}
+		if err := json.Unmarshal(fillRates, &run.FillRates); err != nil {
+			return nil, fmt.Errorf("failed to decode source run %d: %w", run.ID, err)
+		}
+		if len(anomalies) > 0 {
+			run.Anomalies = anomalies
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+// Sources returns every source with recorded runs, sorted
+func (r *SourceRunRepository) Sources(ctx context.Context) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT DISTINCT source FROM source_runs ORDER BY source")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sources: %w", err)
+	}
+	defer rows.Close()
+
+	sources := make([]string, 0)
+	for rows.Next() {
+		var source string
+		if err := rows.Scan(&source); err != nil {
+			return nil, fmt.Errorf("failed to scan source: %w", err)
+		}
+		sources = append(sources, source)
+	}
+	return sources, rows.Err()
+}
@@ -0,0 +1,756 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/abhisheksainimitawa/job-aggregator/internal/models"
+	"github.com/abhisheksainimitawa/job-aggregator/pkg/logger"
+)
+
+// sqliteSchema is the SQLite equivalent of the jobs and job_tags tables
+// built by the Postgres migrations. Companies, versions and clusters are
+// not part of JobStore and stay Postgres-only.
+const sqliteSchema = `
+	CREATE TABLE IF NOT EXISTS jobs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		title TEXT NOT NULL,
+		company TEXT NOT NULL,
+		location TEXT NOT NULL,
+		salary TEXT,
+		description TEXT NOT NULL,
+		url TEXT NOT NULL,
+		source TEXT NOT NULL,
+		remote_ok BOOLEAN NOT NULL DEFAULT 0,
+		job_type TEXT NOT NULL,
+		posted_at TIMESTAMP NOT NULL,
+		scraped_at TIMESTAMP NOT NULL,
+		hash TEXT NOT NULL UNIQUE,
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL,
+		normalized_title TEXT NOT NULL DEFAULT '',
+		seniority TEXT NOT NULL DEFAULT '',
+		role_family TEXT NOT NULL DEFAULT '',
+		company_id INTEGER,
+		description_html TEXT NOT NULL DEFAULT '',
+		description_markdown TEXT NOT NULL DEFAULT '',
+		posted_at_precision TEXT NOT NULL DEFAULT 'unknown',
+		cluster_id INTEGER REFERENCES jobs(id) ON DELETE SET NULL,
+		fingerprint INTEGER NOT NULL DEFAULT 0,
+		canonical_url TEXT NOT NULL DEFAULT '',
+		native_id TEXT NOT NULL DEFAULT '',
+		status TEXT NOT NULL DEFAULT 'open',
+		first_seen_at TIMESTAMP NOT NULL,
+		last_seen_at TIMESTAMP NOT NULL,
+		closed_at TIMESTAMP,
+		missed_runs INTEGER NOT NULL DEFAULT 0,
+		salary_min INTEGER,
+		salary_max INTEGER,
+		salary_currency TEXT NOT NULL DEFAULT '',
+		latitude REAL,
+		longitude REAL,
+		quality_score INTEGER NOT NULL DEFAULT 0,
+		quality_reasons TEXT NOT NULL DEFAULT '[]',
+		flagged BOOLEAN NOT NULL DEFAULT 0,
+		flag_reasons TEXT NOT NULL DEFAULT '[]',
+		flag_review TEXT NOT NULL DEFAULT ''
+	);
+
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_jobs_source_native_id ON jobs(source, native_id) WHERE native_id <> '';
+	CREATE INDEX IF NOT EXISTS idx_jobs_posted_at ON jobs(posted_at);
+	CREATE INDEX IF NOT EXISTS idx_jobs_status ON jobs(status);
+	CREATE INDEX IF NOT EXISTS idx_jobs_quality_score ON jobs(quality_score);
+
+	CREATE TABLE IF NOT EXISTS job_tags (
+		job_id INTEGER NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+		tag TEXT NOT NULL,
+		PRIMARY KEY (job_id, tag)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_job_tags_tag ON job_tags(tag);
+`
+
+// sqliteJobColumns are the columns of jobColumns without the tags, which
+// SQLite reads separately
+const sqliteJobColumns = `
+	id, title, company, location, COALESCE(salary, ''), description, url, source,
+	remote_ok, job_type, posted_at, scraped_at, hash, created_at, updated_at,
+	normalized_title, seniority, role_family, company_id,
+	description_html, description_markdown, posted_at_precision,
+	cluster_id, fingerprint, canonical_url, native_id,
+	status, first_seen_at, last_seen_at, closed_at,
+	salary_min, salary_max, salary_currency, latitude, longitude,
+	quality_score, quality_reasons, flagged, flag_reasons, flag_review
+`
+
+// sqliteNewestDay is DATE(posted_at) for the UTC timestamps SQLite stores
+const sqliteNewestDay = "substr(posted_at, 1, 10)"
+
+// SQLiteJobStore is a JobStore on an embedded SQLite database (see
+// NewSQLiteDB), for running the API without a database server. Keywords
+// match words in the title, company or description instead of using
+// full-text search.
+type SQLiteJobStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteJobStore creates a new SQLite job store
+func NewSQLiteJobStore(db *sql.DB) *SQLiteJobStore {
+	return &SQLiteJobStore{db: db}
+}
+
+// sqliteQuery rewrites the $N parameters of a Postgres query to SQLite's
+// equivalent ?N, so both stores can share statements
+func sqliteQuery(query string) string {
+	return strings.ReplaceAll(query, "$", "?")
+}
+
+// sqliteArgs converts times to UTC. SQLite stores them as text, which
+// only sorts and compares correctly in a single time zone.
+func sqliteArgs(args []interface{}) []interface{} {
+	for i, arg := range args {
+		if t, ok := arg.(time.Time); ok {
+			args[i] = t.UTC()
+		}
+	}
+	return args
+}
+
+// Create inserts a new job into the database
+func (s *SQLiteJobStore) Create(ctx context.Context, job *models.Job) error {
+	// SQLite does not enforce VARCHAR lengths
+	if reason := rejectReason(job); reason != "" {
+		return fmt.Errorf("failed to create job: %s", reason)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, sqliteQuery(insertJob+" RETURNING id"), sqliteArgs(jobArgs(job, time.Now()))...).Scan(&job.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create job: %w", err)
+	}
+
+	if err := sqliteReplaceTags(ctx, tx, job.ID, job.Tags); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// CreateBatch inserts or updates multiple jobs in a single transaction,
+// with the same matching and rejections as JobRepository.CreateBatch.
+// Changes are not recorded as versions.
+func (s *SQLiteJobStore) CreateBatch(ctx context.Context, jobs []*models.Job) (*models.BatchResult, error) {
+	accepted, rejected := screenBatch(jobs)
+	result := &models.BatchResult{Rejected: rejected}
+	if len(accepted) == 0 {
+		return result, nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	byHash, err := tx.PrepareContext(ctx, sqliteQuery(insertJob+`
+		ON CONFLICT (hash) DO UPDATE SET `+upsertJobSet+`,
+			native_id = CASE WHEN native_id = '' THEN EXCLUDED.native_id ELSE native_id END
+		RETURNING id, cluster_id
+	`))
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer byHash.Close()
+
+	byNativeID, err := tx.PrepareContext(ctx, sqliteQuery(insertJob+`
+		ON CONFLICT (source, native_id) WHERE native_id <> '' DO UPDATE SET `+upsertJobSet+`
+		RETURNING id, cluster_id
+	`))
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer byNativeID.Close()
+
+	// The stored job a row updates, and the owner of its hash unless it
+	// is a job of the same source stored without a native ID, which the
+	// row adopts. The native_id <> '' term lets SQLite use the partial index.
+	findStored, err := tx.PrepareContext(ctx, `
+		SELECT
+			CASE WHEN ?2 <> ''
+				THEN COALESCE(
+					(SELECT id FROM jobs WHERE source = ?1 AND native_id = ?2 AND native_id <> ''),
+					(SELECT id FROM jobs WHERE hash = ?3 AND source = ?1 AND native_id = ''))
+				ELSE (SELECT id FROM jobs WHERE hash = ?3)
+			END,
+			(SELECT id FROM jobs WHERE hash = ?3)
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer findStored.Close()
+
+	reject := func(row int, reason string) {
+		result.Rejected = append(result.Rejected, models.BatchRejection{Row: row, Hash: jobs[row].Hash, Reason: reason})
+	}
+
+	now := time.Now()
+	for _, row := range accepted {
+		job := jobs[row]
+
+		var storedID, hashOwner sql.NullInt64
+		if err := findStored.QueryRowContext(ctx, job.Source, job.NativeID, job.Hash).Scan(&storedID, &hashOwner); err != nil {
+			return nil, fmt.Errorf("failed to look up stored job: %w", err)
+		}
+		if job.NativeID != "" && !storedID.Valid && hashOwner.Valid {
+			reject(row, fmt.Sprintf(hashTakenReason, hashOwner.Int64))
+			continue
+		}
+
+		// An adopted job only matches on its hash
+		stmt := byHash
+		if job.NativeID != "" && (!storedID.Valid || storedID != hashOwner) {
+			stmt = byNativeID
+		}
+
+		// A failed statement leaves SQLite's transaction usable
+		err := stmt.QueryRowContext(ctx, sqliteArgs(jobArgs(job, now))...).Scan(&job.ID, &job.ClusterID)
+		if err != nil {
+			reject(row, err.Error())
+			continue
+		}
+
+		if err := sqliteReplaceTags(ctx, tx, job.ID, job.Tags); err != nil {
+			return nil, fmt.Errorf("failed to store tags for job %d: %w", job.ID, err)
+		}
+
+		if storedID.Valid {
+			result.Updated++
+		} else {
+			result.Inserted++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	sortRejections(result.Rejected)
+	return result, nil
+}
+
+// FindByID retrieves a job by ID
+func (s *SQLiteJobStore) FindByID(ctx context.Context, id int64) (*models.Job, error) {
+	job := &models.Job{}
+	err := s.db.QueryRowContext(ctx, `SELECT `+sqliteJobColumns+` FROM jobs WHERE id = ?`, id).Scan(jobFields(job)...)
+	if err == sql.ErrNoRows {
+		return nil, ErrJobNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find job: %w", err)
+	}
+
+	if err := s.attachTags(ctx, []*models.Job{job}); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// Search searches for jobs based on query parameters
+func (s *SQLiteJobStore) Search(ctx context.Context, query *models.JobSearchQuery) ([]*models.Job, error) {
+	if err := checkPortable(query); err != nil {
+		return nil, err
+	}
+	if query.Limit == 0 {
+		query.Limit = 20
+	}
+
+	where, args := sqliteFilter(query)
+	sql := `SELECT ` + sqliteJobColumns + ` FROM jobs WHERE ` + where +
+		` ORDER BY ` + sqliteOrderBy(query) + ` LIMIT ? OFFSET ?`
+	args = append(args, query.Limit, query.Page*query.Limit)
+
+	rows, err := s.db.QueryContext(ctx, sql, sqliteArgs(args)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search jobs: %w", err)
+	}
+
+	jobs := make([]*models.Job, 0)
+	for rows.Next() {
+		job := &models.Job{}
+		if err := rows.Scan(jobFields(job)...); err != nil {
+			logger.Error("Failed to scan job: %v", err)
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+	rows.Close()
+
+	if err := s.attachTags(ctx, jobs); err != nil {
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
+// TimeSeries counts the canonical jobs matching the query's filter that
+// were posted, and that were listed, during each bucket
+func (s *SQLiteJobStore) TimeSeries(ctx context.Context, query *models.TimeSeriesQuery) ([]models.TimeSeriesPoint, error) {
+	if err := checkPortable(query.Filter); err != nil {
+		return nil, err
+	}
+
+	where, args := sqliteFilter(query.Filter)
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT posted_at, first_seen_at, last_seen_at, closed_at, status FROM jobs WHERE "+where,
+		sqliteArgs(args)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count time series: %w", err)
+	}
+	defer rows.Close()
+
+	now := time.Now()
+	var spans []postingSpan
+	for rows.Next() {
+		var posted, firstSeen, lastSeen time.Time
+		var closedAt *time.Time
+		var status string
+		if err := rows.Scan(&posted, &firstSeen, &lastSeen, &closedAt, &status); err != nil {
+			return nil, fmt.Errorf("failed to scan time series: %w", err)
+		}
+		spans = append(spans, newPostingSpan(posted, firstSeen, lastSeen, closedAt, status, now))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to count time series: %w", err)
+	}
+
+	return countTimeSeries(query.Bounds(), spans), nil
+}
+
+// SalaryStats computes salary percentiles of the jobs matching the
+// query's filter per group and currency
+func (s *SQLiteJobStore) SalaryStats(ctx context.Context, query *models.SalaryStatsQuery) ([]models.SalaryStats, error) {
+	if err := checkPortable(query.Filter); err != nil {
+		return nil, err
+	}
+	columns, err := salaryGroupSelect(query)
+	if err != nil {
+		return nil, err
+	}
+
+	where, args := sqliteFilter(query.Filter)
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT "+columns+", salary_min, salary_max FROM jobs WHERE "+where+
+			" AND (salary_min IS NOT NULL OR salary_max IS NOT NULL)",
+		sqliteArgs(args)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute salary stats: %w", err)
+	}
+	defer rows.Close()
+
+	var samples []salarySample
+	for rows.Next() {
+		sample := salarySample{group: make([]string, len(query.GroupBy))}
+		dest := make([]interface{}, 0, len(query.GroupBy)+3)
+		for i := range sample.group {
+			dest = append(dest, &sample.group[i])
+		}
+		dest = append(dest, &sample.currency, &sample.min, &sample.max)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to scan salary stats: %w", err)
+		}
+		samples = append(samples, sample)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to compute salary stats: %w", err)
+	}
+
+	return summarizeSalaries(query, samples), nil
+}
+
+// sqliteFilter builds the WHERE clause of a search, like searchFilter
+func sqliteFilter(query *models.JobSearchQuery) (string, []interface{}) {
+	where := "(cluster_id IS NULL OR cluster_id = id)"
+	var args []interface{}
+
+	// Every keyword must appear somewhere
+	for _, term := range keywordTerms(query.Keywords) {
+		pattern := "%" + escapeLike(term) + "%"
+		where += ` AND (title LIKE ? ESCAPE '\' OR company LIKE ? ESCAPE '\' OR description LIKE ? ESCAPE '\')`
+		args = append(args, pattern, pattern, pattern)
+	}
+
+	// LIKE is case-insensitive for ASCII, like ILIKE
+	if query.Location != "" {
+		where += ` AND location LIKE ? ESCAPE '\'`
+		args = append(args, "%"+escapeLike(query.Location)+"%")
+	}
+
+	if query.Remote != nil {
+		where += " AND remote_ok = ?"
+		args = append(args, *query.Remote)
+	}
+
+	if query.JobType != "" {
+		where += " AND job_type = ?"
+		args = append(args, query.JobType)
+	}
+
+	if query.Source != "" {
+		where += " AND source = ?"
+		args = append(args, query.Source)
+	}
+
+	if query.CompanyID != 0 {
+		where += " AND company_id = ?"
+		args = append(args, query.CompanyID)
+	}
+
+	if query.Seniority != "" {
+		where += " AND seniority = ?"
+		args = append(args, query.Seniority)
+	}
+
+	if query.RoleFamily != "" {
+		where += " AND role_family = ?"
+		args = append(args, query.RoleFamily)
+	}
+
+	if query.MinSalary > 0 {
+		where += " AND salary_max >= ?"
+		args = append(args, query.MinSalary)
+	}
+
+	if query.MinQuality > 0 {
+		where += " AND quality_score >= ?"
+		args = append(args, query.MinQuality)
+	}
+
+	if !query.PostedAfter.IsZero() {
+		where += " AND posted_at >= ?"
+		args = append(args, query.PostedAfter)
+	}
+
+	if !query.PostedBefore.IsZero() {
+		where += " AND posted_at < ?"
+		args = append(args, query.PostedBefore)
+	}
+
+	if !query.UpdatedSince.IsZero() {
+		where += " AND updated_at >= ?"
+		args = append(args, query.UpdatedSince)
+	}
+
+	switch query.Status {
+	case "", models.JobStatusOpen:
+		where += " AND status = 'open'"
+	case models.JobStatusClosed:
+		where += " AND status <> 'open'"
+	}
+
+	if cond := flaggedCondition(query.Flagged, ""); cond != "" {
+		where += " AND " + cond
+	}
+
+	if len(query.Skills) > 0 {
+		where += ` AND id IN (
+			SELECT job_id FROM job_tags WHERE tag IN (` + placeholders(len(query.Skills)) + `)
+			GROUP BY job_id HAVING COUNT(*) = ?
+		)`
+		for _, skill := range query.Skills {
+			args = append(args, skill)
+		}
+		args = append(args, len(query.Skills))
+	}
+
+	if len(query.SkillsAny) > 0 {
+		where += ` AND EXISTS (SELECT 1 FROM job_tags st WHERE st.job_id = jobs.id AND st.tag IN (` + placeholders(len(query.SkillsAny)) + `))`
+		for _, skill := range query.SkillsAny {
+			args = append(args, skill)
+		}
+	}
+
+	return where, args
+}
+
+// sqliteOrderBy returns the ORDER BY expressions of a search, like orderBy
+func sqliteOrderBy(query *models.JobSearchQuery) string {
+	dir := " ASC"
+	if query.Descending() {
+		dir = " DESC"
+	}
+
+	switch query.SortField() {
+	case models.SortScrapedAt:
+		return "scraped_at" + dir + ", id" + dir
+	case models.SortSalary:
+		return "salary_max" + dir + " NULLS LAST, id" + dir
+	case models.SortCompany:
+		return "company" + dir + ", id" + dir
+	}
+	return sqliteNewestDay + dir + ", " + precisionRank + dir + ", posted_at" + dir + ", id" + dir
+}
+
+// placeholders returns n comma-separated parameters
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// attachTags loads the skill tags of jobs
+func (s *SQLiteJobStore) attachTags(ctx context.Context, jobs []*models.Job) error {
+	if len(jobs) == 0 {
+		return nil
+	}
+
+	byID := make(map[int64]*models.Job, len(jobs))
+	args := make([]interface{}, len(jobs))
+	for i, job := range jobs {
+		job.Tags = make([]string, 0)
+		byID[job.ID] = job
+		args[i] = job.ID
+	}
+
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT job_id, tag FROM job_tags WHERE job_id IN (`+placeholders(len(jobs))+`) ORDER BY tag`,
+		args...,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to get job tags: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+		var tag string
+		if err := rows.Scan(&id, &tag); err != nil {
+			return fmt.Errorf("failed to scan job tag: %w", err)
+		}
+		byID[id].Tags = append(byID[id].Tags, tag)
+	}
+
+	return rows.Err()
+}
+
+// GetStats retrieves aggregated job statistics
+func (s *SQLiteJobStore) GetStats(ctx context.Context) (*models.JobStats, error) {
+	stats := &models.JobStats{
+		TopCompanies: make([]models.CompanyCount, 0),
+		TopLocations: make([]models.LocationCount, 0),
+		TopSkills:    make([]models.SkillCount, 0),
+		RefreshedAt:  time.Now(), // Computed on every call
+	}
+
+	err := s.db.QueryRowContext(ctx, `
+		SELECT
+			COUNT(*),
+			COALESCE(SUM(remote_ok), 0),
+			COALESCE(SUM(`+sqliteNewestDay+` = date('now') AND posted_at_precision <> 'unknown'), 0),
+			COALESCE(SUM(status = 'open'), 0),
+			COALESCE(SUM(status = 'closed'), 0),
+			COALESCE(AVG(quality_score), 0)
+		FROM jobs
+	`).Scan(&stats.TotalJobs, &stats.RemoteJobs, &stats.TodayJobs, &stats.OpenJobs, &stats.ClosedJobs, &stats.AvgQuality)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get total jobs: %w", err)
+	}
+
+	// A plain column keeps its TIMESTAMP type, unlike MAX(scraped_at)
+	err = s.db.QueryRowContext(ctx, "SELECT scraped_at FROM jobs ORDER BY scraped_at DESC LIMIT 1").Scan(&stats.LastScrapedAt)
+	if err != nil && err != sql.ErrNoRows {
+		logger.Error("Failed to get last scraped time: %v", err)
+	}
+
+	groups := []struct {
+		column string
+		counts *map[string]int64
+	}{
+		{"source", &stats.JobsBySource},
+		{"job_type", &stats.JobsByType},
+		{"seniority", &stats.JobsBySeniority},
+		{"role_family", &stats.JobsByRoleFamily},
+	}
+	for _, g := range groups {
+		counts, err := s.countBy(ctx, "SELECT "+g.column+", COUNT(*) FROM jobs GROUP BY "+g.column)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get jobs by %s: %w", g.column, err)
+		}
+		*g.counts = counts
+	}
+
+	bands, err := s.countBy(ctx, "SELECT "+qualityBand+", COUNT(*) FROM jobs GROUP BY 1")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get jobs by quality: %w", err)
+	}
+	stats.JobsByQuality = qualityCounts()
+	for band, count := range bands {
+		stats.JobsByQuality[band] = count
+	}
+
+	// Listing lifecycle
+	durations, err := s.closedDurations(ctx)
+	if err != nil {
+		logger.Error("Failed to get listing lifecycle stats: %v", err)
+	}
+	stats.AvgDaysOpen, stats.MedianDaysOpen = daysOpen(durations)
+
+	top, err := s.topCounts(ctx, "SELECT company, COUNT(*) AS cnt FROM jobs GROUP BY company ORDER BY cnt DESC, company LIMIT 10")
+	if err != nil {
+		logger.Error("Failed to get top companies: %v", err)
+	}
+	for _, c := range top {
+		stats.TopCompanies = append(stats.TopCompanies, models.CompanyCount{Company: c.Value, Count: c.Count})
+	}
+
+	top, err = s.topCounts(ctx, "SELECT location, COUNT(*) AS cnt FROM jobs GROUP BY location ORDER BY cnt DESC, location LIMIT 10")
+	if err != nil {
+		logger.Error("Failed to get top locations: %v", err)
+	}
+	for _, c := range top {
+		stats.TopLocations = append(stats.TopLocations, models.LocationCount{Location: c.Value, Count: c.Count})
+	}
+
+	top, err = s.topCounts(ctx, "SELECT tag, COUNT(*) AS cnt FROM job_tags GROUP BY tag ORDER BY cnt DESC, tag LIMIT 25")
+	if err != nil {
+		logger.Error("Failed to get top skills: %v", err)
+	}
+	for _, c := range top {
+		stats.TopSkills = append(stats.TopSkills, models.SkillCount{Skill: c.Value, Count: c.Count})
+	}
+
+	return stats, nil
+}
+
+// countBy runs a two-column grouping query into a map
+func (s *SQLiteJobStore) countBy(ctx context.Context, query string) (map[string]int64, error) {
+	top, err := s.topCounts(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(top))
+	for _, c := range top {
+		counts[c.Value] = c.Count
+	}
+	return counts, nil
+}
+
+// topCounts runs a query returning values and their counts
+func (s *SQLiteJobStore) topCounts(ctx context.Context, query string) ([]models.FacetCount, error) {
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make([]models.FacetCount, 0)
+	for rows.Next() {
+		var c models.FacetCount
+		if err := rows.Scan(&c.Value, &c.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, c)
+	}
+	return counts, rows.Err()
+}
+
+// closedDurations returns how long each closed listing was seen for
+func (s *SQLiteJobStore) closedDurations(ctx context.Context) ([]time.Duration, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT first_seen_at, last_seen_at FROM jobs WHERE status = 'closed'")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var durations []time.Duration
+	for rows.Next() {
+		var first, last time.Time
+		if err := rows.Scan(&first, &last); err != nil {
+			return nil, err
+		}
+		durations = append(durations, last.Sub(first))
+	}
+	return durations, rows.Err()
+}
+
+// CountExpired counts the jobs matching a retention rule whose age column
+// is before cutoff
+func (s *SQLiteJobStore) CountExpired(ctx context.Context, rule *models.RetentionRule, cutoff time.Time) (int64, error) {
+	where, args := expiredWhere(rule, cutoff)
+
+	var count int64
+	err := s.db.QueryRowContext(ctx, sqliteQuery("SELECT COUNT(*) FROM jobs WHERE "+where), sqliteArgs(args)...).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count expired jobs: %w", err)
+	}
+	return count, nil
+}
+
+// DeleteExpired deletes at most limit of the jobs CountExpired counts
+func (s *SQLiteJobStore) DeleteExpired(ctx context.Context, rule *models.RetentionRule, cutoff time.Time, limit int) (int64, error) {
+	where, args := expiredWhere(rule, cutoff)
+	args = append(args, limit)
+
+	result, err := s.db.ExecContext(ctx, sqliteQuery(fmt.Sprintf(
+		"DELETE FROM jobs WHERE id IN (SELECT id FROM jobs WHERE %s ORDER BY id LIMIT $%d)", where, len(args),
+	)), sqliteArgs(args)...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired jobs: %w", err)
+	}
+
+	count, _ := result.RowsAffected()
+	return count, nil
+}
+
+// ExistsByHash checks if a job with the given hash already exists
+func (s *SQLiteJobStore) ExistsByHash(ctx context.Context, hash string) (bool, error) {
+	var exists bool
+	err := s.db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM jobs WHERE hash = ?)", hash).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check job existence: %w", err)
+	}
+
+	return exists, nil
+}
+
+// ReviewFlag records an admin's review of a job's flag and returns the job
+func (s *SQLiteJobStore) ReviewFlag(ctx context.Context, id int64, review string) (*models.Job, error) {
+	result, err := s.db.ExecContext(ctx, "UPDATE jobs SET flag_review = ? WHERE id = ?", review, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to review job flag: %w", err)
+	}
+	if count, _ := result.RowsAffected(); count == 0 {
+		return nil, ErrJobNotFound
+	}
+	return s.FindByID(ctx, id)
+}
+
+// sqliteReplaceTags overwrites the stored skill tags of a job
+func sqliteReplaceTags(ctx context.Context, db execer, jobID int64, tags []string) error {
+	if _, err := db.ExecContext(ctx, "DELETE FROM job_tags WHERE job_id = ?", jobID); err != nil {
+		return fmt.Errorf("failed to clear job tags: %w", err)
+	}
+
+	for _, tag := range tags {
+		if _, err := db.ExecContext(ctx, "INSERT OR IGNORE INTO job_tags (job_id, tag) VALUES (?, ?)", jobID, tag); err != nil {
+			return fmt.Errorf("failed to store job tags: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// escapeLike escapes the LIKE wildcards in a literal
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/abhisheksainimitawa/job-aggregator/internal/models"
+)
+
+// ErrJobNotFound is returned when no job has the requested ID
+var ErrJobNotFound = errors.New("job not found")
+
+// ErrNotSupported is returned for features a store cannot provide, like
+// the advanced query language outside Postgres
+var ErrNotSupported = errors.New("not supported by this storage backend")
+
+// JobStore stores and searches jobs. JobRepository implements it on
+// Postgres; SQLiteJobStore and MemoryJobStore run without a database
+// server. The shared behaviour is tested by the storetest package.
+type JobStore interface {
+	// Create inserts a new job and sets its ID
+	Create(ctx context.Context, job *models.Job) error
+
+	// CreateBatch inserts or updates scraped jobs, matching stored jobs
+	// on (source, native ID) when known and on the content hash otherwise.
+	// Jobs that cannot be stored are reported, with the reason, in the
+	// result and left without an ID; the error is reserved for failures
+	// that store nothing.
+	CreateBatch(ctx context.Context, jobs []*models.Job) (*models.BatchResult, error)
+
+	// FindByID returns ErrJobNotFound for unknown IDs
+	FindByID(ctx context.Context, id int64) (*models.Job, error)
+
+	Search(ctx context.Context, query *models.JobSearchQuery) ([]*models.Job, error)
+	GetStats(ctx context.Context) (*models.JobStats, error)
+
+	// CountExpired counts the jobs matching a retention rule whose age
+	// column is before cutoff
+	CountExpired(ctx context.Context, rule *models.RetentionRule, cutoff time.Time) (int64, error)
+
+	// DeleteExpired deletes at most limit of the jobs CountExpired counts,
+	// with their tags and versions, and returns how many it deleted
+	DeleteExpired(ctx context.Context, rule *models.RetentionRule, cutoff time.Time, limit int) (int64, error)
+
+	ExistsByHash(ctx context.Context, hash string) (bool, error)
+
+	// ReviewFlag records an admin's review of a job's flag, one of the
+	// models.FlagReview values, and returns the job. Reviews survive
+	// re-scrapes. It returns ErrJobNotFound for unknown IDs.
+	ReviewFlag(ctx context.Context, id int64, review string) (*models.Job, error)
+
+	// TimeSeries counts the jobs matching the query's filter that were
+	// posted, and that were listed, during each bucket of the query
+	TimeSeries(ctx context.Context, query *models.TimeSeriesQuery) ([]models.TimeSeriesPoint, error)
+
+	// SalaryStats computes salary percentiles of the jobs matching the
+	// query's filter per group and currency, largest groups first
+	SalaryStats(ctx context.Context, query *models.SalaryStatsQuery) ([]models.SalaryStats, error)
+}
+
+// PageSearcher is implemented by stores that count search totals and
+// issue pagination cursors
+type PageSearcher interface {
+	SearchPage(ctx context.Context, query *models.JobSearchQuery) (*models.JobPage, error)
+}
+
+// FacetCounter is implemented by stores that count facet values
+type FacetCounter interface {
+	Facets(ctx context.Context, query *models.JobSearchQuery, fields []string) (map[string][]models.FacetCount, error)
+}
+
+// StatsRefresher is implemented by stores that precompute GetStats
+type StatsRefresher interface {
+	RefreshStats(ctx context.Context) error
+}
+
+// QualityUpdater is implemented by stores that can rescore stored jobs
+type QualityUpdater interface {
+	SetQuality(ctx context.Context, jobs []*models.Job) error
+}
+
+// HistoryReader is implemented by stores that record job versions
+type HistoryReader interface {
+	History(ctx context.Context, jobID int64) ([]*models.JobVersion, error)
+}
+
+var (
+	_ JobStore       = (*JobRepository)(nil)
+	_ PageSearcher   = (*JobRepository)(nil)
+	_ FacetCounter   = (*JobRepository)(nil)
+	_ HistoryReader  = (*JobRepository)(nil)
+	_ StatsRefresher = (*JobRepository)(nil)
+	_ QualityUpdater = (*JobRepository)(nil)
+	_ JobStore       = (*SQLiteJobStore)(nil)
+	_ JobStore       = (*MemoryJobStore)(nil)
+)
+
+// checkPortable rejects the search features that only Postgres supports:
+// full-text ranking, distance, cursors, salary history and qx
+func checkPortable(query *models.JobSearchQuery) error {
+	switch {
+	case query.Expr != nil:
+		return fmt.Errorf("%w: advanced queries", ErrNotSupported)
+	case query.Cursor != nil:
+		return fmt.Errorf("%w: cursors", ErrNotSupported)
+	case !query.SalaryChangedSince.IsZero():
+		return fmt.Errorf("%w: salary change filter", ErrNotSupported)
+	case query.SortField() == models.SortRelevance, query.SortField() == models.SortDistance:
+		return fmt.Errorf("%w: sorting by %s", ErrNotSupported, query.SortField())
+	}
+	return nil
+}
+
+// flaggedCondition returns the SQL condition of a search's flag filter
+// on columns prefixed with prefix, "" if it has none. It mirrors
+// models.Job.Suspicious.
+func flaggedCondition(filter, prefix string) string {
+	switch filter {
+	case "":
+		return fmt.Sprintf("NOT (%[1]sflag_review = 'scam' OR (%[1]sflagged AND %[1]sflag_review = ''))", prefix)
+	case models.FlaggedOnly:
+		return fmt.Sprintf("(%[1]sflag_review = 'scam' OR (%[1]sflagged AND %[1]sflag_review = ''))", prefix)
+	case models.FlaggedUnreviewed:
+		return fmt.Sprintf("%[1]sflagged AND %[1]sflag_review = ''", prefix)
+	}
+	return ""
+}
+
+// keywordTerms splits search keywords into the words that SQLite and
+// memory stores require in the title, company or description
+func keywordTerms(keywords string) []string {
+	return strings.Fields(strings.ToLower(keywords))
+}
+
+// daysOpen returns the mean and median number of days closed listings
+// were seen for, matching the lifecycle figures of GetStats
+func daysOpen(durations []time.Duration) (avg, median float64) {
+	if len(durations) == 0 {
+		return 0, 0
+	}
+
+	days := make([]float64, len(durations))
+	var sum float64
+	for i, d := range durations {
+		days[i] = d.Hours() / 24
+		sum += days[i]
+	}
+	sort.Float64s(days)
+
+	mid := len(days) / 2
+	median = days[mid]
+	if len(days)%2 == 0 {
+		median = (days[mid-1] + days[mid]) / 2
+	}
+	return sum / float64(len(days)), median
+}
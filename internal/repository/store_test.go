@@ -0,0 +1,298 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/abhisheksainimitawa/job-aggregator/internal/migrate"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/models"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/repository"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/repository/storetest"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/scraper"
+)
+
+func TestMemoryJobStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) repository.JobStore {
+		return repository.NewMemoryJobStore()
+	})
+}
+
+func TestSQLiteJobStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) repository.JobStore {
+		db, err := repository.NewSQLiteDB(":memory:")
+		if err != nil {
+			t.Fatalf("NewSQLiteDB() error = %v", err)
+		}
+		t.Cleanup(func() { db.Close() })
+		return repository.NewSQLiteJobStore(db)
+	})
+}
+
+// TestPostgresJobStore runs against the database in TEST_DATABASE_URL,
+// whose jobs are deleted before every test
+func TestPostgresJobStore(t *testing.T) {
+	db := postgresDB(t)
+	storetest.Run(t, func(t *testing.T) repository.JobStore {
+		truncateJobs(t, db)
+		return repository.NewJobRepository(db)
+	})
+}
+
+// TestPostgresDeleteExpiredClusterHead checks that deleting the canonical
+// job of a cluster hands the cluster to a surviving listing
+func TestPostgresDeleteExpiredClusterHead(t *testing.T) {
+	db := postgresDB(t)
+	truncateJobs(t, db)
+	repo := repository.NewJobRepository(db)
+	ctx := context.Background()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	var jobs []*models.Job
+	for i, source := range []string{"LinkedIn", "Indeed", "Glassdoor"} {
+		jobs = append(jobs, &models.Job{
+			Title: "Go Developer", Company: "Acme", Source: source,
+			URL: fmt.Sprintf("https://example.com/%d", i), Hash: fmt.Sprintf("cluster-%d", i),
+			PostedAt: now.AddDate(0, 0, -100+i), ScrapedAt: now,
+		})
+	}
+	if _, err := repo.CreateBatch(ctx, jobs); err != nil {
+		t.Fatalf("CreateBatch() error = %v", err)
+	}
+	for _, job := range jobs {
+		if err := repo.SetCluster(ctx, job.ID, jobs[0].ID); err != nil {
+			t.Fatalf("SetCluster() error = %v", err)
+		}
+	}
+
+	rule := &models.RetentionRule{Source: "LinkedIn", AfterDays: 90}
+	deleted, err := repo.DeleteExpired(ctx, rule, now.AddDate(0, 0, -90), 100)
+	if err != nil || deleted != 1 {
+		t.Fatalf("DeleteExpired() = %d, %v, want 1", deleted, err)
+	}
+
+	for _, job := range jobs[1:] {
+		got, err := repo.FindByID(ctx, job.ID)
+		if err != nil {
+			t.Fatalf("FindByID(%d) error = %v", job.ID, err)
+		}
+		if got.ClusterID == nil || *got.ClusterID != jobs[1].ID {
+			t.Errorf("job %d cluster = %v, want the new canonical job %d", job.ID, got.ClusterID, jobs[1].ID)
+		}
+	}
+}
+
+// TestPostgresDropDetachedClusterHead checks that dropping an archived
+// partition hands its clusters to listings in attached partitions
+func TestPostgresDropDetachedClusterHead(t *testing.T) {
+	db := postgresDB(t)
+	truncateJobs(t, db)
+	repo := repository.NewJobRepository(db)
+	partitions := repository.NewPartitionRepository(db)
+	ctx := context.Background()
+
+	month := time.Date(2001, time.January, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := partitions.EnsureMonths(ctx, month, 0); err != nil {
+		t.Fatalf("EnsureMonths() error = %v", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	jobs := []*models.Job{
+		{Title: "Go Developer", Company: "Acme", Source: "LinkedIn", URL: "https://example.com/0", Hash: "archived-0", PostedAt: month, ScrapedAt: now},
+		{Title: "Go Developer", Company: "Acme", Source: "Indeed", URL: "https://example.com/1", Hash: "archived-1", PostedAt: now, ScrapedAt: now},
+		{Title: "Go Developer", Company: "Acme", Source: "Glassdoor", URL: "https://example.com/2", Hash: "archived-2", PostedAt: now, ScrapedAt: now},
+	}
+	if _, err := repo.CreateBatch(ctx, jobs); err != nil {
+		t.Fatalf("CreateBatch() error = %v", err)
+	}
+	for _, job := range jobs {
+		if err := repo.SetCluster(ctx, job.ID, jobs[0].ID); err != nil {
+			t.Fatalf("SetCluster() error = %v", err)
+		}
+	}
+
+	if err := partitions.Detach(ctx, month); err != nil {
+		t.Fatalf("Detach() error = %v", err)
+	}
+	if deleted, err := partitions.DropDetached(ctx, month); err != nil || deleted != 1 {
+		t.Fatalf("DropDetached() = %d, %v, want 1", deleted, err)
+	}
+
+	for _, job := range jobs[1:] {
+		got, err := repo.FindByID(ctx, job.ID)
+		if err != nil {
+			t.Fatalf("FindByID(%d) error = %v", job.ID, err)
+		}
+		if got.ClusterID == nil || *got.ClusterID != jobs[1].ID {
+			t.Errorf("job %d cluster = %v, want %d", job.ID, got.ClusterID, jobs[1].ID)
+		}
+	}
+}
+
+// TestPostgresCreateBatchDetached checks that a job matching an archived
+// job is rejected instead of silently dropped
+func TestPostgresCreateBatchDetached(t *testing.T) {
+	db := postgresDB(t)
+	truncateJobs(t, db)
+	repo := repository.NewJobRepository(db)
+	partitions := repository.NewPartitionRepository(db)
+	ctx := context.Background()
+
+	month := time.Date(2001, time.February, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := partitions.EnsureMonths(ctx, month, 0); err != nil {
+		t.Fatalf("EnsureMonths() error = %v", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	newJob := func() *models.Job {
+		return &models.Job{
+			Title: "Go Developer", Company: "Acme", Source: "LinkedIn",
+			URL: "https://example.com/archived", Hash: "archived", PostedAt: month, ScrapedAt: now,
+		}
+	}
+	job := newJob()
+	if _, err := repo.CreateBatch(ctx, []*models.Job{job}); err != nil {
+		t.Fatalf("CreateBatch() error = %v", err)
+	}
+	if err := partitions.Detach(ctx, month); err != nil {
+		t.Fatalf("Detach() error = %v", err)
+	}
+	t.Cleanup(func() { partitions.DropDetached(ctx, month) })
+
+	result, err := repo.CreateBatch(ctx, []*models.Job{newJob()})
+	if err != nil {
+		t.Fatalf("CreateBatch() error = %v", err)
+	}
+	if result.Inserted != 0 || result.Updated != 0 || len(result.Rejected) != 1 || result.Rejected[0].Row != 0 {
+		t.Errorf("CreateBatch() = %+v, want row 0 rejected", result)
+	}
+}
+
+// TestPostgresClusterBatch checks the set-based fingerprint, candidate
+// and cluster statements the clusterer uses
+func TestPostgresClusterBatch(t *testing.T) {
+	db := postgresDB(t)
+	truncateJobs(t, db)
+	repo := repository.NewJobRepository(db)
+	ctx := context.Background()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	var jobs []*models.Job
+	for i := 0; i < 3; i++ {
+		jobs = append(jobs, &models.Job{
+			Title: "Go Developer", Company: "Acme", Source: fmt.Sprintf("source-%d", i),
+			URL: fmt.Sprintf("https://example.com/%d", i), Hash: fmt.Sprintf("batch-%d", i),
+			PostedAt: now, ScrapedAt: now,
+		})
+	}
+	if _, err := repo.CreateBatch(ctx, jobs); err != nil {
+		t.Fatalf("CreateBatch() error = %v", err)
+	}
+
+	// The first two jobs share a band, the third shares none
+	fps := []repository.JobFingerprint{
+		{JobID: jobs[0].ID, Fingerprint: 1, Bands: []int{1, 2, 3, 4}},
+		{JobID: jobs[1].ID, Fingerprint: 2, Bands: []int{1, 5, 6, 7}},
+		{JobID: jobs[2].ID, Fingerprint: 3, Bands: []int{8, 9, 10, 11}},
+	}
+	if err := repo.SetFingerprints(ctx, fps); err != nil {
+		t.Fatalf("SetFingerprints() error = %v", err)
+	}
+	candidates, err := repo.FindClusterCandidates(ctx, fps)
+	if err != nil {
+		t.Fatalf("FindClusterCandidates() error = %v", err)
+	}
+	if got := candidates[jobs[1].ID]; len(got) != 1 || got[0].ID != jobs[0].ID || got[0].Fingerprint != 1 {
+		t.Errorf("candidates of job 2 = %+v, want job 1", got)
+	}
+	if got := candidates[jobs[2].ID]; len(got) != 0 {
+		t.Errorf("candidates of job 3 = %+v, want none", got)
+	}
+
+	ids := []int64{jobs[0].ID, jobs[1].ID, jobs[2].ID}
+	if err := repo.SetClusters(ctx, ids, []int64{jobs[0].ID, jobs[0].ID, jobs[2].ID}); err != nil {
+		t.Fatalf("SetClusters() error = %v", err)
+	}
+	got, err := repo.FindByID(ctx, jobs[1].ID)
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if got.ClusterID == nil || *got.ClusterID != jobs[0].ID {
+		t.Errorf("job 2 cluster = %v, want %d", got.ClusterID, jobs[0].ID)
+	}
+}
+
+// TestPostgresRekeyListings checks that jobs stored before URL
+// canonicalization are re-keyed so their re-scrapes update them
+func TestPostgresRekeyListings(t *testing.T) {
+	db := postgresDB(t)
+	truncateJobs(t, db)
+	repo := repository.NewJobRepository(db)
+	ctx := context.Background()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	old := &models.Job{
+		Title: "Go Developer", Company: "Acme", Source: "LinkedIn",
+		URL: "https://www.linkedin.com/jobs/view/456789?trk=feed", Hash: "content-hash",
+		PostedAt: now, ScrapedAt: now,
+	}
+	if _, err := repo.CreateBatch(ctx, []*models.Job{old}); err != nil {
+		t.Fatalf("CreateBatch() error = %v", err)
+	}
+
+	rekeyed, kept, err := repo.RekeyListings(ctx, scraper.SetListingKeys)
+	if err != nil || rekeyed != 1 || kept != 0 {
+		t.Fatalf("RekeyListings() = %d, %d, %v, want 1 re-keyed", rekeyed, kept, err)
+	}
+
+	rescraped := &models.Job{
+		Title: "Go Developer", Company: "Acme", Source: "LinkedIn",
+		URL: "https://www.linkedin.com/jobs/view/456789?trk=search", PostedAt: now, ScrapedAt: now,
+	}
+	scraper.SetListingKeys(rescraped)
+	result, err := repo.CreateBatch(ctx, []*models.Job{rescraped})
+	if err != nil {
+		t.Fatalf("CreateBatch() error = %v", err)
+	}
+	if result.Updated != 1 || result.Inserted != 0 || rescraped.ID != old.ID {
+		t.Errorf("re-scrape = %+v with ID %d, want an update of job %d", result, rescraped.ID, old.ID)
+	}
+
+	// Re-keyed jobs are not read again
+	if rekeyed, kept, err := repo.RekeyListings(ctx, scraper.SetListingKeys); err != nil || rekeyed+kept != 0 {
+		t.Errorf("second RekeyListings() = %d, %d, %v, want none", rekeyed, kept, err)
+	}
+}
+
+// postgresDB connects to TEST_DATABASE_URL and migrates it, skipping when
+// it is not set
+func postgresDB(tb testing.TB) *sql.DB {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		tb.Skip("TEST_DATABASE_URL not set")
+	}
+
+	db, err := repository.NewDB(dsn)
+	if err != nil {
+		tb.Fatalf("NewDB() error = %v", err)
+	}
+	tb.Cleanup(func() { db.Close() })
+
+	migrator, err := migrate.New(db)
+	if err != nil {
+		tb.Fatalf("migrate.New() error = %v", err)
+	}
+	if _, err := migrator.Up(context.Background(), 0); err != nil {
+		tb.Fatalf("Up() error = %v", err)
+	}
+	return db
+}
+
+func truncateJobs(tb testing.TB, db *sql.DB) {
+	if _, err := db.Exec("TRUNCATE jobs, job_keys RESTART IDENTITY CASCADE"); err != nil {
+		tb.Fatalf("failed to empty jobs: %v", err)
+	}
+}
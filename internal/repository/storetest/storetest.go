@@ -0,0 +1,662 @@
+// Package storetest is the conformance suite of repository.JobStore. Every
+// store implementation runs it, so the API behaves the same on each.
+package storetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/abhisheksainimitawa/job-aggregator/internal/models"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/repository"
+)
+
+// Run runs the suite. newStore must return an empty store for each test.
+func Run(t *testing.T, newStore func(t *testing.T) repository.JobStore) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, store repository.JobStore)
+	}{
+		{"CreateAndFind", testCreateAndFind},
+		{"CreateDuplicateHash", testCreateDuplicateHash},
+		{"FindMissing", testFindMissing},
+		{"CreateBatchUpsert", testCreateBatchUpsert},
+		{"CreateBatchNativeID", testCreateBatchNativeID},
+		{"CreateBatchAdoptsNativeID", testCreateBatchAdoptsNativeID},
+		{"CreateBatchRejects", testCreateBatchRejects},
+		{"Search", testSearch},
+		{"SearchPaging", testSearchPaging},
+		{"GetStats", testGetStats},
+		{"DeleteExpired", testDeleteExpired},
+		{"TimeSeries", testTimeSeries},
+		{"SalaryStats", testSalaryStats},
+		{"Flags", testFlags},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newStore(t))
+		})
+	}
+}
+
+// now is truncated so every store returns the stored times unchanged
+var now = time.Now().UTC().Truncate(time.Second)
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
+
+// fixtures returns three jobs posted one hour, one day and three days ago
+func fixtures() []*models.Job {
+	return []*models.Job{
+		{
+			Title: "Senior Golang Engineer", Company: "Acme", Location: "Berlin, Germany",
+			Description: "Build APIs in Go", URL: "https://example.com/jobs/1", Source: "indeed",
+			RemoteOk: true, JobType: "full-time", PostedAt: now.Add(-time.Hour), ScrapedAt: now,
+			Hash: "hash-1", PostedAtPrecision: "exact", Seniority: "senior", RoleFamily: "engineering",
+			SalaryMin: int64Ptr(90000), SalaryMax: int64Ptr(120000), SalaryCurrency: "USD",
+			Tags: []string{"kubernetes", "go"}, QualityScore: 85,
+			QualityReasons: []models.QualityReason{{Factor: "salary", Points: 20}, {Factor: "sources", Detail: "listed on 1 source"}},
+		},
+		{
+			Title: "Frontend Developer", Company: "Beta", Location: "London, UK",
+			Description: "Build user interfaces", URL: "https://example.com/jobs/2", Source: "linkedin",
+			JobType: "contract", PostedAt: now.Add(-24 * time.Hour), ScrapedAt: now.Add(-time.Minute),
+			Hash: "hash-2", PostedAtPrecision: "exact", Seniority: "mid", RoleFamily: "engineering",
+			SalaryMin: int64Ptr(60000), SalaryMax: int64Ptr(70000), SalaryCurrency: "USD",
+			Tags: []string{"react", "typescript"}, QualityScore: 55,
+		},
+		{
+			Title: "Data Engineer", Company: "Gamma", Location: "Berlin, Germany",
+			Description: "Build data pipelines", URL: "https://example.com/jobs/3", Source: "indeed",
+			JobType: "full-time", PostedAt: now.Add(-72 * time.Hour), ScrapedAt: now.Add(-2 * time.Minute),
+			Hash: "hash-3", PostedAtPrecision: "exact", Seniority: "mid", RoleFamily: "data",
+			Tags: []string{"go", "python"}, QualityScore: 30,
+		},
+	}
+}
+
+// seed stores the fixtures and returns them with their IDs set
+func seed(t *testing.T, store repository.JobStore) []*models.Job {
+	t.Helper()
+	jobs := fixtures()
+	result, err := store.CreateBatch(context.Background(), jobs)
+	if err != nil {
+		t.Fatalf("CreateBatch() error = %v", err)
+	}
+	if result.Inserted != len(jobs) || result.Updated != 0 || len(result.Rejected) != 0 {
+		t.Fatalf("CreateBatch() = %+v, want %d inserted", result, len(jobs))
+	}
+	for _, job := range jobs {
+		if job.ID == 0 {
+			t.Fatalf("CreateBatch() left job %q without an ID", job.Title)
+		}
+	}
+	return jobs
+}
+
+// getStats returns the current stats, refreshing them first in stores
+// that precompute them
+func getStats(store repository.JobStore) (*models.JobStats, error) {
+	if refresher, ok := store.(repository.StatsRefresher); ok {
+		if err := refresher.RefreshStats(context.Background()); err != nil {
+			return nil, err
+		}
+	}
+	return store.GetStats(context.Background())
+}
+
+func find(t *testing.T, store repository.JobStore, id int64) *models.Job {
+	t.Helper()
+	job, err := store.FindByID(context.Background(), id)
+	if err != nil {
+		t.Fatalf("FindByID(%d) error = %v", id, err)
+	}
+	return job
+}
+
+func testCreateAndFind(t *testing.T, store repository.JobStore) {
+	job := fixtures()[0]
+	if err := store.Create(context.Background(), job); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if job.ID == 0 {
+		t.Fatal("Create() did not set the ID")
+	}
+
+	got := find(t, store, job.ID)
+	if got.Title != job.Title || got.Company != job.Company || got.Source != job.Source || !got.RemoteOk {
+		t.Errorf("FindByID() = %+v, want %+v", got, job)
+	}
+	if !got.PostedAt.Equal(job.PostedAt) {
+		t.Errorf("PostedAt = %v, want %v", got.PostedAt, job.PostedAt)
+	}
+	if got.SalaryMax == nil || *got.SalaryMax != 120000 || got.SalaryCurrency != "USD" {
+		t.Errorf("salary = %v %q, want 120000 USD", got.SalaryMax, got.SalaryCurrency)
+	}
+	if got.Status != models.JobStatusOpen {
+		t.Errorf("Status = %q, want open", got.Status)
+	}
+	if got.QualityScore != job.QualityScore || !reflect.DeepEqual(got.QualityReasons, job.QualityReasons) {
+		t.Errorf("quality = %d %+v, want %d %+v", got.QualityScore, got.QualityReasons, job.QualityScore, job.QualityReasons)
+	}
+	if want := []string{"go", "kubernetes"}; !reflect.DeepEqual(got.Tags, want) {
+		t.Errorf("Tags = %v, want %v", got.Tags, want)
+	}
+
+	exists, err := store.ExistsByHash(context.Background(), job.Hash)
+	if err != nil || !exists {
+		t.Errorf("ExistsByHash(%q) = %v, %v, want true", job.Hash, exists, err)
+	}
+	exists, err = store.ExistsByHash(context.Background(), "missing")
+	if err != nil || exists {
+		t.Errorf("ExistsByHash(missing) = %v, %v, want false", exists, err)
+	}
+}
+
+func testCreateDuplicateHash(t *testing.T, store repository.JobStore) {
+	if err := store.Create(context.Background(), fixtures()[0]); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := store.Create(context.Background(), fixtures()[0]); err == nil {
+		t.Error("Create() of a duplicate hash succeeded")
+	}
+}
+
+func testFindMissing(t *testing.T, store repository.JobStore) {
+	if _, err := store.FindByID(context.Background(), 424242); !errors.Is(err, repository.ErrJobNotFound) {
+		t.Errorf("FindByID() error = %v, want ErrJobNotFound", err)
+	}
+}
+
+func testCreateBatchUpsert(t *testing.T, store repository.JobStore) {
+	jobs := seed(t, store)
+
+	again := fixtures()[0]
+	again.Title = "Staff Golang Engineer"
+	again.Tags = []string{"rust"}
+	result, err := store.CreateBatch(context.Background(), []*models.Job{again})
+	if err != nil {
+		t.Fatalf("CreateBatch() error = %v", err)
+	}
+	if result.Inserted != 0 || result.Updated != 1 {
+		t.Errorf("CreateBatch() = %+v, want 1 updated", result)
+	}
+	if again.ID != jobs[0].ID {
+		t.Fatalf("re-scraped job got ID %d, want %d", again.ID, jobs[0].ID)
+	}
+
+	got := find(t, store, again.ID)
+	if got.Title != "Staff Golang Engineer" {
+		t.Errorf("Title = %q, want the updated title", got.Title)
+	}
+	if want := []string{"rust"}; !reflect.DeepEqual(got.Tags, want) {
+		t.Errorf("Tags = %v, want %v", got.Tags, want)
+	}
+
+	stats, err := getStats(store)
+	if err != nil {
+		t.Fatalf("GetStats() error = %v", err)
+	}
+	if stats.TotalJobs != 3 {
+		t.Errorf("TotalJobs = %d after re-scrape, want 3", stats.TotalJobs)
+	}
+}
+
+func testCreateBatchNativeID(t *testing.T, store repository.JobStore) {
+	job := fixtures()[0]
+	job.NativeID = "abc123"
+	if _, err := store.CreateBatch(context.Background(), []*models.Job{job}); err != nil {
+		t.Fatalf("CreateBatch() error = %v", err)
+	}
+
+	// A new title changes the hash, the board's ID still matches
+	moved := fixtures()[0]
+	moved.NativeID = "abc123"
+	moved.Title = "Golang Engineer"
+	moved.Hash = "hash-1-renamed"
+	if _, err := store.CreateBatch(context.Background(), []*models.Job{moved}); err != nil {
+		t.Fatalf("CreateBatch() error = %v", err)
+	}
+	if moved.ID != job.ID {
+		t.Fatalf("job with the same native ID got ID %d, want %d", moved.ID, job.ID)
+	}
+	if got := find(t, store, job.ID); got.Title != "Golang Engineer" {
+		t.Errorf("Title = %q, want the updated title", got.Title)
+	}
+}
+
+func testCreateBatchAdoptsNativeID(t *testing.T, store repository.JobStore) {
+	jobs := seed(t, store)
+
+	// The board started exposing IDs after the job was stored
+	job := fixtures()[2]
+	job.NativeID = "xyz789"
+	result, err := store.CreateBatch(context.Background(), []*models.Job{job})
+	if err != nil {
+		t.Fatalf("CreateBatch() error = %v", err)
+	}
+	if result.Updated != 1 || result.Inserted != 0 || len(result.Rejected) != 0 {
+		t.Fatalf("CreateBatch() = %+v, want 1 updated", result)
+	}
+	if job.ID != jobs[2].ID {
+		t.Fatalf("job got ID %d, want the stored job %d", job.ID, jobs[2].ID)
+	}
+	if got := find(t, store, job.ID); got.NativeID != "xyz789" {
+		t.Errorf("NativeID = %q, want the adopted ID", got.NativeID)
+	}
+
+	// Later scrapes match on the adopted ID
+	moved := fixtures()[2]
+	moved.NativeID = "xyz789"
+	moved.Hash = "hash-3-moved"
+	if _, err := store.CreateBatch(context.Background(), []*models.Job{moved}); err != nil {
+		t.Fatalf("CreateBatch() error = %v", err)
+	}
+	if moved.ID != jobs[2].ID {
+		t.Errorf("job with the adopted native ID got ID %d, want %d", moved.ID, jobs[2].ID)
+	}
+}
+
+func testCreateBatchRejects(t *testing.T, store repository.JobStore) {
+	seed(t, store)
+
+	untitled := fixtures()[1]
+	untitled.Title = ""
+	untitled.Hash = "hash-untitled"
+
+	// A new board ID cannot take the hash of another source's job
+	taken := fixtures()[2]
+	taken.NativeID = "xyz789"
+	taken.Source = "linkedin"
+
+	first := fixtures()[0]
+	first.Hash = "hash-4"
+	first.URL = "https://example.com/jobs/4"
+	last := fixtures()[0]
+	last.Hash = "hash-4"
+	last.URL = "https://example.com/jobs/4"
+	last.Title = "Principal Golang Engineer"
+
+	jobs := []*models.Job{untitled, taken, first, last}
+	result, err := store.CreateBatch(context.Background(), jobs)
+	if err != nil {
+		t.Fatalf("CreateBatch() error = %v", err)
+	}
+	if result.Inserted != 1 || result.Updated != 0 {
+		t.Errorf("CreateBatch() = %+v, want 1 inserted", result)
+	}
+
+	var rows []int
+	for _, r := range result.Rejected {
+		if r.Reason == "" || r.Hash != jobs[r.Row].Hash {
+			t.Errorf("rejection %+v lacks a reason or hash", r)
+		}
+		rows = append(rows, r.Row)
+	}
+	if want := []int{0, 1, 2}; !reflect.DeepEqual(rows, want) {
+		t.Errorf("rejected rows = %v, want %v", rows, want)
+	}
+	for _, job := range jobs[:3] {
+		if job.ID != 0 {
+			t.Errorf("rejected job %q got ID %d", job.Title, job.ID)
+		}
+	}
+
+	// The last of the duplicates is the one stored
+	if last.ID == 0 {
+		t.Fatal("CreateBatch() left the last duplicate without an ID")
+	}
+	if got := find(t, store, last.ID); got.Title != last.Title {
+		t.Errorf("Title = %q, want %q", got.Title, last.Title)
+	}
+}
+
+func testSearch(t *testing.T, store repository.JobStore) {
+	jobs := seed(t, store)
+	remote, onSite := true, false
+
+	tests := []struct {
+		name  string
+		query models.JobSearchQuery
+		want  []int // fixture indexes, in order
+	}{
+		{"all newest first", models.JobSearchQuery{}, []int{0, 1, 2}},
+		{"keywords", models.JobSearchQuery{Keywords: "golang"}, []int{0}},
+		{"keywords in company", models.JobSearchQuery{Keywords: "gamma"}, []int{2}},
+		{"location", models.JobSearchQuery{Location: "berlin"}, []int{0, 2}},
+		{"remote", models.JobSearchQuery{Remote: &remote}, []int{0}},
+		{"on site", models.JobSearchQuery{Remote: &onSite}, []int{1, 2}},
+		{"job type", models.JobSearchQuery{JobType: "contract"}, []int{1}},
+		{"source", models.JobSearchQuery{Source: "indeed"}, []int{0, 2}},
+		{"seniority", models.JobSearchQuery{Seniority: "mid"}, []int{1, 2}},
+		{"role family", models.JobSearchQuery{RoleFamily: "data"}, []int{2}},
+		{"min salary", models.JobSearchQuery{MinSalary: 100000}, []int{0}},
+		{"min quality", models.JobSearchQuery{MinQuality: 55}, []int{0, 1}},
+		{"all skills", models.JobSearchQuery{Skills: []string{"go", "kubernetes"}}, []int{0}},
+		{"any skill", models.JobSearchQuery{SkillsAny: []string{"react", "python"}}, []int{1, 2}},
+		{"posted after", models.JobSearchQuery{PostedAfter: now.Add(-48 * time.Hour)}, []int{0, 1}},
+		{"posted before", models.JobSearchQuery{PostedBefore: now.Add(-48 * time.Hour)}, []int{2}},
+		{"closed", models.JobSearchQuery{Status: models.JobStatusClosed}, []int{}},
+		{"all statuses", models.JobSearchQuery{Status: "all"}, []int{0, 1, 2}},
+		{"oldest first", models.JobSearchQuery{Order: models.OrderAsc}, []int{2, 1, 0}},
+		{"scraped at", models.JobSearchQuery{Sort: models.SortScrapedAt, Order: models.OrderAsc}, []int{2, 1, 0}},
+		{"salary", models.JobSearchQuery{Sort: models.SortSalary}, []int{0, 1, 2}},
+		{"salary ascending", models.JobSearchQuery{Sort: models.SortSalary, Order: models.OrderAsc}, []int{1, 0, 2}},
+		{"company", models.JobSearchQuery{Sort: models.SortCompany}, []int{0, 1, 2}},
+		{"company descending", models.JobSearchQuery{Sort: models.SortCompany, Order: models.OrderDesc}, []int{2, 1, 0}},
+		{"combined", models.JobSearchQuery{Location: "berlin", Skills: []string{"python"}}, []int{2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := tt.query
+			got, err := store.Search(context.Background(), &query)
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+
+			want := make([]string, len(tt.want))
+			for i, idx := range tt.want {
+				want[i] = jobs[idx].Title
+			}
+			if titles := titlesOf(got); !reflect.DeepEqual(titles, want) {
+				t.Errorf("Search() = %v, want %v", titles, want)
+			}
+		})
+	}
+}
+
+func testSearchPaging(t *testing.T, store repository.JobStore) {
+	jobs := seed(t, store)
+
+	for page, want := range [][]string{
+		{jobs[0].Title, jobs[1].Title},
+		{jobs[2].Title},
+		{},
+	} {
+		got, err := store.Search(context.Background(), &models.JobSearchQuery{Page: page, Limit: 2})
+		if err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+		if titles := titlesOf(got); !reflect.DeepEqual(titles, want) {
+			t.Errorf("page %d = %v, want %v", page, titles, want)
+		}
+	}
+}
+
+func titlesOf(jobs []*models.Job) []string {
+	titles := make([]string, len(jobs))
+	for i, job := range jobs {
+		titles[i] = job.Title
+	}
+	return titles
+}
+
+func testGetStats(t *testing.T, store repository.JobStore) {
+	seed(t, store)
+
+	stats, err := getStats(store)
+	if err != nil {
+		t.Fatalf("GetStats() error = %v", err)
+	}
+
+	if stats.TotalJobs != 3 || stats.RemoteJobs != 1 || stats.OpenJobs != 3 || stats.ClosedJobs != 0 {
+		t.Errorf("counts = total %d, remote %d, open %d, closed %d, want 3, 1, 3, 0",
+			stats.TotalJobs, stats.RemoteJobs, stats.OpenJobs, stats.ClosedJobs)
+	}
+	if want := map[string]int64{"indeed": 2, "linkedin": 1}; !reflect.DeepEqual(stats.JobsBySource, want) {
+		t.Errorf("JobsBySource = %v, want %v", stats.JobsBySource, want)
+	}
+	if want := map[string]int64{"full-time": 2, "contract": 1}; !reflect.DeepEqual(stats.JobsByType, want) {
+		t.Errorf("JobsByType = %v, want %v", stats.JobsByType, want)
+	}
+	if want := map[string]int64{"engineering": 2, "data": 1}; !reflect.DeepEqual(stats.JobsByRoleFamily, want) {
+		t.Errorf("JobsByRoleFamily = %v, want %v", stats.JobsByRoleFamily, want)
+	}
+	if !stats.LastScrapedAt.Equal(now) {
+		t.Errorf("LastScrapedAt = %v, want %v", stats.LastScrapedAt, now)
+	}
+	if len(stats.TopCompanies) != 3 {
+		t.Errorf("TopCompanies = %v, want 3 companies", stats.TopCompanies)
+	}
+	if len(stats.TopLocations) == 0 || stats.TopLocations[0] != (models.LocationCount{Location: "Berlin, Germany", Count: 2}) {
+		t.Errorf("TopLocations = %v, want Berlin first with 2", stats.TopLocations)
+	}
+	if len(stats.TopSkills) != 5 || stats.TopSkills[0] != (models.SkillCount{Skill: "go", Count: 2}) {
+		t.Errorf("TopSkills = %v, want 5 skills with go first", stats.TopSkills)
+	}
+	if want := map[string]int64{"0-19": 0, "20-39": 1, "40-59": 1, "60-79": 0, "80-100": 1}; !reflect.DeepEqual(stats.JobsByQuality, want) {
+		t.Errorf("JobsByQuality = %v, want %v", stats.JobsByQuality, want)
+	}
+	if math.Abs(stats.AvgQuality-170.0/3) > 0.01 {
+		t.Errorf("AvgQuality = %v, want 56.67", stats.AvgQuality)
+	}
+}
+
+func testDeleteExpired(t *testing.T, store repository.JobStore) {
+	jobs := seed(t, store)
+
+	// Two old indeed jobs and an old linkedin one
+	var old []*models.Job
+	for i, source := range []string{"indeed", "indeed", "linkedin"} {
+		job := fixtures()[0]
+		job.Hash = fmt.Sprintf("hash-old-%d", i)
+		job.Source = source
+		job.PostedAt = now.AddDate(0, 0, -40)
+		if err := store.Create(context.Background(), job); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		old = append(old, job)
+	}
+
+	rule := &models.RetentionRule{Name: "indeed", Source: "indeed", AfterDays: 30}
+	cutoff := now.AddDate(0, 0, -30)
+	count, err := store.CountExpired(context.Background(), rule, cutoff)
+	if err != nil {
+		t.Fatalf("CountExpired() error = %v", err)
+	}
+	if count != 2 {
+		t.Errorf("CountExpired() = %d, want 2", count)
+	}
+
+	closed := &models.RetentionRule{Name: "closed", Status: models.JobStatusClosed, AfterDays: 1}
+	if count, err := store.CountExpired(context.Background(), closed, now); err != nil || count != 0 {
+		t.Errorf("CountExpired() of closed jobs = %d, %v, want 0", count, err)
+	}
+
+	// One job per batch
+	for i, want := range []int64{1, 1, 0} {
+		deleted, err := store.DeleteExpired(context.Background(), rule, cutoff, 1)
+		if err != nil {
+			t.Fatalf("DeleteExpired() error = %v", err)
+		}
+		if deleted != want {
+			t.Errorf("DeleteExpired() batch %d = %d, want %d", i, deleted, want)
+		}
+	}
+
+	for _, job := range old[:2] {
+		if _, err := store.FindByID(context.Background(), job.ID); !errors.Is(err, repository.ErrJobNotFound) {
+			t.Errorf("FindByID() of a deleted job error = %v, want ErrJobNotFound", err)
+		}
+		if exists, _ := store.ExistsByHash(context.Background(), job.Hash); exists {
+			t.Error("ExistsByHash() of a deleted job = true")
+		}
+	}
+	find(t, store, old[2].ID)
+	for _, job := range jobs {
+		find(t, store, job.ID)
+	}
+}
+
+func testTimeSeries(t *testing.T, store repository.JobStore) {
+	jobs := seed(t, store)
+
+	query := &models.TimeSeriesQuery{
+		Filter:   &models.JobSearchQuery{Status: "all"},
+		Interval: models.IntervalDay,
+		From:     now.AddDate(0, 0, -4),
+		To:       now,
+	}
+	points, err := store.TimeSeries(context.Background(), query)
+	if err != nil {
+		t.Fatalf("TimeSeries() error = %v", err)
+	}
+	if len(points) != 5 {
+		t.Fatalf("TimeSeries() = %d points, want 5", len(points))
+	}
+
+	// Every fixture is still open, so it is active from its posting day on
+	for _, p := range points {
+		var wantNew, wantActive int64
+		for _, job := range jobs {
+			day := models.BucketStart(job.PostedAt, models.IntervalDay)
+			if day.Equal(p.Start) {
+				wantNew++
+			}
+			if !day.After(p.Start) {
+				wantActive++
+			}
+		}
+		if p.New != wantNew || p.Active != wantActive {
+			t.Errorf("point %s = %d new, %d active, want %d, %d",
+				p.Start.Format("2006-01-02"), p.New, p.Active, wantNew, wantActive)
+		}
+	}
+
+	query.Filter = &models.JobSearchQuery{Status: "all", Source: "linkedin"}
+	query.Interval = models.IntervalMonth
+	query.From = now.AddDate(0, 0, -3)
+	points, err = store.TimeSeries(context.Background(), query)
+	if err != nil {
+		t.Fatalf("TimeSeries() error = %v", err)
+	}
+	var total int64
+	for _, p := range points {
+		total += p.New
+	}
+	if total != 1 {
+		t.Errorf("TimeSeries() of linkedin = %d new jobs, want 1", total)
+	}
+}
+
+func testSalaryStats(t *testing.T, store repository.JobStore) {
+	seed(t, store)
+	ctx := context.Background()
+
+	// Midpoints of 105000 and 65000 USD; the third job has no salary
+	stats, err := store.SalaryStats(ctx, &models.SalaryStatsQuery{Filter: &models.JobSearchQuery{}, MinSamples: 1})
+	if err != nil {
+		t.Fatalf("SalaryStats() error = %v", err)
+	}
+	if len(stats) != 1 {
+		t.Fatalf("SalaryStats() = %+v, want one group", stats)
+	}
+	got := stats[0]
+	if got.Currency != "USD" || got.Samples != 2 || got.P10 != 69000 || got.P25 != 75000 ||
+		got.Median != 85000 || got.P75 != 95000 || got.P90 != 101000 {
+		t.Errorf("SalaryStats() = %+v, want 2 USD samples from 69000 to 101000", got)
+	}
+
+	query := &models.SalaryStatsQuery{
+		Filter:     &models.JobSearchQuery{},
+		GroupBy:    []string{"location", "remote_ok"},
+		MinSamples: 1,
+	}
+	stats, err = store.SalaryStats(ctx, query)
+	if err != nil {
+		t.Fatalf("SalaryStats() error = %v", err)
+	}
+	if len(stats) != 2 {
+		t.Fatalf("SalaryStats() by location = %+v, want two groups", stats)
+	}
+	if g := stats[0].Group; g["location"] != "Berlin, Germany" || g["remote_ok"] != "true" || stats[0].Median != 105000 {
+		t.Errorf("first group = %+v, want Berlin remote at 105000", stats[0])
+	}
+
+	query.MinSamples = 2
+	if stats, err = store.SalaryStats(ctx, query); err != nil || len(stats) != 0 {
+		t.Errorf("SalaryStats() below the threshold = %+v, %v, want none", stats, err)
+	}
+
+	query.GroupBy = nil
+	query.MinSamples = 1
+	query.Filter = &models.JobSearchQuery{Source: "linkedin"}
+	stats, err = store.SalaryStats(ctx, query)
+	if err != nil {
+		t.Fatalf("SalaryStats() error = %v", err)
+	}
+	if len(stats) != 1 || stats[0].Samples != 1 || stats[0].Median != 65000 {
+		t.Errorf("SalaryStats() of linkedin = %+v, want one sample at 65000", stats)
+	}
+}
+
+func testFlags(t *testing.T, store repository.JobStore) {
+	jobs := seed(t, store)
+
+	scam := fixtures()[2]
+	scam.Title, scam.Hash, scam.URL = "Easy Money From Home", "hash-scam", "https://example.com/jobs/4"
+	scam.PostedAt = now.Add(-96 * time.Hour)
+	scam.Flagged, scam.FlagReasons = true, []string{`payment_request: "registration fee"`}
+	if err := store.Create(context.Background(), scam); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if got := find(t, store, scam.ID); !got.Flagged || !reflect.DeepEqual(got.FlagReasons, scam.FlagReasons) || got.FlagReview != "" {
+		t.Errorf("flag = %v %q %q, want flagged and unreviewed", got.Flagged, got.FlagReasons, got.FlagReview)
+	}
+
+	search := func(t *testing.T, flagged string, want ...string) {
+		t.Helper()
+		got, err := store.Search(context.Background(), &models.JobSearchQuery{Flagged: flagged})
+		if err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+		if want == nil {
+			want = []string{}
+		}
+		if titles := titlesOf(got); !reflect.DeepEqual(titles, want) {
+			t.Errorf("Search(flagged=%q) = %v, want %v", flagged, titles, want)
+		}
+	}
+
+	search(t, "", jobs[0].Title, jobs[1].Title, jobs[2].Title)
+	search(t, models.FlaggedInclude, jobs[0].Title, jobs[1].Title, jobs[2].Title, scam.Title)
+	search(t, models.FlaggedOnly, scam.Title)
+	search(t, models.FlaggedUnreviewed, scam.Title)
+
+	// A cleared job is shown and its review outlives re-scrapes
+	got, err := store.ReviewFlag(context.Background(), scam.ID, models.FlagReviewLegit)
+	if err != nil {
+		t.Fatalf("ReviewFlag() error = %v", err)
+	}
+	if got.ID != scam.ID || got.FlagReview != models.FlagReviewLegit {
+		t.Errorf("ReviewFlag() = job %d reviewed %q, want job %d reviewed legit", got.ID, got.FlagReview, scam.ID)
+	}
+	again := *scam
+	again.ID = 0
+	if _, err := store.CreateBatch(context.Background(), []*models.Job{&again}); err != nil {
+		t.Fatalf("CreateBatch() error = %v", err)
+	}
+	if got := find(t, store, scam.ID); !got.Flagged || got.FlagReview != models.FlagReviewLegit {
+		t.Errorf("after re-scrape flag = %v reviewed %q, want flagged and reviewed legit", got.Flagged, got.FlagReview)
+	}
+	search(t, "", jobs[0].Title, jobs[1].Title, jobs[2].Title, scam.Title)
+	search(t, models.FlaggedUnreviewed)
+
+	// A job confirmed as a scam is hidden even though it was not flagged
+	if _, err := store.ReviewFlag(context.Background(), jobs[1].ID, models.FlagReviewScam); err != nil {
+		t.Fatalf("ReviewFlag() error = %v", err)
+	}
+	search(t, "", jobs[0].Title, jobs[2].Title, scam.Title)
+	search(t, models.FlaggedOnly, jobs[1].Title)
+
+	if _, err := store.ReviewFlag(context.Background(), 424242, models.FlagReviewScam); !errors.Is(err, repository.ErrJobNotFound) {
+		t.Errorf("ReviewFlag() error = %v, want ErrJobNotFound", err)
+	}
+}
@@ -0,0 +1,78 @@
+package retention
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/abhisheksainimitawa/job-aggregator/internal/models"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/repository"
+)
+
+// Engine applies retention rules to a job store
+type Engine struct {
+	store     repository.JobStore
+	rules     []models.RetentionRule
+	batchSize int
+}
+
+// NewEngine creates an engine deleting the jobs matching rules at most
+// batchSize at a time, so no delete holds its locks for long
+func NewEngine(store repository.JobStore, rules []models.RetentionRule, batchSize int) *Engine {
+	if batchSize < 1 {
+		batchSize = 1000
+	}
+	return &Engine{store: store, rules: rules, batchSize: batchSize}
+}
+
+// Rules returns the rules of the engine
+func (e *Engine) Rules() []models.RetentionRule {
+	return e.rules
+}
+
+// Run applies the rules in order, measuring ages from now. A dry run only
+// counts the jobs each rule matches. The returned run reports the work
+// done before any error.
+func (e *Engine) Run(ctx context.Context, now time.Time, dryRun bool) (*models.RetentionRun, error) {
+	run := &models.RetentionRun{StartedAt: now, DryRun: dryRun, Rules: make([]models.RetentionRuleResult, 0, len(e.rules))}
+	defer func() { run.FinishedAt = time.Now() }()
+
+	for i := range e.rules {
+		rule := &e.rules[i]
+		result := models.RetentionRuleResult{Rule: *rule, Cutoff: now.AddDate(0, 0, -rule.AfterDays)}
+
+		err := e.apply(ctx, rule, &result, dryRun)
+		run.Rules = append(run.Rules, result)
+		run.Deleted += result.Deleted
+		if err != nil {
+			run.Error = fmt.Sprintf("rule %q: %v", rule.Name, err)
+			return run, fmt.Errorf("retention rule %q failed: %w", rule.Name, err)
+		}
+	}
+	return run, nil
+}
+
+// apply counts or deletes the jobs of one rule
+func (e *Engine) apply(ctx context.Context, rule *models.RetentionRule, result *models.RetentionRuleResult, dryRun bool) error {
+	if dryRun {
+		count, err := e.store.CountExpired(ctx, rule, result.Cutoff)
+		result.Matched = count
+		return err
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		deleted, err := e.store.DeleteExpired(ctx, rule, result.Cutoff, e.batchSize)
+		if err != nil {
+			return err
+		}
+		result.Deleted += deleted
+		result.Batches++
+		if deleted < int64(e.batchSize) {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package retention
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/abhisheksainimitawa/job-aggregator/internal/models"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/repository"
+)
+
+// newStore returns a memory store with five indeed jobs and one linkedin
+// job posted 100 days before now, and one recent indeed job
+func newStore(t *testing.T, now time.Time) repository.JobStore {
+	t.Helper()
+	store := repository.NewMemoryJobStore()
+	add := func(i int, source string, posted time.Time) {
+		job := &models.Job{
+			Title: "Engineer", Company: "Acme", Location: "Remote", Description: "Build things",
+			URL: fmt.Sprintf("https://example.com/%d", i), Source: source, JobType: "full-time",
+			PostedAt: posted, ScrapedAt: now, Hash: fmt.Sprintf("hash-%d", i),
+		}
+		if err := store.Create(context.Background(), job); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+	for i := 0; i < 5; i++ {
+		add(i, "indeed", now.AddDate(0, 0, -100))
+	}
+	add(5, "linkedin", now.AddDate(0, 0, -100))
+	add(6, "indeed", now.AddDate(0, 0, -1))
+	return store
+}
+
+func TestEngineRun(t *testing.T) {
+	now := time.Now()
+	rules := []models.RetentionRule{
+		{Name: "indeed", Source: "indeed", AfterDays: 90},
+		{Name: "everything", AfterDays: 365},
+	}
+
+	store := newStore(t, now)
+	engine := NewEngine(store, rules, 2)
+
+	dry, err := engine.Run(context.Background(), now, true)
+	if err != nil {
+		t.Fatalf("Run(dry) error = %v", err)
+	}
+	if dry.Deleted != 0 || dry.Rules[0].Matched != 5 || dry.Rules[1].Matched != 0 {
+		t.Errorf("Run(dry) = %+v, want 5 matched by indeed and nothing deleted", dry)
+	}
+	if stats, _ := store.GetStats(context.Background()); stats.TotalJobs != 7 {
+		t.Errorf("dry run left %d jobs, want 7", stats.TotalJobs)
+	}
+
+	run, err := engine.Run(context.Background(), now, false)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if run.Deleted != 5 {
+		t.Errorf("Run() deleted %d, want 5", run.Deleted)
+	}
+	// Batches of 2: 2, 2, 1
+	if got := run.Rules[0]; got.Deleted != 5 || got.Batches != 3 {
+		t.Errorf("Run() indeed rule = %+v, want 5 deleted in 3 batches", got)
+	}
+	if want := now.AddDate(0, 0, -90); !run.Rules[0].Cutoff.Equal(want) {
+		t.Errorf("Cutoff = %v, want %v", run.Rules[0].Cutoff, want)
+	}
+	if stats, _ := store.GetStats(context.Background()); stats.TotalJobs != 2 {
+		t.Errorf("Run() left %d jobs, want 2", stats.TotalJobs)
+	}
+}
+
+func TestEngineRunCancelled(t *testing.T) {
+	now := time.Now()
+	engine := NewEngine(newStore(t, now), []models.RetentionRule{{Name: "all", AfterDays: 30}}, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	run, err := engine.Run(ctx, now, false)
+	if err == nil {
+		t.Fatal("Run() with a cancelled context error = nil")
+	}
+	if run.Error == "" || run.Deleted != 0 {
+		t.Errorf("Run() = %+v, want an error recorded and nothing deleted", run)
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		wantErr bool
+	}{
+		{"valid", `[{"status": "closed", "after_days": 30}, {"name": "linkedin", "source": "linkedin", "after_days": 90}]`, false},
+		{"zero days", `[{"name": "bad", "after_days": 0}]`, true},
+		{"unknown status", `[{"status": "expired", "after_days": 30}]`, true},
+		{"unknown from", `[{"from": "created_at", "after_days": 30}]`, true},
+		{"duplicate", `[{"name": "a", "after_days": 30}, {"name": "a", "after_days": 60}]`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "rules.json")
+			if err := os.WriteFile(path, []byte(tt.json), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			rules, err := LoadRules(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadRules() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if rules[0].Name != "source=any status=closed closed_at+30d" || rules[0].AgeColumn() != models.RetentionFromClosed {
+				t.Errorf("rules[0] = %+v, want named after its conditions and aged from closed_at", rules[0])
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+// Package retention deletes jobs once they are older than configurable
+// per-source and per-status limits.
+package retention
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/abhisheksainimitawa/job-aggregator/internal/models"
+)
+
+// LoadRules reads retention rules from a JSON file holding an array of
+// rules, e.g. [{"name": "closed", "status": "closed", "after_days": 30}]
+func LoadRules(path string) ([]models.RetentionRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read retention rules: %w", err)
+	}
+
+	var rules []models.RetentionRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse retention rules: %w", err)
+	}
+
+	if err := ValidateRules(rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// ValidateRules validates each rule, naming unnamed ones, and rejects
+// duplicate names so audit records stay unambiguous
+func ValidateRules(rules []models.RetentionRule) error {
+	names := make(map[string]bool, len(rules))
+	for i := range rules {
+		if err := rules[i].Validate(); err != nil {
+			return err
+		}
+		if names[rules[i].Name] {
+			return fmt.Errorf("duplicate retention rule %q", rules[i].Name)
+		}
+		names[rules[i].Name] = true
+	}
+	return nil
+}
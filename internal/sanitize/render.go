@@ -0,0 +1,257 @@
+package sanitize
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	whitespaceRe   = regexp.MustCompile(`\s+`)
+	blankLinesRe   = regexp.MustCompile(`\n{3,}`)
+	markdownEscape = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`)
+
+	// linkEscape percent-encodes what would end a Markdown link destination
+	linkEscape = strings.NewReplacer(" ", "%20", "\t", "%09", "\n", "%0A", "\r", "%0D", "(", "%28", ")", "%29", "<", "%3C", ">", "%3E")
+)
+
+// writer renders a sanitized tree either as Markdown or as plain text
+type writer struct {
+	markdown bool
+	buf      strings.Builder
+	lists    []list
+	inPre    bool
+	trim     bool // drop leading spaces from the next text, e.g. at line start
+}
+
+// list tracks the state of an open <ul> or <ol>
+type list struct {
+	ordered bool
+	index   int
+}
+
+// renderMarkdown renders the sanitized tree as Markdown
+func renderMarkdown(root *html.Node) string {
+	w := &writer{markdown: true}
+	w.children(root)
+	return tidy(w.buf.String())
+}
+
+// renderText renders the sanitized tree as plain text
+func renderText(root *html.Node) string {
+	w := &writer{}
+	w.children(root)
+	return tidy(w.buf.String())
+}
+
+func (w *writer) children(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		w.node(c)
+	}
+}
+
+func (w *writer) node(n *html.Node) {
+	if n.Type == html.TextNode {
+		w.text(n.Data)
+		return
+	}
+	if n.Type != html.ElementNode {
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.P, atom.Div:
+		w.block()
+		w.children(n)
+		w.block()
+	case atom.Br:
+		w.write("\n")
+	case atom.Hr:
+		w.block()
+		if w.markdown {
+			w.write("---")
+		}
+		w.block()
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		w.block()
+		if w.markdown {
+			level, _ := strconv.Atoi(n.Data[1:])
+			w.write(strings.Repeat("#", level) + " ")
+		}
+		w.children(n)
+		w.block()
+	case atom.Strong, atom.B:
+		w.wrap(n, "**")
+	case atom.Em, atom.I:
+		w.wrap(n, "_")
+	case atom.Code:
+		if w.inPre {
+			w.children(n)
+		} else {
+			w.wrap(n, "`")
+		}
+	case atom.Pre:
+		w.block()
+		w.inPre = true
+		if w.markdown {
+			w.write("```\n")
+		}
+		w.children(n)
+		if w.markdown {
+			w.write("\n```")
+		}
+		w.inPre = false
+		w.block()
+	case atom.Blockquote:
+		w.block()
+		inner := &writer{markdown: w.markdown}
+		inner.children(n)
+		for i, line := range strings.Split(tidy(inner.buf.String()), "\n") {
+			if i > 0 {
+				w.write("\n")
+			}
+			if w.markdown {
+				w.write("> ")
+			}
+			w.write(line)
+		}
+		w.block()
+	case atom.A:
+		href := attr(n, "href")
+		if !w.markdown || href == "" {
+			w.children(n)
+			return
+		}
+		w.write("[")
+		w.children(n)
+		w.write("](" + linkEscape.Replace(href) + ")")
+	case atom.Ul, atom.Ol:
+		if len(w.lists) == 0 {
+			w.block()
+		}
+		w.lists = append(w.lists, list{ordered: n.DataAtom == atom.Ol})
+		w.children(n)
+		w.lists = w.lists[:len(w.lists)-1]
+		if len(w.lists) == 0 {
+			w.block()
+		}
+	case atom.Li:
+		w.listItem(n)
+	default:
+		w.children(n)
+	}
+}
+
+// listItem writes a bullet or numbered item at the current nesting depth
+func (w *writer) listItem(n *html.Node) {
+	marker := "-"
+	depth := len(w.lists)
+	if depth > 0 {
+		l := &w.lists[depth-1]
+		l.index++
+		if l.ordered {
+			marker = strconv.Itoa(l.index) + "."
+		}
+	} else {
+		depth = 1
+	}
+
+	w.newline()
+	w.write(strings.Repeat("  ", depth-1) + marker + " ")
+	w.trim = true
+	w.children(n)
+}
+
+// wrap surrounds the rendered children with a Markdown delimiter
+func (w *writer) wrap(n *html.Node, delim string) {
+	if !w.markdown || strings.TrimSpace(textContent(n)) == "" {
+		w.children(n)
+		return
+	}
+	w.write(delim)
+	w.children(n)
+	w.write(delim)
+}
+
+// text writes a text node, collapsing whitespace outside of <pre>
+func (w *writer) text(s string) {
+	if w.inPre {
+		w.write(s)
+		return
+	}
+
+	s = whitespaceRe.ReplaceAllString(s, " ")
+	if w.trim || w.buf.Len() == 0 {
+		s = strings.TrimLeft(s, " ")
+	}
+	if s == "" {
+		return
+	}
+	if w.markdown {
+		s = markdownEscape.Replace(s)
+	}
+	w.write(s)
+}
+
+func (w *writer) write(s string) {
+	w.buf.WriteString(s)
+	w.trim = strings.HasSuffix(s, "\n")
+}
+
+// block starts a new paragraph unless one was just started
+func (w *writer) block() {
+	if w.buf.Len() == 0 {
+		return
+	}
+	s := w.buf.String()
+	switch {
+	case strings.HasSuffix(s, "\n\n"):
+	case strings.HasSuffix(s, "\n"):
+		w.write("\n")
+	default:
+		w.write("\n\n")
+	}
+}
+
+// newline starts a new line unless already at one
+func (w *writer) newline() {
+	if w.buf.Len() > 0 && !strings.HasSuffix(w.buf.String(), "\n") {
+		w.write("\n")
+	}
+}
+
+// tidy trims trailing spaces on each line and collapses runs of blank lines
+func tidy(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	s = strings.Join(lines, "\n")
+	s = blankLinesRe.ReplaceAllString(s, "\n\n")
+	return strings.TrimSpace(s)
+}
+
+// textContent returns the concatenated text of a node and its descendants
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var sb strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		sb.WriteString(textContent(c))
+	}
+	return sb.String()
+}
+
+// attr returns the value of an attribute, or "" if absent
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
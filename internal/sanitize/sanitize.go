@@ -0,0 +1,185 @@
+package sanitize
+
+import (
+	"bytes"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Description formats
+const (
+	FormatHTML     = "html"
+	FormatMarkdown = "markdown"
+	FormatText     = "text"
+)
+
+// Description holds the renditions of a sanitized job description
+type Description struct {
+	HTML     string
+	Markdown string
+	Text     string
+}
+
+// IsFormat reports whether f is a supported description format
+func IsFormat(f string) bool {
+	return f == FormatHTML || f == FormatMarkdown || f == FormatText
+}
+
+// Select returns a job description in the requested format. Rows stored
+// before sanitization have no HTML or Markdown, so they fall back to the
+// plain text (escaped for HTML).
+func Select(format, text, htmlText, markdown string) string {
+	switch format {
+	case FormatHTML:
+		if htmlText != "" {
+			return htmlText
+		}
+		return html.EscapeString(text)
+	case FormatMarkdown:
+		if markdown != "" {
+			return markdown
+		}
+		return text
+	default:
+		return text
+	}
+}
+
+// allowedElements are kept as-is (minus attributes). Everything else is
+// unwrapped, so its text survives but the tag does not.
+var allowedElements = map[atom.Atom]bool{
+	atom.P: true, atom.Br: true, atom.Hr: true, atom.Div: true,
+	atom.Ul: true, atom.Ol: true, atom.Li: true,
+	atom.Strong: true, atom.B: true, atom.Em: true, atom.I: true, atom.U: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Blockquote: true, atom.Code: true, atom.Pre: true, atom.A: true,
+}
+
+// droppedElements are removed together with their content
+var droppedElements = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Iframe: true, atom.Object: true,
+	atom.Embed: true, atom.Noscript: true, atom.Template: true, atom.Svg: true,
+	atom.Form: true, atom.Head: true, atom.Title: true, atom.Img: true,
+	atom.Picture: true, atom.Video: true, atom.Audio: true, atom.Canvas: true,
+	atom.Link: true, atom.Meta: true, atom.Button: true, atom.Input: true,
+	atom.Select: true, atom.Textarea: true,
+}
+
+// allowedSchemes are the link schemes kept on <a href>
+var allowedSchemes = map[string]bool{"http": true, "https": true, "mailto": true}
+
+// Process sanitizes a raw description and renders it as clean HTML,
+// Markdown and plain text. Input without markup is treated as plain text.
+func Process(raw string) Description {
+	root := parse(raw)
+	clean(root)
+
+	return Description{
+		HTML:     renderHTML(root),
+		Markdown: renderMarkdown(root),
+		Text:     renderText(root),
+	}
+}
+
+// parse turns raw input into a sanitizable fragment tree. Plain text is
+// split into paragraphs on blank lines.
+func parse(raw string) *html.Node {
+	root := &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div}
+
+	if !strings.Contains(raw, "<") {
+		for _, para := range strings.Split(strings.ReplaceAll(raw, "\r\n", "\n"), "\n\n") {
+			if para = strings.TrimSpace(para); para == "" {
+				continue
+			}
+			p := &html.Node{Type: html.ElementNode, Data: "p", DataAtom: atom.P}
+			lines := strings.Split(para, "\n")
+			for i, line := range lines {
+				if i > 0 {
+					p.AppendChild(&html.Node{Type: html.ElementNode, Data: "br", DataAtom: atom.Br})
+				}
+				p.AppendChild(&html.Node{Type: html.TextNode, Data: strings.TrimSpace(line)})
+			}
+			root.AppendChild(p)
+		}
+		return root
+	}
+
+	nodes, err := html.ParseFragment(strings.NewReader(raw), root)
+	if err != nil {
+		root.AppendChild(&html.Node{Type: html.TextNode, Data: raw})
+		return root
+	}
+	for _, n := range nodes {
+		root.AppendChild(n)
+	}
+	return root
+}
+
+// clean removes disallowed nodes and attributes from the children of n
+func clean(n *html.Node) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+
+		switch c.Type {
+		case html.TextNode:
+			// kept
+		case html.ElementNode:
+			switch {
+			case droppedElements[c.DataAtom]:
+				n.RemoveChild(c)
+			case allowedElements[c.DataAtom]:
+				c.Attr = cleanAttrs(c)
+				clean(c)
+			default:
+				// Unwrap: clean the children, then hoist them into n
+				clean(c)
+				for gc := c.FirstChild; gc != nil; {
+					gcNext := gc.NextSibling
+					c.RemoveChild(gc)
+					n.InsertBefore(gc, c)
+					gc = gcNext
+				}
+				n.RemoveChild(c)
+			}
+		default:
+			// Comments, doctypes and the like
+			n.RemoveChild(c)
+		}
+
+		c = next
+	}
+}
+
+// cleanAttrs keeps only a safe href on links
+func cleanAttrs(n *html.Node) []html.Attribute {
+	if n.DataAtom != atom.A {
+		return nil
+	}
+
+	for _, attr := range n.Attr {
+		if attr.Key != "href" {
+			continue
+		}
+		u, err := url.Parse(strings.TrimSpace(attr.Val))
+		if err != nil || !allowedSchemes[strings.ToLower(u.Scheme)] {
+			return nil
+		}
+		return []html.Attribute{
+			{Key: "href", Val: u.String()},
+			{Key: "rel", Val: "nofollow noopener"},
+		}
+	}
+	return nil
+}
+
+// renderHTML serializes the children of the sanitized root
+func renderHTML(root *html.Node) string {
+	var buf bytes.Buffer
+	for c := root.FirstChild; c != nil; c = c.NextSibling {
+		html.Render(&buf, c)
+	}
+	return strings.TrimSpace(buf.String())
+}
@@ -0,0 +1,74 @@
+package sanitize
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestProcess_StripsUnsafeMarkup(t *testing.T) {
+	raw := `<div class="jd" style="color:red">
+		<script>track()</script>
+		<p onclick="x()">We build <b>distributed</b> systems in <a href="https://go.dev" target="_blank">Go</a>.</p>
+		<img src="https://t.example.com/pixel.gif" width="1" height="1">
+		<a href="javascript:alert(1)">Apply</a>
+		<!-- tracking -->
+	</div>`
+
+	got := Process(raw)
+
+	for _, bad := range []string{"script", "track()", "style", "onclick", "pixel", "javascript", "tracking", "class"} {
+		if strings.Contains(got.HTML, bad) {
+			t.Errorf("HTML contains %q: %s", bad, got.HTML)
+		}
+	}
+
+	wantHTML := `<p>We build <b>distributed</b> systems in <a href="https://go.dev" rel="nofollow noopener">Go</a>.</p>`
+	if !strings.Contains(got.HTML, wantHTML) {
+		t.Errorf("HTML = %s, want it to contain %s", got.HTML, wantHTML)
+	}
+
+	wantMarkdown := "We build **distributed** systems in [Go](https://go.dev).\n\nApply"
+	if got.Markdown != wantMarkdown {
+		t.Errorf("Markdown = %q, want %q", got.Markdown, wantMarkdown)
+	}
+
+	wantText := "We build distributed systems in Go.\n\nApply"
+	if got.Text != wantText {
+		t.Errorf("Text = %q, want %q", got.Text, wantText)
+	}
+}
+
+func TestProcess_Lists(t *testing.T) {
+	raw := `<h2>Requirements</h2><ul><li>3+ years of Go</li><li>Kubernetes<ul><li>Helm</li></ul></li></ul><ol><li>Apply</li><li>Interview</li></ol>`
+
+	got := Process(raw)
+
+	wantMarkdown := "## Requirements\n\n- 3+ years of Go\n- Kubernetes\n  - Helm\n\n1. Apply\n2. Interview"
+	if got.Markdown != wantMarkdown {
+		t.Errorf("Markdown = %q, want %q", got.Markdown, wantMarkdown)
+	}
+
+	wantText := "Requirements\n\n- 3+ years of Go\n- Kubernetes\n  - Helm\n\n1. Apply\n2. Interview"
+	if got.Text != wantText {
+		t.Errorf("Text = %q, want %q", got.Text, wantText)
+	}
+}
+
+func TestProcess_MarkdownLinkDestination(t *testing.T) {
+	got := Process(`<p><a href="https://example.com/jobs/go (remote)?a=1&amp;b=x)y">Apply</a></p>`)
+
+	want := "[Apply](https://example.com/jobs/go%20%28remote%29?a=1&b=x%29y)"
+	if got.Markdown != want {
+		t.Errorf("Markdown = %q, want %q", got.Markdown, want)
+	}
+}
+
+func TestProcess_PlainText(t *testing.T) {
+	got := Process("Looking for an engineer.\n\nApply & join us")
+	if got.HTML != "<p>Looking for an engineer.</p><p>Apply &amp; join us</p>" {
+		t.Errorf("HTML = %q", got.HTML)
+	}
+	if got.Text != "Looking for an engineer.\n\nApply & join us" {
+		t.Errorf("Text = %q", got.Text)
+	}
+}
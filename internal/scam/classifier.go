@@ -0,0 +1,183 @@
+package scam
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/abhisheksainimitawa/job-aggregator/internal/models"
+)
+
+// Example is a labeled job text, one line of a JSON Lines training file:
+// {"label": "scam", "text": "..."}. Labels are models.FlagReviewScam and
+// models.FlagReviewLegit.
+type Example struct {
+	Label string `json:"label"`
+	Text  string `json:"text"`
+	JobID int64  `json:"job_id,omitempty"` // Set for labels from flag reviews
+}
+
+// Text returns the text of a job that rules and the classifier look at
+func Text(job *models.Job) string {
+	return job.Title + "\n" + job.Salary + "\n" + job.Description
+}
+
+// Classifier is a multinomial naive Bayes classifier telling scams from
+// legitimate listings by the words of their text
+type Classifier struct {
+	docs   map[string]int            // Examples per label
+	counts map[string]map[string]int // Word counts per label
+	words  map[string]int            // Total words per label
+	vocab  int
+}
+
+// labels are the classes of the classifier
+var labels = []string{models.FlagReviewScam, models.FlagReviewLegit}
+
+// Train trains a classifier, which needs examples of both labels
+func Train(examples []Example) (*Classifier, error) {
+	c := &Classifier{
+		docs:   make(map[string]int, len(labels)),
+		counts: make(map[string]map[string]int, len(labels)),
+		words:  make(map[string]int, len(labels)),
+	}
+	for _, label := range labels {
+		c.counts[label] = make(map[string]int)
+	}
+
+	vocab := make(map[string]bool)
+	for i, ex := range examples {
+		counts, ok := c.counts[ex.Label]
+		if !ok {
+			return nil, fmt.Errorf("example %d has unknown label %q, expected scam or legit", i+1, ex.Label)
+		}
+		c.docs[ex.Label]++
+		for _, word := range tokenize(ex.Text) {
+			counts[word]++
+			c.words[ex.Label]++
+			vocab[word] = true
+		}
+	}
+	c.vocab = len(vocab)
+
+	for _, label := range labels {
+		if c.docs[label] == 0 {
+			return nil, fmt.Errorf("no %s examples to train on", label)
+		}
+	}
+	return c, nil
+}
+
+// Probability returns the probability that a text is a scam
+func (c *Classifier) Probability(text string) float64 {
+	total := float64(c.docs[models.FlagReviewScam] + c.docs[models.FlagReviewLegit])
+	logs := make(map[string]float64, len(labels))
+	for _, label := range labels {
+		logs[label] = math.Log(float64(c.docs[label]) / total)
+	}
+
+	for _, word := range tokenize(text) {
+		if c.counts[models.FlagReviewScam][word] == 0 && c.counts[models.FlagReviewLegit][word] == 0 {
+			continue // Unseen words carry no evidence
+		}
+		for _, label := range labels {
+			// Laplace smoothing
+			p := float64(c.counts[label][word]+1) / float64(c.words[label]+c.vocab)
+			logs[label] += math.Log(p)
+		}
+	}
+
+	return 1 / (1 + math.Exp(logs[models.FlagReviewLegit]-logs[models.FlagReviewScam]))
+}
+
+// tokenize splits text into lowercase words of two or more characters
+func tokenize(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '$'
+	})
+
+	words := fields[:0]
+	for _, f := range fields {
+		if len(f) >= 2 {
+			words = append(words, f)
+		}
+	}
+	return words
+}
+
+// LoadExamples reads a JSON Lines training file
+func LoadExamples(path string) ([]Example, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open scam labels: %w", err)
+	}
+	defer file.Close()
+
+	var examples []Example
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var ex Example
+		if err := json.Unmarshal([]byte(text), &ex); err != nil {
+			return nil, fmt.Errorf("failed to parse scam labels line %d: %w", line, err)
+		}
+		examples = append(examples, ex)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read scam labels: %w", err)
+	}
+	return examples, nil
+}
+
+// TrainFile trains a classifier on a JSON Lines training file
+func TrainFile(path string) (*Classifier, error) {
+	examples, err := LoadExamples(path)
+	if err != nil {
+		return nil, err
+	}
+	return Train(examples)
+}
+
+// LabelFile appends labeled examples to a JSON Lines training file
+type LabelFile struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewLabelFile creates a label file writing to path
+func NewLabelFile(path string) *LabelFile {
+	return &LabelFile{path: path}
+}
+
+// Append adds an example to the end of the file, creating it if needed
+func (f *LabelFile) Append(ex Example) error {
+	line, err := json.Marshal(ex)
+	if err != nil {
+		return fmt.Errorf("failed to encode scam label: %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open scam labels: %w", err)
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write scam label: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write scam label: %w", err)
+	}
+	return nil
+}
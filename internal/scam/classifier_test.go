@@ -0,0 +1,79 @@
+package scam
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/abhisheksainimitawa/job-aggregator/internal/models"
+)
+
+var trainingExamples = []Example{
+	{Label: models.FlagReviewScam, Text: "Easy money from home, weekly payout, no skills, start today"},
+	{Label: models.FlagReviewScam, Text: "Mystery shopper, we send you a check, keep your payout, start today"},
+	{Label: models.FlagReviewScam, Text: "Reshipping assistant, easy money, daily payout from home"},
+	{Label: models.FlagReviewLegit, Text: "Backend engineer building Go services on Kubernetes with a great team"},
+	{Label: models.FlagReviewLegit, Text: "Data engineer maintaining Python pipelines and Postgres warehouses"},
+	{Label: models.FlagReviewLegit, Text: "Frontend engineer working on React components with designers"},
+}
+
+func TestClassifier(t *testing.T) {
+	c, err := Train(trainingExamples)
+	if err != nil {
+		t.Fatalf("Train() error = %v", err)
+	}
+
+	if p := c.Probability("Easy money with a weekly payout, start today"); p < 0.9 {
+		t.Errorf("Probability(scam) = %.2f, want at least 0.9", p)
+	}
+	if p := c.Probability("Go engineer for our Kubernetes platform team"); p > 0.1 {
+		t.Errorf("Probability(legit) = %.2f, want at most 0.1", p)
+	}
+	if p := c.Probability("Lorem ipsum dolor"); p != 0.5 {
+		t.Errorf("Probability(unseen words) = %.2f, want the prior 0.5", p)
+	}
+
+	if _, err := Train(trainingExamples[:3]); err == nil {
+		t.Error("Train() without legit examples succeeded, want an error")
+	}
+	if _, err := Train([]Example{{Label: "spam", Text: "x"}}); err == nil {
+		t.Error("Train() with an unknown label succeeded, want an error")
+	}
+}
+
+func TestLabelFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "labels.jsonl")
+	labels := NewLabelFile(path)
+	for _, ex := range trainingExamples {
+		if err := labels.Append(ex); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+
+	examples, err := LoadExamples(path)
+	if err != nil {
+		t.Fatalf("LoadExamples() error = %v", err)
+	}
+	if len(examples) != len(trainingExamples) || examples[4] != trainingExamples[4] {
+		t.Errorf("LoadExamples() = %+v, want the appended examples", examples)
+	}
+
+	// A detector keeps reviews as examples for the next training
+	detector, err := NewDetector(DefaultRules, nil, 0, path)
+	if err != nil {
+		t.Fatalf("NewDetector() error = %v", err)
+	}
+	job := &models.Job{ID: 7, Title: "Reshipping Assistant", Description: "Easy money"}
+	if err := detector.Label(job, models.FlagReviewScam); err != nil {
+		t.Fatalf("Label() error = %v", err)
+	}
+	if examples, err = LoadExamples(path); err != nil {
+		t.Fatalf("LoadExamples() error = %v", err)
+	}
+	if last := examples[len(examples)-1]; last.JobID != 7 || last.Label != models.FlagReviewScam || last.Text != Text(job) {
+		t.Errorf("last example = %+v, want the reviewed job", last)
+	}
+
+	if _, err := TrainFile(path); err != nil {
+		t.Errorf("TrainFile() error = %v", err)
+	}
+}
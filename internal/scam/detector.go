@@ -0,0 +1,157 @@
+package scam
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/abhisheksainimitawa/job-aggregator/internal/companies"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/models"
+)
+
+// DefaultThreshold is the scam probability from which the classifier
+// flags a job
+const DefaultThreshold = 0.9
+
+// emailPattern finds email addresses and captures their domain
+var emailPattern = regexp.MustCompile(`(?i)\b[a-z0-9._%+-]+@([a-z0-9-]+(?:\.[a-z0-9-]+)+)\b`)
+
+// freeMailDomains are mail providers no company hires from
+var freeMailDomains = map[string]bool{
+	"gmail.com": true, "googlemail.com": true, "yahoo.com": true, "ymail.com": true,
+	"hotmail.com": true, "outlook.com": true, "live.com": true, "msn.com": true,
+	"aol.com": true, "icloud.com": true, "me.com": true, "mail.com": true,
+	"gmx.com": true, "gmx.de": true, "web.de": true, "yandex.com": true, "yandex.ru": true,
+	"mail.ru": true, "protonmail.com": true, "proton.me": true, "zoho.com": true,
+}
+
+// Detector flags suspicious jobs with rules and, when it has one, a
+// classifier. Admin reviews of its flags become training examples.
+type Detector struct {
+	rules      []compiledRule
+	domain     float64 // Weight of DomainRule, 0 when off
+	classifier *Classifier
+	threshold  float64
+	labels     *LabelFile
+}
+
+// NewDetector creates a detector. classifier may be nil to use the rules
+// only, and without a labels path reviews are not kept for training.
+func NewDetector(rules []Rule, classifier *Classifier, threshold float64, labelsPath string) (*Detector, error) {
+	compiled, err := compileRules(rules)
+	if err != nil {
+		return nil, err
+	}
+	if threshold <= 0 || threshold > 1 {
+		threshold = DefaultThreshold
+	}
+
+	d := &Detector{rules: compiled, classifier: classifier, threshold: threshold}
+	for _, rule := range rules {
+		if rule.Name == DomainRule {
+			d.domain = rule.Weight
+		}
+	}
+	if labelsPath != "" {
+		d.labels = NewLabelFile(labelsPath)
+	}
+	return d, nil
+}
+
+// Check returns whether a job should be flagged and why
+func (d *Detector) Check(job *models.Job) (bool, []string) {
+	text := Text(job)
+
+	var score float64
+	var reasons []string
+	for _, rule := range d.rules {
+		if match := rule.pattern.FindString(text); match != "" {
+			score += rule.weight
+			reasons = append(reasons, fmt.Sprintf("%s: %q", rule.name, strings.TrimSpace(match)))
+		}
+	}
+	if d.domain > 0 {
+		if reason := foreignEmail(job); reason != "" {
+			score += d.domain
+			reasons = append(reasons, DomainRule+": "+reason)
+		}
+	}
+	flagged := score >= FlagScore
+
+	if d.classifier != nil {
+		if p := d.classifier.Probability(text); p >= d.threshold {
+			flagged = true
+			reasons = append(reasons, fmt.Sprintf("classifier: %.0f%% likely a scam", 100*p))
+		}
+	}
+
+	if !flagged {
+		return false, nil
+	}
+	return true, reasons
+}
+
+// Apply flags the suspicious jobs of a batch and returns how many it
+// flagged
+func (d *Detector) Apply(jobs []*models.Job) int {
+	flagged := 0
+	for _, job := range jobs {
+		job.Flagged, job.FlagReasons = d.Check(job)
+		if job.Flagged {
+			flagged++
+		}
+	}
+	return flagged
+}
+
+// Label keeps a reviewed job as a training example, if the detector has
+// a labels file
+func (d *Detector) Label(job *models.Job, label string) error {
+	if d.labels == nil {
+		return nil
+	}
+	return d.labels.Append(Example{Label: label, Text: Text(job), JobID: job.ID})
+}
+
+// foreignEmail describes the first contact email of a job's description
+// that belongs to a free mail provider or to a domain unrelated to the
+// company and the job's site, "" if there is none
+func foreignEmail(job *models.Job) string {
+	host := ""
+	if u, err := url.Parse(job.URL); err == nil {
+		host = strings.ToLower(u.Hostname())
+	}
+	company := strings.Fields(companies.Normalize(job.Company))
+
+	for _, m := range emailPattern.FindAllStringSubmatch(job.Description, -1) {
+		address, domain := m[0], strings.ToLower(m[1])
+		switch {
+		case freeMailDomains[domain]:
+			return fmt.Sprintf("contact address %s is a free mail account", address)
+		case host != "" && (host == domain || strings.HasSuffix(host, "."+domain)):
+			continue
+		case !domainMatches(domain, company):
+			return fmt.Sprintf("contact address %s does not belong to %s", address, job.Company)
+		}
+	}
+	return ""
+}
+
+// domainMatches reports whether a domain looks like the company's: it
+// contains a word of the company name, or the name run together
+func domainMatches(domain string, company []string) bool {
+	if len(company) == 0 {
+		return true // Nothing to compare with
+	}
+	name := strings.ReplaceAll(domain, "-", "")
+	if strings.Contains(name, strings.Join(company, "")) {
+		return true
+	}
+	for _, word := range company {
+		if len(word) >= 3 && strings.Contains(name, word) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+package scam
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/abhisheksainimitawa/job-aggregator/internal/models"
+)
+
+func TestDetectorCheck(t *testing.T) {
+	detector, err := NewDetector(DefaultRules, nil, 0, "")
+	if err != nil {
+		t.Fatalf("NewDetector() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		job     models.Job
+		flagged bool
+		reasons []string // Rule names, in order
+	}{
+		{
+			name: "legitimate",
+			job: models.Job{Title: "Go Developer", Company: "Acme GmbH", URL: "https://www.indeed.com/viewjob?jk=1",
+				Description: "Build APIs in Go. Questions? Write to jobs@acme.de or apply on our website."},
+		},
+		{
+			name: "payment request",
+			job: models.Job{Title: "Data Entry Clerk", Company: "Acme",
+				Description: "A one-time registration fee of $50 covers your starter materials."},
+			flagged: true,
+			reasons: []string{"payment_request"},
+		},
+		{
+			name: "earnings promise and messenger contact",
+			job: models.Job{Title: "Work From Home", Company: "Acme",
+				Description: "Earn $5000/week from home. Contact us on WhatsApp to start."},
+			flagged: true,
+			reasons: []string{"earnings_promise", "messenger_contact"},
+		},
+		{
+			name: "earnings promise alone",
+			job: models.Job{Title: "Sales Representative", Company: "Acme",
+				Description: "Top performers earn $500 per day in commission."},
+		},
+		{
+			name: "free mail and messenger contact",
+			job: models.Job{Title: "Remote Assistant", Company: "Acme", URL: "https://example.com/jobs/1",
+				Description: "Send your CV to acme.hiring@gmail.com and text us on Telegram."},
+			flagged: true,
+			reasons: []string{"messenger_contact", DomainRule},
+		},
+		{
+			name: "mismatched domain and personal data",
+			job: models.Job{Title: "Payroll Clerk", Company: "Acme", URL: "https://example.com/jobs/1",
+				Description: "Email hr@global-staffing.net with your bank account details."},
+			flagged: true,
+			reasons: []string{"personal_data", DomainRule},
+		},
+		{
+			name: "email on the job's site",
+			job: models.Job{Title: "Payroll Clerk", Company: "Acme", URL: "https://careers.example.com/jobs/1",
+				Description: "Questions? Email recruiting@example.com."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flagged, reasons := detector.Check(&tt.job)
+			if flagged != tt.flagged {
+				t.Errorf("flagged = %v, want %v (reasons %q)", flagged, tt.flagged, reasons)
+			}
+			if len(reasons) != len(tt.reasons) {
+				t.Fatalf("reasons = %q, want rules %q", reasons, tt.reasons)
+			}
+			for i, rule := range tt.reasons {
+				if !strings.HasPrefix(reasons[i], rule+": ") {
+					t.Errorf("reason %d = %q, want rule %s", i, reasons[i], rule)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	data := `[
+		{"name": "crypto", "pattern": "\\bbitcoin\\b", "weight": 1},
+		{"name": "payment_request", "weight": 0},
+		{"name": "company_domain", "weight": 1}
+	]`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	rules, err := LoadRules(path)
+	if err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+	if len(rules) != len(DefaultRules)+1 {
+		t.Errorf("got %d rules, want %d", len(rules), len(DefaultRules)+1)
+	}
+
+	detector, err := NewDetector(rules, nil, 0, "")
+	if err != nil {
+		t.Fatalf("NewDetector() error = %v", err)
+	}
+	checks := []struct {
+		description string
+		flagged     bool
+	}{
+		{"Pay in Bitcoin only.", true},
+		{"A registration fee applies.", false},
+		{"Apply at jobs@gmail.com", true},
+	}
+	for _, c := range checks {
+		job := &models.Job{Title: "Clerk", Company: "Acme", Description: c.description}
+		if flagged, reasons := detector.Check(job); flagged != c.flagged {
+			t.Errorf("Check(%q) = %v %q, want %v", c.description, flagged, reasons, c.flagged)
+		}
+	}
+
+	for _, bad := range []string{
+		`[{"name": "broken", "pattern": "(", "weight": 1}]`,
+		`[{"name": "empty", "weight": 1}]`,
+		`[{"pattern": "x", "weight": 1}]`,
+		`[{"name": "negative", "pattern": "x", "weight": -1}]`,
+	} {
+		if err := os.WriteFile(path, []byte(bad), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadRules(path); err == nil {
+			t.Errorf("LoadRules(%s) succeeded, want an error", bad)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+// Package scam flags scam and spam job listings: earnings promises,
+// requests for payment, messenger-only contact and contact addresses
+// that do not belong to the hiring company. Rules score the text of a job
+// and an optional naive Bayes classifier, trained offline on labeled
+// examples, adds a second opinion.
+package scam
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+)
+
+// Rule adds Weight to a job's score when its title, description or
+// salary matches Pattern, a case-insensitive regular expression. A job is
+// flagged once its score reaches FlagScore. The DomainRule has no pattern.
+type Rule struct {
+	Name    string  `json:"name"`
+	Pattern string  `json:"pattern,omitempty"`
+	Weight  float64 `json:"weight"`
+}
+
+// FlagScore is the rule score at which a job is flagged
+const FlagScore = 1.0
+
+// DomainRule checks that contact email addresses belong to the company
+// rather than to a free mail provider or an unrelated domain
+const DomainRule = "company_domain"
+
+// DefaultRules are the built-in rules
+var DefaultRules = []Rule{
+	{
+		Name:    "earnings_promise",
+		Pattern: `\b(earn|make)\s+(up\s+to\s+)?[$€£]?\s?\d[\d,.]*k?\+?\s*(/|per|a|an|every)\s*(hour|day|week)\b`,
+		Weight:  0.6,
+	},
+	{
+		Name: "payment_request",
+		Pattern: `\b(registration|training|starter|application|processing|onboarding)\s+(fee|kit|deposit)\b` +
+			`|\b(pay|send|wire)\s+(a\s+|the\s+|us\s+)?(fee|deposit|money)\b` +
+			`|\b(western\s+union|moneygram|gift\s?cards?)\b`,
+		Weight: 1,
+	},
+	{
+		Name:    "messenger_contact",
+		Pattern: `\b(contact|message|text|reach|apply|dm|add)\b[^.\n]{0,40}\b(whatsapp|telegram|signal|wechat|viber)\b`,
+		Weight:  0.6,
+	},
+	{
+		Name:    "personal_data",
+		Pattern: `\b(ssn|social\s+security\s+number|bank\s+account\s+(details|number)|passport\s+(copy|scan))\b`,
+		Weight:  0.6,
+	},
+	{
+		Name:    "pressure",
+		Pattern: `\b(no\s+experience\s+(needed|required)|no\s+interview|limited\s+spots|act\s+now|guaranteed\s+income)\b`,
+		Weight:  0.3,
+	},
+	{Name: DomainRule, Weight: 0.5},
+}
+
+// LoadRules returns the default rules extended with the rules of the JSON
+// file at path, e.g. [{"name": "crypto", "pattern": "\\bbitcoin\\b",
+// "weight": 0.5}]. A rule named like a default replaces it, keeping the
+// default pattern if it has none, so {"name": "pressure", "weight": 0}
+// turns that rule off. An empty path yields the defaults.
+func LoadRules(path string) ([]Rule, error) {
+	rules := append([]Rule(nil), DefaultRules...)
+	if path == "" {
+		return rules, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scam rules: %w", err)
+	}
+
+	var custom []Rule
+	if err := json.Unmarshal(data, &custom); err != nil {
+		return nil, fmt.Errorf("failed to parse scam rules: %w", err)
+	}
+
+	for _, rule := range custom {
+		replaced := false
+		for i := range rules {
+			if rules[i].Name == rule.Name {
+				if rule.Pattern == "" {
+					rule.Pattern = rules[i].Pattern
+				}
+				rules[i], replaced = rule, true
+				break
+			}
+		}
+		if !replaced {
+			rules = append(rules, rule)
+		}
+	}
+
+	if _, err := compileRules(rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// compiledRule is a pattern rule ready to match
+type compiledRule struct {
+	name    string
+	pattern *regexp.Regexp
+	weight  float64
+}
+
+// compileRules validates and compiles the pattern rules, skipping rules
+// turned off with a weight of 0
+func compileRules(rules []Rule) ([]compiledRule, error) {
+	names := make(map[string]bool, len(rules))
+	compiled := make([]compiledRule, 0, len(rules))
+	for _, rule := range rules {
+		switch {
+		case rule.Name == "":
+			return nil, fmt.Errorf("scam rule without a name")
+		case names[rule.Name]:
+			return nil, fmt.Errorf("duplicate scam rule %q", rule.Name)
+		case rule.Weight < 0:
+			return nil, fmt.Errorf("scam rule %q has a negative weight", rule.Name)
+		case rule.Name != DomainRule && rule.Pattern == "":
+			return nil, fmt.Errorf("scam rule %q has no pattern", rule.Name)
+		}
+		names[rule.Name] = true
+
+		if rule.Name == DomainRule || rule.Weight == 0 {
+			continue
+		}
+		pattern, err := regexp.Compile("(?i)" + rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern of scam rule %q: %w", rule.Name, err)
+		}
+		compiled = append(compiled, compiledRule{name: rule.Name, pattern: pattern, weight: rule.Weight})
+	}
+	return compiled, nil
+}
@@ -8,8 +8,10 @@ import (
 	"time"
 
 	"github.com/abhisheksainimitawa/job-aggregator/internal/models"
+	"github.com/abhisheksainimitawa/job-aggregator/pkg/dateparse"
 	"github.com/abhisheksainimitawa/job-aggregator/pkg/logger"
 	"github.com/abhisheksainimitawa/job-aggregator/pkg/ratelimit"
+	"github.com/abhisheksainimitawa/job-aggregator/pkg/urlcanon"
 )
 
 // JobSource defines the interface for job board scrapers
@@ -32,10 +34,12 @@ type Engine struct {
 
 // Stats holds scraping statistics
 type Stats struct {
-	JobsScraped int
-	Errors      int
-	StartTime   time.Time
-	EndTime     time.Time
+	JobsScraped      int
+	Errors           int
+	StartTime        time.Time
+	EndTime          time.Time
+	CompletedSources []string           // Sources that finished the last run without error
+	SourceRuns       []models.SourceRun // What each source returned in the last run
 }
 
 // NewEngine creates a new scraper engine
@@ -58,7 +62,11 @@ func (e *Engine) RegisterSource(source JobSource) {
 
 // Start starts the scraping engine with concurrent workers
 func (e *Engine) Start(ctx context.Context, query string) ([]*models.Job, error) {
+	e.mu.Lock()
 	e.stats.StartTime = time.Now()
+	e.stats.CompletedSources = nil
+	e.stats.SourceRuns = nil
+	e.mu.Unlock()
 	logger.Info("Starting scraper engine with %d workers for query: %s", e.workers, query)
 
 	// Create worker pool
@@ -140,18 +148,28 @@ func (e *Engine) worker(ctx context.Context, id int, sources <-chan JobSource, j
 			}
 
 			// Scrape the source
+			started := time.Now()
 			sourceJobs, err := source.Scrape(ctx, query)
+			latency := time.Since(started)
 			if err != nil {
+				e.recordRun(models.MeasureSourceRun(source.Name(), started, latency, nil, err))
 				e.errCh <- fmt.Errorf("%s scraper failed: %w", source.Name(), err)
 				continue
 			}
 
 			// Send jobs to collector
 			for _, job := range sourceJobs {
-				// Add hash for deduplication
-				job.Hash = generateJobHash(job)
+				SetListingKeys(job)
 				job.ScrapedAt = time.Now()
 
+				// Sources that found no posted date fall back to the scrape time
+				if job.PostedAt.IsZero() {
+					job.PostedAt = job.ScrapedAt
+					job.PostedAtPrecision = string(dateparse.PrecisionUnknown)
+				} else if job.PostedAtPrecision == "" {
+					job.PostedAtPrecision = string(dateparse.PrecisionExact)
+				}
+
 				select {
 				case jobs <- job:
 				case <-ctx.Done():
@@ -159,14 +177,37 @@ func (e *Engine) worker(ctx context.Context, id int, sources <-chan JobSource, j
 				}
 			}
 
+			e.recordRun(models.MeasureSourceRun(source.Name(), started, latency, sourceJobs, nil))
+			e.markCompleted(source.Name())
 			logger.Info("Worker %d: Scraped %d jobs from %s", id, len(sourceJobs), source.Name())
 		}
 	}
 }
 
-// generateJobHash creates a unique hash for job deduplication
+// SetListingKeys sets the keys a listing is stored under: its canonical
+// URL without tracking parameters, the board's own job ID and the hash
+// for deduplication. Jobs stored before URLs were canonicalized are
+// re-keyed with it too.
+func SetListingKeys(job *models.Job) {
+	job.CanonicalURL = job.URL
+	if canon, err := urlcanon.Canonicalize(job.URL); err == nil {
+		job.CanonicalURL = canon.URL
+		job.NativeID = canon.NativeID
+	}
+	job.Hash = generateJobHash(job)
+}
+
+// generateJobHash creates a unique hash identifying a single listing.
+// Listings are identified by source and canonical URL so unrelated postings that
+// share a title stay apart; near-duplicates are grouped later by
+// internal/dedup. Listings without a URL fall back to their content.
 func generateJobHash(job *models.Job) string {
 	data := fmt.Sprintf("%s|%s|%s", job.Title, job.Company, job.Location)
+	if url := job.CanonicalURL; url != "" {
+		data = fmt.Sprintf("%s|%s", job.Source, url)
+	} else if job.URL != "" {
+		data = fmt.Sprintf("%s|%s", job.Source, job.URL)
+	}
 	hash := sha256.Sum256([]byte(data))
 	return fmt.Sprintf("%x", hash)
 }
@@ -178,6 +219,20 @@ func (e *Engine) incrementJobCount() {
 	e.stats.JobsScraped++
 }
 
+// markCompleted records a source that finished without error
+func (e *Engine) markCompleted(source string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.stats.CompletedSources = append(e.stats.CompletedSources, source)
+}
+
+// recordRun records what a source returned in the current run
+func (e *Engine) recordRun(run models.SourceRun) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.stats.SourceRuns = append(e.stats.SourceRuns, run)
+}
+
 // incrementErrorCount increments the error counter
 func (e *Engine) incrementErrorCount() {
 	e.mu.Lock()
@@ -189,7 +244,10 @@ func (e *Engine) incrementErrorCount() {
 func (e *Engine) GetStats() Stats {
 	e.mu.Lock()
 	defer e.mu.Unlock()
-	return e.stats
+	stats := e.stats
+	stats.CompletedSources = append([]string(nil), e.stats.CompletedSources...)
+	stats.SourceRuns = append([]models.SourceRun(nil), e.stats.SourceRuns...)
+	return stats
 }
 
 // Shutdown gracefully shuts down the engine
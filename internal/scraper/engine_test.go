@@ -2,6 +2,7 @@ package scraper
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -38,6 +39,51 @@ func TestEngine_Start(t *testing.T) {
 	t.Logf("Scraped %d jobs with %d errors", stats.JobsScraped, stats.Errors)
 }
 
+// fakeSource returns fixed jobs or an error
+type fakeSource struct {
+	name string
+	jobs []*models.Job
+	err  error
+}
+
+func (s *fakeSource) Name() string { return s.name }
+
+func (s *fakeSource) Scrape(ctx context.Context, query string) ([]*models.Job, error) {
+	return s.jobs, s.err
+}
+
+func TestEngine_SourceRuns(t *testing.T) {
+	engine := NewEngine(2, 100)
+	engine.RegisterSource(&fakeSource{name: "broken", err: errors.New("unexpected markup")})
+	engine.RegisterSource(&fakeSource{name: "good", jobs: []*models.Job{
+		{Title: "Go Developer", URL: "https://example.com/1", Company: "Acme", Location: "Remote", Salary: "$100k"},
+		{Title: "", URL: "https://example.com/2", Company: "Acme"},
+	}})
+
+	if _, err := engine.Start(context.Background(), "go"); err != nil {
+		t.Fatalf("Engine.Start() error = %v", err)
+	}
+
+	runs := make(map[string]models.SourceRun)
+	for _, run := range engine.GetStats().SourceRuns {
+		runs[run.Source] = run
+	}
+	if len(runs) != 2 {
+		t.Fatalf("SourceRuns = %+v, want a run per source", runs)
+	}
+	if runs["broken"].Error != "unexpected markup" || runs["broken"].Jobs != 0 {
+		t.Errorf("broken run = %+v, want the scrape error", runs["broken"])
+	}
+
+	good := runs["good"]
+	if good.Jobs != 2 || good.ParseFailures != 1 || good.Error != "" {
+		t.Errorf("good run = %+v, want 2 jobs with 1 parse failure", good)
+	}
+	if good.FillRates["location"] != 0.5 || good.FillRates["company"] != 1 || good.FillRates["posted_at"] != 0 {
+		t.Errorf("good fill rates = %v, want location 0.5, company 1, posted_at 0", good.FillRates)
+	}
+}
+
 func TestGenerateJobHash(t *testing.T) {
 	job1 := &models.Job{
 		Title:    "Go Developer",
@@ -68,6 +114,14 @@ func TestGenerateJobHash(t *testing.T) {
 	if hash1 == hash3 {
 		t.Error("Expected different hash for different jobs")
 	}
+
+	// Separate postings that share a title are different listings
+	job4 := &models.Job{Title: "Go Developer", Company: "Tech Corp", Location: "Remote", Source: "Indeed", URL: "https://indeed.com/job/1"}
+	job5 := &models.Job{Title: "Go Developer", Company: "Tech Corp", Location: "Remote", Source: "Indeed", URL: "https://indeed.com/job/2"}
+
+	if generateJobHash(job4) == generateJobHash(job5) {
+		t.Error("Expected different hash for listings with different URLs")
+	}
 }
 
 func TestIndeedScraper_Scrape(t *testing.T) {
@@ -7,8 +7,16 @@ import (
 	"time"
 
 	"github.com/abhisheksainimitawa/job-aggregator/internal/models"
+	"github.com/abhisheksainimitawa/job-aggregator/pkg/dateparse"
 )
 
+// setPostedAt resolves the posted-date text shown on a job board
+func setPostedAt(job *models.Job, text string, now time.Time) {
+	r := dateparse.ParseOrNow(text, now)
+	job.PostedAt = r.Time
+	job.PostedAtPrecision = string(r.Precision)
+}
+
 // IndeedScraper scrapes Indeed job board (mock implementation)
 type IndeedScraper struct {
 	baseURL string
@@ -72,9 +80,16 @@ func (s *IndeedScraper) Scrape(ctx context.Context, query string) ([]*models.Job
 			Source:      "Indeed",
 			RemoteOk:    rand.Float32() > 0.5,
 			JobType:     jobTypes[rand.Intn(len(jobTypes))],
-			PostedAt:    time.Now().Add(-time.Duration(rand.Intn(30)) * 24 * time.Hour),
 		}
 
+		// Indeed shows "Just posted", "Today", "3 days ago" or "30+ days ago"
+		postedTexts := []string{
+			"Just posted", "Today",
+			fmt.Sprintf("Posted %d days ago", 1+rand.Intn(29)),
+			"Posted 30+ days ago",
+		}
+		setPostedAt(job, postedTexts[rand.Intn(len(postedTexts))], time.Now())
+
 		if rand.Float32() > 0.3 {
 			job.Salary = fmt.Sprintf("$%dk - $%dk", 100+rand.Intn(100), 150+rand.Intn(100))
 		}
@@ -140,9 +155,16 @@ func (s *LinkedInScraper) Scrape(ctx context.Context, query string) ([]*models.J
 			Source:      "LinkedIn",
 			RemoteOk:    rand.Float32() > 0.4,
 			JobType:     "Full-time",
-			PostedAt:    time.Now().Add(-time.Duration(rand.Intn(14)) * 24 * time.Hour),
 		}
 
+		// LinkedIn shows "5 hours ago", "2 days ago" or "1 week ago"
+		postedTexts := []string{
+			fmt.Sprintf("%d hours ago", 1+rand.Intn(23)),
+			fmt.Sprintf("%d days ago", 1+rand.Intn(6)),
+			"1 week ago", "2 weeks ago",
+		}
+		setPostedAt(job, postedTexts[rand.Intn(len(postedTexts))], time.Now())
+
 		if rand.Float32() > 0.2 {
 			job.Salary = fmt.Sprintf("$%dk - $%dk", 120+rand.Intn(150), 200+rand.Intn(150))
 		}
@@ -200,10 +222,17 @@ func (s *GlassdoorScraper) Scrape(ctx context.Context, query string) ([]*models.
 			Source:      "Glassdoor",
 			RemoteOk:    true,
 			JobType:     "Full-time",
-			PostedAt:    time.Now().Add(-time.Duration(rand.Intn(20)) * 24 * time.Hour),
 			Salary:      fmt.Sprintf("$%dk - $%dk", 110+rand.Intn(120), 180+rand.Intn(120)),
 		}
 
+		// Glassdoor shows compact ages like "24h" and "3d", or a full date
+		postedTexts := []string{
+			"24h",
+			fmt.Sprintf("%dd", 1+rand.Intn(19)),
+			time.Now().AddDate(0, 0, -rand.Intn(20)).Format("Jan 2, 2006"),
+		}
+		setPostedAt(job, postedTexts[rand.Intn(len(postedTexts))], time.Now())
+
 		jobs = append(jobs, job)
 	}
 
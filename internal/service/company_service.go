@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/abhisheksainimitawa/job-aggregator/internal/models"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/repository"
+)
+
+// errNoCompanies is returned when companies are not stored
+var errNoCompanies = fmt.Errorf("%w: companies", repository.ErrNotSupported)
+
+// CompanyService handles business logic for companies
+type CompanyService struct {
+	repo *repository.CompanyRepository
+}
+
+// NewCompanyService creates a new company service. Companies are stored
+// in Postgres only; with other job stores repo is nil and every method
+// returns repository.ErrNotSupported.
+func NewCompanyService(repo *repository.CompanyRepository) *CompanyService {
+	return &CompanyService{
+		repo: repo,
+	}
+}
+
+// GetCompany retrieves a company by ID
+func (s *CompanyService) GetCompany(ctx context.Context, id int64) (*models.Company, error) {
+	if s.repo == nil {
+		return nil, errNoCompanies
+	}
+	return s.repo.FindByID(ctx, id)
+}
+
+// ListCompanies lists one page of canonical companies with their total
+func (s *CompanyService) ListCompanies(ctx context.Context, query *models.CompanySearchQuery) (*models.CompanyPage, error) {
+	if s.repo == nil {
+		return nil, errNoCompanies
+	}
+	return s.repo.List(ctx, query)
+}
@@ -3,24 +3,67 @@ package service
 import (
 	"context"
 	"fmt"
+	"sync"
+	"time"
 
+	"github.com/abhisheksainimitawa/job-aggregator/internal/companies"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/dedup"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/geo"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/health"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/lifecycle"
 	"github.com/abhisheksainimitawa/job-aggregator/internal/models"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/quality"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/querylang"
 	"github.com/abhisheksainimitawa/job-aggregator/internal/repository"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/sanitize"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/scam"
 	"github.com/abhisheksainimitawa/job-aggregator/internal/scraper"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/tagging"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/titles"
 	"github.com/abhisheksainimitawa/job-aggregator/pkg/logger"
+	"github.com/abhisheksainimitawa/job-aggregator/pkg/salary"
 )
 
 // JobService handles business logic for jobs
 type JobService struct {
-	repo    *repository.JobRepository
-	scraper *scraper.Engine
+	repo      repository.JobStore
+	scraper   *scraper.Engine
+	tagger    *tagging.Tagger
+	companies *companies.Resolver
+	clusterer *dedup.Clusterer
+	lifecycle *lifecycle.Tracker
+	health    *health.Monitor
+	detector  *scam.Detector
+
+	refreshMu sync.Mutex
+	refresh   *statsRefresh // Running refresh for fresh stats, nil if none
+}
+
+// freshStatsAge is how recent precomputed stats must be for a request for
+// fresh stats to be answered without refreshing them
+const freshStatsAge = time.Minute
+
+// statsRefresh is a refresh of precomputed stats that concurrent requests
+// for fresh stats wait on
+type statsRefresh struct {
+	done chan struct{}
+	err  error
 }
 
-// NewJobService creates a new job service
-func NewJobService(repo *repository.JobRepository, scraperEngine *scraper.Engine) *JobService {
+// NewJobService creates a new job service. The company resolver,
+// clusterer and lifecycle tracker need Postgres and may be nil with other
+// stores, which skips their steps. Without a health monitor source runs
+// are not tracked, and without a scam detector no jobs are flagged.
+func NewJobService(repo repository.JobStore, scraperEngine *scraper.Engine, tagger *tagging.Tagger, resolver *companies.Resolver, clusterer *dedup.Clusterer, tracker *lifecycle.Tracker, monitor *health.Monitor, detector *scam.Detector) *JobService {
 	return &JobService{
-		repo:    repo,
-		scraper: scraperEngine,
+		repo:      repo,
+		scraper:   scraperEngine,
+		tagger:    tagger,
+		companies: resolver,
+		clusterer: clusterer,
+		lifecycle: tracker,
+		health:    monitor,
+		detector:  detector,
 	}
 }
 
@@ -29,33 +72,330 @@ func (s *JobService) GetJob(ctx context.Context, id int64) (*models.Job, error)
 	return s.repo.FindByID(ctx, id)
 }
 
+// GetJobHistory retrieves the recorded changes of a job, newest first
+func (s *JobService) GetJobHistory(ctx context.Context, id int64) ([]*models.JobVersion, error) {
+	history, ok := s.repo.(repository.HistoryReader)
+	if !ok {
+		return nil, fmt.Errorf("%w: job history", repository.ErrNotSupported)
+	}
+	return history.History(ctx, id)
+}
+
 // SearchJobs searches for jobs based on criteria
 func (s *JobService) SearchJobs(ctx context.Context, query *models.JobSearchQuery) ([]*models.Job, error) {
+	s.normalizeSkills(query)
 	return s.repo.Search(ctx, query)
 }
 
-// GetStats retrieves job statistics
-func (s *JobService) GetStats(ctx context.Context) (*models.JobStats, error) {
+// SearchJobsPage searches for jobs and returns the page with its total
+// count and pagination cursors
+func (s *JobService) SearchJobsPage(ctx context.Context, query *models.JobSearchQuery) (*models.JobPage, error) {
+	s.normalizeSkills(query)
+	if pager, ok := s.repo.(repository.PageSearcher); ok {
+		return pager.SearchPage(ctx, query)
+	}
+
+	// Without counts the total is known only once the last page is
+	// reached; before that it is a lower bound
+	jobs, err := s.repo.Search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	total := int64(query.Page*query.Limit + len(jobs))
+	return &models.JobPage{Jobs: jobs, Total: total, TotalExact: len(jobs) < query.Limit}, nil
+}
+
+// SearchFacets counts search results per value of the given facets
+func (s *JobService) SearchFacets(ctx context.Context, query *models.JobSearchQuery, fields []string) (map[string][]models.FacetCount, error) {
+	s.normalizeSkills(query)
+	facets, ok := s.repo.(repository.FacetCounter)
+	if !ok {
+		return nil, fmt.Errorf("%w: facets", repository.ErrNotSupported)
+	}
+	return facets.Facets(ctx, query, fields)
+}
+
+// normalizeSkills resolves skill aliases in a query so "golang" and "k8s"
+// match the stored tags
+func (s *JobService) normalizeSkills(query *models.JobSearchQuery) {
+	query.Skills = s.tagger.Normalize(query.Skills)
+	query.SkillsAny = s.tagger.Normalize(query.SkillsAny)
+
+	if query.Expr != nil {
+		querylang.Walk(query.Expr, func(t *querylang.Term) {
+			if t.Field == "skill" {
+				if skills := s.tagger.Normalize([]string{t.Value}); len(skills) == 1 {
+					t.Value = skills[0]
+				}
+			}
+		})
+	}
+}
+
+// GetStats retrieves job statistics. Stores that precompute them answer
+// as of their last refresh unless fresh is set, which refreshes first if
+// they are older than freshStatsAge.
+func (s *JobService) GetStats(ctx context.Context, fresh bool) (*models.JobStats, error) {
+	stats, err := s.repo.GetStats(ctx)
+	if err != nil || !fresh || time.Since(stats.RefreshedAt) < freshStatsAge {
+		return stats, err
+	}
+
+	if err := s.refreshStatsShared(ctx); err != nil {
+		return nil, err
+	}
 	return s.repo.GetStats(ctx)
 }
 
+// refreshStatsShared refreshes precomputed stats, joining the refresh of
+// another request if one is running instead of starting a second one
+func (s *JobService) refreshStatsShared(ctx context.Context) error {
+	s.refreshMu.Lock()
+	refresh := s.refresh
+	if refresh == nil {
+		refresh = &statsRefresh{done: make(chan struct{})}
+		s.refresh = refresh
+		go func() {
+			// Detached from the request, which others may be waiting with
+			refresh.err = s.RefreshStats(context.WithoutCancel(ctx))
+
+			s.refreshMu.Lock()
+			s.refresh = nil
+			s.refreshMu.Unlock()
+			close(refresh.done)
+		}()
+	}
+	s.refreshMu.Unlock()
+
+	select {
+	case <-refresh.done:
+		return refresh.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RefreshStats recomputes precomputed job statistics. Stores computing
+// them on every call need no refresh.
+func (s *JobService) RefreshStats(ctx context.Context) error {
+	refresher, ok := s.repo.(repository.StatsRefresher)
+	if !ok {
+		return nil
+	}
+
+	start := time.Now()
+	if err := refresher.RefreshStats(ctx); err != nil {
+		return err
+	}
+	logger.Info("Refreshed job stats in %v", time.Since(start))
+	return nil
+}
+
+// ScheduleStatsRefresh refreshes the job statistics every interval until
+// ctx is done
+func (s *JobService) ScheduleStatsRefresh(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.RefreshStats(ctx); err != nil {
+				logger.Error("Failed to refresh job stats: %v", err)
+			}
+		}
+	}
+}
+
+// TimeSeries counts new and active postings per bucket. Unlike search,
+// closed and expired jobs are counted unless the filter sets a status.
+func (s *JobService) TimeSeries(ctx context.Context, query *models.TimeSeriesQuery) ([]models.TimeSeriesPoint, error) {
+	if query.Filter == nil {
+		query.Filter = &models.JobSearchQuery{}
+	}
+	s.normalizeSkills(query.Filter)
+	if query.Filter.Status == "" {
+		query.Filter.Status = "all"
+	}
+	return s.repo.TimeSeries(ctx, query)
+}
+
+// SalaryStats computes salary percentiles per group of matching jobs.
+// Groups with fewer than the default minimum of salaries are left out
+// unless the query sets its own.
+func (s *JobService) SalaryStats(ctx context.Context, query *models.SalaryStatsQuery) ([]models.SalaryStats, error) {
+	if query.Filter == nil {
+		query.Filter = &models.JobSearchQuery{}
+	}
+	s.normalizeSkills(query.Filter)
+	if query.MinSamples <= 0 {
+		query.MinSamples = models.DefaultSalaryMinSamples
+	}
+	return s.repo.SalaryStats(ctx, query)
+}
+
 // RunScraper runs the scraper and stores results
 func (s *JobService) RunScraper(ctx context.Context, query string) (int, error) {
 	logger.Info("Starting job scraper for query: %s", query)
 
 	// Run the scraper
+	runStart := time.Now()
 	jobs, err := s.scraper.Start(ctx, query)
 	if err != nil {
 		return 0, fmt.Errorf("scraper failed: %w", err)
 	}
 
+	// Judge every source against its recent runs
+	if s.health != nil {
+		if err := s.health.Record(ctx, s.scraper.GetStats().SourceRuns); err != nil {
+			logger.Error("Failed to record source health: %v", err)
+		}
+	}
+
+	// Enrich and score jobs before storing
+	for _, job := range jobs {
+		s.enrich(ctx, job)
+	}
+	if s.detector != nil {
+		if flagged := s.detector.Apply(jobs); flagged > 0 {
+			logger.Info("Flagged %d suspicious jobs", flagged)
+		}
+	}
+	quality.Apply(jobs, quality.Sources(jobs))
+
 	// Store jobs in database with deduplication
-	if err := s.repo.CreateBatch(ctx, jobs); err != nil {
+	result, err := s.repo.CreateBatch(ctx, jobs)
+	if err != nil {
 		return 0, fmt.Errorf("failed to store jobs: %w", err)
 	}
+	for _, r := range result.Rejected {
+		logger.Error("Rejected job %q from %s: %s", jobs[r.Row].Title, jobs[r.Row].Source, r.Reason)
+	}
+
+	// Rejected jobs were left without an ID
+	stored := make([]*models.Job, 0, len(jobs))
+	for _, job := range jobs {
+		if job.ID != 0 {
+			stored = append(stored, job)
+		}
+	}
+
+	// Group near-duplicates from different sources into canonical jobs
+	if s.clusterer != nil {
+		if err := s.clusterer.Assign(ctx, stored); err != nil {
+			return 0, fmt.Errorf("failed to cluster jobs: %w", err)
+		}
+
+		// Rescore with the sources of each job's cluster, which spans runs,
+		// rather than of this batch
+		if err := s.rescoreSources(ctx, stored); err != nil {
+			logger.Error("Failed to rescore jobs by cluster sources: %v", err)
+		}
+	}
+
+	// Close listings their sources stopped returning
+	if s.lifecycle != nil {
+		if err := s.lifecycle.Complete(ctx, s.scraper.GetStats().CompletedSources, runStart); err != nil {
+			return 0, fmt.Errorf("failed to update listing lifecycle: %w", err)
+		}
+	}
+
+	// Stats follow the run; a failed refresh is retried on schedule
+	if err := s.RefreshStats(ctx); err != nil {
+		logger.Error("Failed to refresh job stats: %v", err)
+	}
+
+	logger.Info("Successfully scraped %d jobs: %d new, %d updated, %d rejected",
+		len(jobs), result.Inserted, result.Updated, len(result.Rejected))
+	return len(stored), nil
+}
+
+// rescoreSources scores stored jobs by the sources listing their clusters
+func (s *JobService) rescoreSources(ctx context.Context, jobs []*models.Job) error {
+	updater, ok := s.repo.(repository.QualityUpdater)
+	if !ok || len(jobs) == 0 {
+		return nil
+	}
+
+	sources, err := s.clusterer.Sources(ctx, jobs)
+	if err != nil {
+		return err
+	}
+	quality.Apply(jobs, sources)
+	return updater.SetQuality(ctx, jobs)
+}
+
+// enrich derives normalized fields from a scraped job
+func (s *JobService) enrich(ctx context.Context, job *models.Job) {
+	// Sanitize first so everything downstream works on plain text
+	desc := sanitize.Process(job.Description)
+	job.Description = desc.Text
+	job.DescriptionHTML = desc.HTML
+	job.DescriptionMarkdown = desc.Markdown
+
+	job.Tags = s.tagger.Extract(job.Title, job.Description)
+
+	title := titles.Normalize(job.Title)
+	job.NormalizedTitle = title.Normalized
+	job.Seniority = title.Seniority
+	job.RoleFamily = title.RoleFamily
+
+	if job.Salary != "" {
+		if r, err := salary.Parse(job.Salary); err == nil {
+			job.SalaryMin, job.SalaryMax = &r.Min, &r.Max
+			job.SalaryCurrency = r.Currency
+		}
+	}
+
+	if p, ok := geo.Locate(job.Location); ok {
+		job.Latitude, job.Longitude = &p.Lat, &p.Lng
+	}
+
+	if s.companies == nil {
+		return
+	}
+	if id, err := s.companies.Resolve(ctx, job.Company); err != nil {
+		logger.Error("Failed to resolve company %q: %v", job.Company, err)
+	} else {
+		job.CompanyID = &id
+	}
+}
 
-	logger.Info("Successfully scraped and stored %d jobs", len(jobs))
-	return len(jobs), nil
+// SourceHealth returns the health of every source that has run
+func (s *JobService) SourceHealth(ctx context.Context) ([]models.SourceHealth, error) {
+	if s.health == nil {
+		return nil, fmt.Errorf("%w: source health", repository.ErrNotSupported)
+	}
+	return s.health.Health(ctx)
+}
+
+// SourceRuns returns the latest runs of a source, newest first
+func (s *JobService) SourceRuns(ctx context.Context, source string, limit int) ([]models.SourceRun, error) {
+	if s.health == nil {
+		return nil, fmt.Errorf("%w: source health", repository.ErrNotSupported)
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	return s.health.Runs(ctx, source, limit)
+}
+
+// ReviewFlag records an admin's verdict on a job, scam or legit, and
+// keeps the job as a training example of the scam classifier
+func (s *JobService) ReviewFlag(ctx context.Context, id int64, review string) (*models.Job, error) {
+	job, err := s.repo.ReviewFlag(ctx, id, review)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.detector != nil {
+		if err := s.detector.Label(job, review); err != nil {
+			logger.Error("Failed to store the review of job %d as a training label: %v", id, err)
+		}
+	}
+	return job, nil
 }
 
 // GetScraperStats returns current scraper statistics
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/abhisheksainimitawa/job-aggregator/internal/models"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/repository"
+	"github.com/abhisheksainimitawa/job-aggregator/internal/retention"
+	"github.com/abhisheksainimitawa/job-aggregator/pkg/logger"
+)
+
+// errNoRetentionRuns is returned when retention runs are not recorded
+var errNoRetentionRuns = fmt.Errorf("%w: retention audit log", repository.ErrNotSupported)
+
+// RetentionService runs the retention engine and keeps its audit log
+type RetentionService struct {
+	engine *retention.Engine
+	runs   *repository.RetentionRepository
+}
+
+// NewRetentionService creates a new retention service. Runs are recorded
+// in Postgres only; with other job stores runs is nil and runs are only
+// logged.
+func NewRetentionService(engine *retention.Engine, runs *repository.RetentionRepository) *RetentionService {
+	return &RetentionService{
+		engine: engine,
+		runs:   runs,
+	}
+}
+
+// Rules returns the configured retention rules
+func (s *RetentionService) Rules() []models.RetentionRule {
+	return s.engine.Rules()
+}
+
+// Run applies the retention rules and records the run, including a run
+// that failed part way
+func (s *RetentionService) Run(ctx context.Context, dryRun bool) (*models.RetentionRun, error) {
+	run, runErr := s.engine.Run(ctx, time.Now(), dryRun)
+
+	if s.runs != nil {
+		// Record the run even if ctx was cancelled during it
+		recordCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := s.runs.Record(recordCtx, run); err != nil {
+			logger.Error("Failed to record retention run: %v", err)
+		}
+	}
+
+	for _, result := range run.Rules {
+		if dryRun {
+			logger.Info("Retention dry run: %s would delete %d jobs", result.Rule.Name, result.Matched)
+		} else {
+			logger.Info("Retention: %s deleted %d jobs in %d batches", result.Rule.Name, result.Deleted, result.Batches)
+		}
+	}
+
+	return run, runErr
+}
+
+// ListRuns returns the latest recorded retention runs
+func (s *RetentionService) ListRuns(ctx context.Context, limit int) ([]*models.RetentionRun, error) {
+	if s.runs == nil {
+		return nil, errNoRetentionRuns
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	return s.runs.List(ctx, limit)
+}
+
+// Schedule runs the retention rules every interval until ctx is done
+func (s *RetentionService) Schedule(ctx context.Context, interval time.Duration, dryRun bool) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.Run(ctx, dryRun); err != nil {
+				logger.Error("Retention run failed: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package tagging
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Skill describes a canonical technology tag and the spellings that map to it
+type Skill struct {
+	Name     string   `json:"name"`
+	Aliases  []string `json:"aliases,omitempty"`
+	Category string   `json:"category,omitempty"`
+	// Ambiguous marks names that are also ordinary English words ("Go", "Rust",
+	// "Swift"). They only count when the surrounding text confirms them.
+	Ambiguous bool `json:"ambiguous,omitempty"`
+}
+
+// Dictionary maps aliases to canonical skill names
+type Dictionary struct {
+	skills   map[string]Skill
+	aliases  map[string]string
+	maxWords int
+}
+
+// NewDictionary creates a dictionary from the given skills
+func NewDictionary(skills []Skill) *Dictionary {
+	d := &Dictionary{
+		skills:  make(map[string]Skill),
+		aliases: make(map[string]string),
+	}
+	for _, skill := range skills {
+		d.Add(skill)
+	}
+	return d
+}
+
+// DefaultDictionary returns the curated built-in skills dictionary
+func DefaultDictionary() *Dictionary {
+	return NewDictionary(defaultSkills)
+}
+
+// LoadDictionary returns the default dictionary extended with the skills
+// defined in the JSON file at path. An empty path yields the defaults.
+func LoadDictionary(path string) (*Dictionary, error) {
+	d := DefaultDictionary()
+	if path == "" {
+		return d, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read skills dictionary: %w", err)
+	}
+
+	var skills []Skill
+	if err := json.Unmarshal(data, &skills); err != nil {
+		return nil, fmt.Errorf("failed to parse skills dictionary: %w", err)
+	}
+
+	for _, skill := range skills {
+		d.Add(skill)
+	}
+	return d, nil
+}
+
+// Add registers a skill, replacing any existing skill with the same name
+func (d *Dictionary) Add(skill Skill) {
+	skill.Name = normalizeTerm(skill.Name)
+	if skill.Name == "" {
+		return
+	}
+
+	if old, ok := d.skills[skill.Name]; ok {
+		for _, alias := range old.Aliases {
+			delete(d.aliases, normalizeTerm(alias))
+		}
+	}
+	d.skills[skill.Name] = skill
+
+	for _, term := range append([]string{skill.Name}, skill.Aliases...) {
+		term = normalizeTerm(term)
+		if term == "" {
+			continue
+		}
+		d.aliases[term] = skill.Name
+		if n := len(strings.Fields(term)); n > d.maxWords {
+			d.maxWords = n
+		}
+	}
+}
+
+// Canonical returns the canonical skill name for a term or alias
+func (d *Dictionary) Canonical(term string) (string, bool) {
+	name, ok := d.aliases[normalizeTerm(term)]
+	return name, ok
+}
+
+// Skill returns the skill registered under a canonical name
+func (d *Dictionary) Skill(name string) (Skill, bool) {
+	skill, ok := d.skills[name]
+	return skill, ok
+}
+
+// normalizeTerm lowercases a term and collapses its whitespace
+func normalizeTerm(term string) string {
+	return strings.Join(strings.Fields(strings.ToLower(term)), " ")
+}
+
+// defaultSkills is the curated list of technologies we tag out of the box
+var defaultSkills = []Skill{
+	// Languages
+	{Name: "go", Aliases: []string{"golang"}, Category: "language", Ambiguous: true},
+	{Name: "python", Aliases: []string{"python3"}, Category: "language"},
+	{Name: "java", Category: "language"},
+	{Name: "javascript", Aliases: []string{"js", "ecmascript"}, Category: "language"},
+	{Name: "typescript", Category: "language"},
+	{Name: "rust", Aliases: []string{"rustlang"}, Category: "language", Ambiguous: true},
+	{Name: "c++", Aliases: []string{"cpp"}, Category: "language"},
+	{Name: "c#", Aliases: []string{"csharp"}, Category: "language"},
+	{Name: "ruby", Category: "language"},
+	{Name: "php", Category: "language"},
+	{Name: "scala", Category: "language"},
+	{Name: "kotlin", Category: "language"},
+	{Name: "swift", Category: "language", Ambiguous: true},
+	{Name: "elixir", Category: "language"},
+	{Name: "sql", Category: "language"},
+
+	// Frameworks and runtimes
+	{Name: "react", Aliases: []string{"react.js", "reactjs"}, Category: "framework", Ambiguous: true},
+	{Name: "vue", Aliases: []string{"vue.js", "vuejs"}, Category: "framework"},
+	{Name: "angular", Aliases: []string{"angularjs"}, Category: "framework"},
+	{Name: "node.js", Aliases: []string{"nodejs"}, Category: "runtime"},
+	{Name: ".net", Aliases: []string{"dotnet", ".net core"}, Category: "framework"},
+	{Name: "rails", Aliases: []string{"ruby on rails", "ror"}, Category: "framework"},
+	{Name: "django", Category: "framework"},
+	{Name: "spring", Aliases: []string{"spring boot"}, Category: "framework", Ambiguous: true},
+	{Name: "grpc", Category: "framework"},
+	{Name: "graphql", Category: "framework"},
+
+	// Infrastructure
+	{Name: "kubernetes", Aliases: []string{"k8s"}, Category: "infrastructure"},
+	{Name: "docker", Category: "infrastructure"},
+	{Name: "helm", Category: "infrastructure"},
+	{Name: "terraform", Category: "infrastructure"},
+	{Name: "ansible", Category: "infrastructure"},
+	{Name: "linux", Category: "infrastructure"},
+	{Name: "aws", Aliases: []string{"amazon web services"}, Category: "cloud"},
+	{Name: "gcp", Aliases: []string{"google cloud", "google cloud platform"}, Category: "cloud"},
+	{Name: "azure", Aliases: []string{"microsoft azure"}, Category: "cloud"},
+	{Name: "prometheus", Category: "observability"},
+	{Name: "grafana", Category: "observability"},
+	{Name: "jenkins", Category: "ci"},
+	{Name: "github actions", Category: "ci"},
+
+	// Data stores and messaging
+	{Name: "postgresql", Aliases: []string{"postgres", "psql"}, Category: "database"},
+	{Name: "mysql", Category: "database"},
+	{Name: "mongodb", Aliases: []string{"mongo"}, Category: "database"},
+	{Name: "redis", Category: "database"},
+	{Name: "elasticsearch", Aliases: []string{"elastic search"}, Category: "database"},
+	{Name: "cassandra", Category: "database"},
+	{Name: "kafka", Aliases: []string{"apache kafka"}, Category: "messaging"},
+	{Name: "rabbitmq", Category: "messaging"},
+
+	// Data and ML
+	{Name: "spark", Aliases: []string{"apache spark", "pyspark"}, Category: "data", Ambiguous: true},
+	{Name: "airflow", Aliases: []string{"apache airflow"}, Category: "data"},
+	{Name: "pandas", Category: "data"},
+	{Name: "tensorflow", Category: "ml"},
+	{Name: "pytorch", Category: "ml"},
+	{Name: "machine learning", Category: "ml"},
+
+	// Practices
+	{Name: "microservices", Aliases: []string{"microservice"}, Category: "practice"},
+	{Name: "distributed systems", Category: "practice"},
+}
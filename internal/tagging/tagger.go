@@ -0,0 +1,204 @@
+package tagging
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// contextWindow is how many tokens on either side of an ambiguous term are
+// inspected for confirming context
+const contextWindow = 3
+
+// contextWords are words that, near an ambiguous term, indicate it is being
+// used as a technology name rather than as ordinary English
+var contextWords = map[string]bool{
+	"developer": true, "developers": true, "engineer": true, "engineers": true,
+	"engineering": true, "programmer": true, "programming": true, "language": true,
+	"languages": true, "lang": true, "backend": true, "back-end": true,
+	"frontend": true, "front-end": true, "fullstack": true, "full-stack": true,
+	"code": true, "coding": true, "codebase": true, "experience": true,
+	"years": true, "proficiency": true, "proficient": true, "stack": true,
+	"services": true, "service": true, "microservices": true, "framework": true,
+	"frameworks": true, "library": true, "libraries": true, "sdk": true,
+	"written": true, "development": true, "skills": true, "knowledge": true,
+}
+
+// Tagger extracts skill tags from job text using a Dictionary
+type Tagger struct {
+	dict *Dictionary
+}
+
+// NewTagger creates a new tagger backed by the given dictionary
+func NewTagger(dict *Dictionary) *Tagger {
+	return &Tagger{dict: dict}
+}
+
+// Dictionary returns the dictionary used by the tagger
+func (t *Tagger) Dictionary() *Dictionary {
+	return t.dict
+}
+
+// Extract returns the sorted, de-duplicated skill tags found in a job's
+// title and description
+func (t *Tagger) Extract(title, description string) []string {
+	found := make(map[string]bool)
+	t.scan(title, found)
+	t.scan(description, found)
+
+	tags := make([]string, 0, len(found))
+	for tag := range found {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// Normalize maps user-supplied skill names to canonical tags, dropping
+// duplicates. Unknown names are kept in lowercase so custom tags still match.
+func (t *Tagger) Normalize(names []string) []string {
+	seen := make(map[string]bool)
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		name = normalizeTerm(name)
+		if name == "" {
+			continue
+		}
+		if canonical, ok := t.dict.Canonical(name); ok {
+			name = canonical
+		}
+		if !seen[name] {
+			seen[name] = true
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+// match is a dictionary hit within a token stream
+type match struct {
+	start, end int
+	skill      string
+	ambiguous  bool
+}
+
+// scan finds all dictionary matches in text and records confirmed ones
+func (t *Tagger) scan(text string, found map[string]bool) {
+	tokens := tokenize(text)
+	if len(tokens) == 0 {
+		return
+	}
+
+	lower := make([]string, len(tokens))
+	for i, tok := range tokens {
+		lower[i] = strings.ToLower(tok)
+	}
+
+	// Greedy longest-match over n-grams so "ruby on rails" wins over "ruby"
+	var matches []match
+	for i := 0; i < len(tokens); {
+		matched := false
+		for n := t.dict.maxWords; n >= 1; n-- {
+			if i+n > len(tokens) {
+				continue
+			}
+			term := strings.Join(lower[i:i+n], " ")
+			name, ok := t.dict.aliases[term]
+			if !ok {
+				continue
+			}
+			skill := t.dict.skills[name]
+			matches = append(matches, match{
+				start:     i,
+				end:       i + n,
+				skill:     name,
+				ambiguous: skill.Ambiguous && term == skill.Name,
+			})
+			i += n
+			matched = true
+			break
+		}
+		if !matched {
+			i++
+		}
+	}
+
+	for idx, m := range matches {
+		if !m.ambiguous || confirmed(tokens, lower, matches, idx) {
+			found[m.skill] = true
+		}
+	}
+}
+
+// confirmed applies the context rules for an ambiguous match. The term must
+// be capitalized as a proper noun, and either sit next to a context word or
+// appear alongside another, unambiguous skill (as in "Go, Docker and k8s").
+func confirmed(tokens, lower []string, matches []match, idx int) bool {
+	m := matches[idx]
+	if !isCapitalized(tokens[m.start]) {
+		return false
+	}
+
+	from := m.start - contextWindow
+	if from < 0 {
+		from = 0
+	}
+	to := m.end + contextWindow
+	if to > len(tokens) {
+		to = len(tokens)
+	}
+
+	for i := from; i < to; i++ {
+		if i >= m.start && i < m.end {
+			continue
+		}
+		if contextWords[lower[i]] {
+			return true
+		}
+	}
+
+	for j, other := range matches {
+		if j == idx || other.ambiguous {
+			continue
+		}
+		if other.end > from && other.start < to {
+			return true
+		}
+	}
+	return false
+}
+
+// isCapitalized reports whether a token starts with an upper-case letter
+func isCapitalized(token string) bool {
+	for _, r := range token {
+		return unicode.IsUpper(r)
+	}
+	return false
+}
+
+// tokenize splits text into word tokens. Characters that appear inside
+// technology names (+, #, ., -) are kept, except at the edges of a token.
+func tokenize(text string) []string {
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return false
+		}
+		switch r {
+		case '+', '#', '.', '-':
+			return false
+		}
+		return true
+	})
+
+	tokens := make([]string, 0, len(fields))
+	for _, f := range fields {
+		// Keep a leading dot for names like ".NET" but drop sentence punctuation
+		f = strings.TrimRight(f, ".-")
+		f = strings.TrimLeft(f, "-")
+		if f == "" || f == "." {
+			continue
+		}
+		tokens = append(tokens, f)
+	}
+	return tokens
+}
@@ -0,0 +1,93 @@
+package tagging
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestTagger_Extract(t *testing.T) {
+	tagger := NewTagger(DefaultDictionary())
+
+	tests := []struct {
+		name        string
+		title       string
+		description string
+		want        []string
+	}{
+		{
+			name:  "alias in title",
+			title: "Backend Engineer - Golang",
+			want:  []string{"go"},
+		},
+		{
+			name:  "ambiguous term with context word",
+			title: "Senior Go Developer",
+			want:  []string{"go"},
+		},
+		{
+			name:        "ambiguous term next to other skills",
+			title:       "Platform Engineer",
+			description: "Our stack: Go, K8s and PostgreSQL.",
+			want:        []string{"go", "kubernetes", "postgresql"},
+		},
+		{
+			name:        "ordinary english is ignored",
+			title:       "Account Manager",
+			description: "You will go the extra mile. Go ahead and apply today!",
+			want:        []string{},
+		},
+		{
+			name:        "multi word alias",
+			title:       "Web Developer",
+			description: "Experience with Ruby on Rails and Amazon Web Services",
+			want:        []string{"aws", "rails"},
+		},
+		{
+			name:        "punctuated names",
+			title:       "Full Stack Developer (Go/React)",
+			description: "C++, C# and .NET experience is a plus.",
+			want:        []string{".net", "c#", "c++", "go", "react"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tagger.Extract(tt.title, tt.description)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Extract() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTagger_Normalize(t *testing.T) {
+	tagger := NewTagger(DefaultDictionary())
+
+	got := tagger.Normalize([]string{"Golang", "k8s", "go", " ", "Custom Tool"})
+	want := []string{"go", "kubernetes", "custom tool"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Normalize() = %v, want %v", got, want)
+	}
+}
+
+func TestLoadDictionary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "skills.json")
+	data := `[{"name": "temporal", "aliases": ["temporal.io"]}]`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatalf("failed to write dictionary: %v", err)
+	}
+
+	dict, err := LoadDictionary(path)
+	if err != nil {
+		t.Fatalf("LoadDictionary() error = %v", err)
+	}
+
+	if name, ok := dict.Canonical("Temporal.io"); !ok || name != "temporal" {
+		t.Errorf("Canonical(Temporal.io) = %q, %v", name, ok)
+	}
+	if _, ok := dict.Canonical("golang"); !ok {
+		t.Error("Expected default skills to be kept")
+	}
+}
@@ -0,0 +1,291 @@
+package titles
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Seniority levels, ordered from least to most senior
+const (
+	SeniorityIntern    = "intern"
+	SeniorityJunior    = "junior"
+	SeniorityMid       = "mid"
+	SenioritySenior    = "senior"
+	SeniorityStaff     = "staff"
+	SeniorityPrincipal = "principal"
+	SeniorityLead      = "lead"
+	SeniorityManager   = "manager"
+)
+
+// Role families
+const (
+	RoleBackend        = "backend"
+	RoleFrontend       = "frontend"
+	RoleFullstack      = "fullstack"
+	RoleSREDevOps      = "sre_devops"
+	RoleInfrastructure = "infrastructure"
+	RoleData           = "data"
+	RoleML             = "ml"
+	RoleMobile         = "mobile"
+	RoleSecurity       = "security"
+	RoleQA             = "qa"
+	RoleManagement     = "management"
+	RoleSoftware       = "software"
+	RoleOther          = "other"
+)
+
+// Seniorities lists every seniority level
+var Seniorities = []string{
+	SeniorityIntern, SeniorityJunior, SeniorityMid, SenioritySenior,
+	SeniorityStaff, SeniorityPrincipal, SeniorityLead, SeniorityManager,
+}
+
+// RoleFamilies lists every role family
+var RoleFamilies = []string{
+	RoleBackend, RoleFrontend, RoleFullstack, RoleSREDevOps, RoleInfrastructure,
+	RoleData, RoleML, RoleMobile, RoleSecurity, RoleQA, RoleManagement,
+	RoleSoftware, RoleOther,
+}
+
+// Title is a job title split into its normalized parts
+type Title struct {
+	Normalized string // lowercased title with seniority words removed
+	Seniority  string
+	RoleFamily string
+}
+
+// rule maps a set of phrases to a value. Rules are checked in order and
+// the first matching one wins.
+type rule struct {
+	value   string
+	phrases []string
+}
+
+// seniorityRules are ordered so that the most specific level wins, e.g.
+// "Senior Engineering Manager" is a manager, not a senior IC
+var seniorityRules = []rule{
+	{SeniorityManager, []string{"manager", "head of", "director", "vp", "vice president", "engineering manager"}},
+	{SeniorityPrincipal, []string{"principal", "distinguished", "fellow"}},
+	{SeniorityStaff, []string{"staff"}},
+	{SeniorityLead, []string{"lead", "tech lead", "team lead", "leader"}},
+	{SenioritySenior, []string{"senior"}},
+	{SeniorityIntern, []string{"intern", "internship", "trainee", "apprentice", "co op"}},
+	{SeniorityJunior, []string{"junior", "entry level", "graduate", "new grad"}},
+	{SeniorityMid, []string{"mid", "mid level", "intermediate"}},
+}
+
+// levelNumerals are the levels of leveled titles like "Engineer II" or
+// "Level 3". They only count in a level position (see levelAt), so
+// "3 Month Contract" and "Tier 1 Support" say nothing about seniority.
+var levelNumerals = map[string]string{
+	"i": SeniorityJunior, "1": SeniorityJunior,
+	"ii": SeniorityMid, "2": SeniorityMid,
+	"iii": SenioritySenior, "3": SenioritySenior,
+	"iv": SenioritySenior, "4": SenioritySenior,
+}
+
+// levelWords are the role words a level numeral can follow
+var levelWords = map[string]bool{
+	"engineer": true, "developer": true, "programmer": true, "analyst": true,
+	"scientist": true, "administrator": true, "architect": true, "designer": true,
+}
+
+// levelCodes are levels written as one word, like "L3"
+var levelCodes = map[string]string{
+	"l1": SeniorityJunior, "l2": SeniorityMid, "l3": SenioritySenior, "l4": SenioritySenior,
+}
+
+// roleRules are ordered so that compound roles ("full stack") are matched
+// before their parts ("frontend", "backend")
+var roleRules = []rule{
+	{RoleFullstack, []string{"full stack"}},
+	{RoleSREDevOps, []string{"sre", "site reliability", "devops", "dev ops", "devsecops", "reliability"}},
+	{RoleML, []string{"machine learning", "ml", "ai", "deep learning", "mlops"}},
+	{RoleData, []string{"data", "analytics", "etl", "bi"}},
+	{RoleSecurity, []string{"security", "appsec", "infosec", "cybersecurity"}},
+	{RoleMobile, []string{"mobile", "ios", "android", "flutter", "react native"}},
+	{RoleQA, []string{"qa", "quality", "test", "tester", "sdet", "automation engineer"}},
+	{RoleFrontend, []string{"front end", "ui", "react", "angular", "vue", "web developer"}},
+	{RoleInfrastructure, []string{"infrastructure", "platform", "cloud", "systems", "network"}},
+	{RoleBackend, []string{"back end", "api", "server", "microservices", "distributed systems"}},
+	{RoleManagement, []string{"manager", "head of", "director", "vp"}},
+}
+
+// backendLanguages are languages that imply a backend role when no other
+// family matched ("Golang Software Engineer")
+var backendLanguages = []string{"go", "java", "scala", "elixir", "ruby", "php", "c#", ".net", "rust"}
+
+// softwareWords mark a generic software engineering title
+var softwareWords = []string{"engineer", "developer", "programmer", "swe", "software"}
+
+// abbreviations are expanded before matching
+var abbreviations = map[string]string{
+	"sr":        "senior",
+	"snr":       "senior",
+	"jr":        "junior",
+	"eng":       "engineer",
+	"engr":      "engineer",
+	"dev":       "developer",
+	"devs":      "developers",
+	"mgr":       "manager",
+	"swe":       "software engineer",
+	"sde":       "software engineer",
+	"golang":    "go",
+	"fullstack": "full stack",
+	"frontend":  "front end",
+	"backend":   "back end",
+}
+
+// seniorityWords are dropped from the normalized title
+var seniorityWords = map[string]bool{
+	"senior": true, "junior": true, "staff": true, "principal": true,
+	"lead": true, "mid": true, "intern": true, "entry": true, "level": true,
+}
+
+// Normalize splits a raw job title into seniority, role family and a
+// normalized title suitable for grouping
+func Normalize(raw string) Title {
+	words, clauses := tokenize(raw)
+	text := " " + strings.Join(words, " ") + " "
+
+	title := Title{
+		Seniority:  SeniorityMid,
+		RoleFamily: RoleOther,
+	}
+
+	// Seniority words win over level numerals
+	if v, ok := matchRule(text, seniorityRules); ok {
+		title.Seniority = v
+	} else if v, ok := level(words, clauses); ok {
+		title.Seniority = v
+	}
+
+	if v, ok := matchRule(text, roleRules); ok {
+		title.RoleFamily = v
+	} else if containsAny(text, backendLanguages) {
+		title.RoleFamily = RoleBackend
+	} else if containsAny(text, softwareWords) {
+		title.RoleFamily = RoleSoftware
+	}
+
+	kept := make([]string, 0, len(words))
+	for i, w := range words {
+		if _, ok := levelAt(words, clauses, i); !ok && !seniorityWords[w] {
+			kept = append(kept, w)
+		}
+	}
+	title.Normalized = strings.Join(kept, " ")
+
+	return title
+}
+
+// IsSeniority reports whether s is a known seniority level
+func IsSeniority(s string) bool {
+	return contains(Seniorities, s)
+}
+
+// IsRoleFamily reports whether s is a known role family
+func IsRoleFamily(s string) bool {
+	return contains(RoleFamilies, s)
+}
+
+// level returns the seniority of the first level in a title's words
+func level(words []string, clauses []int) (string, bool) {
+	for i := range words {
+		if v, ok := levelAt(words, clauses, i); ok {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// levelAt returns the seniority of the word at i if it is a level code or
+// a level numeral in a level position: after "level", or ending the clause
+// of a role word as in "Engineer II, Backend"
+func levelAt(words []string, clauses []int, i int) (string, bool) {
+	if v, ok := levelCodes[words[i]]; ok {
+		return v, true
+	}
+	v, ok := levelNumerals[words[i]]
+	if !ok || i == 0 || clauses[i-1] != clauses[i] {
+		return "", false
+	}
+	prev := words[i-1]
+	last := i == len(words)-1 || clauses[i+1] != clauses[i]
+	if prev == "level" || (levelWords[prev] && last) {
+		return v, true
+	}
+	return "", false
+}
+
+// matchRule returns the value of the first rule with a matching phrase
+func matchRule(text string, rules []rule) (string, bool) {
+	for _, r := range rules {
+		if containsAny(text, r.phrases) {
+			return r.value, true
+		}
+	}
+	return "", false
+}
+
+// containsAny reports whether the padded text contains any whole phrase
+func containsAny(text string, phrases []string) bool {
+	for _, p := range phrases {
+		if strings.Contains(text, " "+p+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// tokenize lowercases a title and splits it into words, expanding
+// abbreviations and treating hyphens and slashes as separators. clauses
+// holds the clause of each word; punctuation other than spaces, like the
+// dash in "Developer - 3 Month Contract", starts a new clause.
+func tokenize(raw string) (words []string, clauses []int) {
+	clause := 0
+	sep := false
+	field := strings.Builder{}
+	flush := func() {
+		f := strings.TrimRight(field.String(), ".")
+		field.Reset()
+		if f == "" {
+			return
+		}
+		if full, ok := abbreviations[f]; ok {
+			for _, w := range strings.Fields(full) {
+				words = append(words, w)
+				clauses = append(clauses, clause)
+			}
+			return
+		}
+		words = append(words, f)
+		clauses = append(clauses, clause)
+	}
+
+	for _, r := range strings.ToLower(raw) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '+' || r == '#' || r == '.' {
+			if sep && len(words) > 0 {
+				clause++
+			}
+			sep = false
+			field.WriteRune(r)
+			continue
+		}
+		flush()
+		if !unicode.IsSpace(r) {
+			sep = true
+		}
+	}
+	flush()
+	return words, clauses
+}
@@ -0,0 +1,45 @@
+package titles
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		title      string
+		seniority  string
+		roleFamily string
+		normalized string
+	}{
+		{"Senior Go Developer", SenioritySenior, RoleBackend, "go developer"},
+		{"Sr. Golang Developer", SenioritySenior, RoleBackend, "go developer"},
+		{"Backend Engineer - Golang", SeniorityMid, RoleBackend, "back end engineer go"},
+		{"Staff Software Engineer", SeniorityStaff, RoleSoftware, "software engineer"},
+		{"Principal Engineer", SeniorityPrincipal, RoleSoftware, "engineer"},
+		{"Full Stack Developer (Go/React)", SeniorityMid, RoleFullstack, "full stack developer go react"},
+		{"Site Reliability Engineer", SeniorityMid, RoleSREDevOps, "site reliability engineer"},
+		{"Senior Engineering Manager", SeniorityManager, RoleManagement, "engineering manager"},
+		{"Software Engineer II", SeniorityMid, RoleSoftware, "software engineer"},
+		{"Software Engineer Level 3", SenioritySenior, RoleSoftware, "software engineer"},
+		{"Data Analyst I", SeniorityJunior, RoleData, "data analyst"},
+		{"L3 Backend Engineer", SenioritySenior, RoleBackend, "back end engineer"},
+		{"Go Developer - 3 Month Contract", SeniorityMid, RoleBackend, "go developer 3 month contract"},
+		{"Level 2 Support Engineer", SeniorityMid, RoleSoftware, "support engineer"},
+		{"Tier 1 Support Engineer", SeniorityMid, RoleSoftware, "tier 1 support engineer"},
+		{"Data Engineering Intern", SeniorityIntern, RoleData, "data engineering"},
+		{"Cloud Platform Engineer", SeniorityMid, RoleInfrastructure, "cloud platform engineer"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			got := Normalize(tt.title)
+			if got.Seniority != tt.seniority {
+				t.Errorf("Seniority = %q, want %q", got.Seniority, tt.seniority)
+			}
+			if got.RoleFamily != tt.roleFamily {
+				t.Errorf("RoleFamily = %q, want %q", got.RoleFamily, tt.roleFamily)
+			}
+			if got.Normalized != tt.normalized {
+				t.Errorf("Normalized = %q, want %q", got.Normalized, tt.normalized)
+			}
+		})
+	}
+}
@@ -0,0 +1,272 @@
+package dateparse
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Precision describes how much of a parsed date can be trusted
+type Precision string
+
+const (
+	// PrecisionExact means the full timestamp was given
+	PrecisionExact Precision = "exact"
+	// PrecisionDay means the calendar day is known but not the time
+	PrecisionDay Precision = "day"
+	// PrecisionApproximate means the date was estimated from a relative
+	// phrase such as "3 days ago" or "30+ days ago"
+	PrecisionApproximate Precision = "approximate"
+	// PrecisionUnknown means no date was available and the scrape time was used
+	PrecisionUnknown Precision = "unknown"
+)
+
+// Result is a parsed posted date
+type Result struct {
+	Time      time.Time
+	Precision Precision
+}
+
+// Phrases are matched whole after normalization, in English, German,
+// French, Spanish, Portuguese and Dutch
+var (
+	justNowWords   = []string{"just posted", "just now", "gerade eben", "a l'instant", "ahora mismo", "agora mesmo", "zojuist"}
+	todayWords     = []string{"today", "heute", "aujourd'hui", "hoy", "hoje", "vandaag"}
+	yesterdayWords = []string{"yesterday", "gestern", "hier", "ayer", "ontem", "gisteren"}
+)
+
+// articles stand in for the number one ("an hour ago", "vor einem Tag")
+var articles = map[string]bool{
+	"a": true, "an": true, "one": true, "ein": true, "einem": true, "einer": true,
+	"einen": true, "un": true, "une": true, "uno": true, "una": true, "um": true,
+	"uma": true, "een": true,
+}
+
+// units maps localized time unit words to durations
+var units = map[string]time.Duration{}
+
+// months maps localized month names and abbreviations to months
+var months = map[string]time.Month{}
+
+func init() {
+	day := 24 * time.Hour
+	unitWords := map[time.Duration][]string{
+		time.Second: {"s", "sec", "secs", "second", "seconds", "sekunde", "sekunden", "seconde", "secondes", "segundo", "segundos", "seconden"},
+		time.Minute: {"m", "min", "mins", "minute", "minutes", "minuten", "minuto", "minutos", "minuut"},
+		time.Hour:   {"h", "hr", "hrs", "hour", "hours", "stunde", "stunden", "heure", "heures", "hora", "horas", "uur", "uren"},
+		day:         {"d", "day", "days", "tag", "tage", "tagen", "jour", "jours", "dia", "dias", "dag", "dagen"},
+		7 * day:     {"w", "wk", "wks", "week", "weeks", "woche", "wochen", "semaine", "semaines", "semana", "semanas", "weken"},
+		30 * day:    {"mo", "mos", "month", "months", "monat", "monate", "monaten", "mois", "mes", "meses", "maand", "maanden"},
+		365 * day:   {"y", "yr", "yrs", "year", "years", "jahr", "jahre", "jahren", "an", "ans", "ano", "anos", "jaar", "jaren"},
+	}
+	for d, words := range unitWords {
+		for _, w := range words {
+			units[w] = d
+		}
+	}
+
+	monthNames := [][]string{
+		{"january", "jan", "januar", "janvier", "enero", "janeiro", "januari"},
+		{"february", "feb", "februar", "fevrier", "febrero", "fevereiro", "februari"},
+		{"march", "mar", "marz", "mars", "marzo", "marco", "maart"},
+		{"april", "apr", "avril", "abril"},
+		{"may", "mai", "mayo", "maio", "mei"},
+		{"june", "jun", "juni", "juin", "junio", "junho"},
+		{"july", "jul", "juli", "juillet", "julio", "julho"},
+		{"august", "aug", "aout", "agosto", "augustus"},
+		{"september", "sep", "sept", "septembre", "septiembre", "setiembre", "setembro"},
+		{"october", "oct", "oktober", "octobre", "octubre", "outubro", "okt"},
+		{"november", "nov", "novembre", "noviembre", "novembro"},
+		{"december", "dec", "dezember", "decembre", "diciembre", "dezembro", "dez"},
+	}
+	for i, names := range monthNames {
+		for _, name := range names {
+			months[name] = time.Month(i + 1)
+		}
+	}
+}
+
+var (
+	// numericRelativeRe finds "3 days", "30+ days", "3d" and "2w"
+	numericRelativeRe = regexp.MustCompile(`\b(\d+)\s*\+?\s*([a-z]+)\b`)
+
+	isoRe = regexp.MustCompile(`\b\d{4}-\d{2}-\d{2}(?:[t ]\d{2}:\d{2}(?::\d{2}(?:\.\d+)?)?(?:z|[+-]\d{2}:?\d{2})?)?\b`)
+	// slashRe is US month/day/year; dotRe is European day.month.year
+	slashRe = regexp.MustCompile(`\b(\d{1,2})/(\d{1,2})/(\d{4})\b`)
+	dotRe   = regexp.MustCompile(`\b(\d{1,2})\.(\d{1,2})\.(\d{4})\b`)
+	// dayMonthRe is "15 January 2026", "15. Januar 2026", "15 de enero de 2026"
+	dayMonthRe = regexp.MustCompile(`\b(\d{1,2})\.?\s+(?:de\s+)?([a-z]+)\.?,?\s+(?:de\s+)?(\d{4})\b`)
+	// monthDayRe is "January 15, 2026" and "Jan 15 2026"
+	monthDayRe = regexp.MustCompile(`\b([a-z]+)\.?\s+(\d{1,2}),?\s+(\d{4})\b`)
+)
+
+// isoLayouts are tried in order against upper-cased ISO-8601 matches
+var isoLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+}
+
+// accents folds the accented characters that appear in supported languages
+var accents = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ä", "a", "ã", "a",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"í", "i", "î", "i", "ï", "i",
+	"ó", "o", "ô", "o", "ö", "o", "õ", "o",
+	"ú", "u", "û", "u", "ü", "u",
+	"ç", "c", "ñ", "n", "ß", "ss", "’", "'",
+)
+
+// Parse resolves a posted-date phrase against now, the time of the scrape.
+// It understands relative phrases ("3 days ago", "Posted 30+ days ago",
+// "vor 2 Wochen", "il y a 3 jours", "hier") and absolute dates in ISO,
+// numeric and month-name formats. Dates without a zone use now's location.
+func Parse(text string, now time.Time) (Result, error) {
+	s := normalize(text)
+	if s == "" {
+		return Result{}, fmt.Errorf("empty date")
+	}
+
+	if r, ok := parseAbsolute(s, now.Location()); ok {
+		return r, nil
+	}
+
+	padded := " " + s + " "
+	switch {
+	case containsPhrase(padded, justNowWords):
+		return Result{Time: now, Precision: PrecisionApproximate}, nil
+	case containsPhrase(padded, todayWords):
+		return Result{Time: startOfDay(now), Precision: PrecisionDay}, nil
+	case containsPhrase(padded, yesterdayWords):
+		return Result{Time: startOfDay(now).AddDate(0, 0, -1), Precision: PrecisionDay}, nil
+	}
+
+	if d, ok := parseRelative(s); ok {
+		return Result{Time: now.Add(-d), Precision: PrecisionApproximate}, nil
+	}
+
+	return Result{}, fmt.Errorf("unrecognized date %q", text)
+}
+
+// ParseOrNow parses text, falling back to now with unknown precision
+func ParseOrNow(text string, now time.Time) Result {
+	r, err := Parse(text, now)
+	if err != nil {
+		return Result{Time: now, Precision: PrecisionUnknown}
+	}
+	return r
+}
+
+// parseRelative returns the age expressed by a relative phrase
+func parseRelative(s string) (time.Duration, bool) {
+	for _, m := range numericRelativeRe.FindAllStringSubmatch(s, -1) {
+		unit, ok := units[m[2]]
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(m[1])
+		if err != nil {
+			continue
+		}
+		return time.Duration(n) * unit, true
+	}
+
+	// "an hour ago", "vor einem Tag", "il y a un an"
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r == '\'')
+	})
+	for i := 0; i+1 < len(words); i++ {
+		if unit, ok := units[words[i+1]]; ok && articles[words[i]] {
+			return unit, true
+		}
+	}
+
+	return 0, false
+}
+
+// parseAbsolute recognizes calendar dates anywhere in the normalized text
+func parseAbsolute(s string, loc *time.Location) (Result, bool) {
+	if m := strings.ToUpper(isoRe.FindString(s)); m != "" {
+		for _, layout := range isoLayouts {
+			if t, err := time.ParseInLocation(layout, m, loc); err == nil {
+				return Result{Time: t, Precision: PrecisionExact}, true
+			}
+		}
+		if t, err := time.ParseInLocation("2006-01-02", m, loc); err == nil {
+			return Result{Time: t, Precision: PrecisionDay}, true
+		}
+	}
+
+	if m := slashRe.FindStringSubmatch(s); m != nil {
+		if t, ok := makeDate(m[3], m[1], m[2], loc); ok {
+			return Result{Time: t, Precision: PrecisionDay}, true
+		}
+	}
+
+	if m := dotRe.FindStringSubmatch(s); m != nil {
+		if t, ok := makeDate(m[3], m[2], m[1], loc); ok {
+			return Result{Time: t, Precision: PrecisionDay}, true
+		}
+	}
+
+	if m := dayMonthRe.FindStringSubmatch(s); m != nil {
+		if month, ok := months[m[2]]; ok {
+			if t, ok := makeDate(m[3], strconv.Itoa(int(month)), m[1], loc); ok {
+				return Result{Time: t, Precision: PrecisionDay}, true
+			}
+		}
+	}
+
+	if m := monthDayRe.FindStringSubmatch(s); m != nil {
+		if month, ok := months[m[1]]; ok {
+			if t, ok := makeDate(m[3], strconv.Itoa(int(month)), m[2], loc); ok {
+				return Result{Time: t, Precision: PrecisionDay}, true
+			}
+		}
+	}
+
+	return Result{}, false
+}
+
+// makeDate builds a date from numeric parts, rejecting impossible dates
+// such as 31/02 instead of letting time.Date normalize them
+func makeDate(year, month, day string, loc *time.Location) (time.Time, bool) {
+	y, err1 := strconv.Atoi(year)
+	m, err2 := strconv.Atoi(month)
+	d, err3 := strconv.Atoi(day)
+	if err1 != nil || err2 != nil || err3 != nil || m < 1 || m > 12 || d < 1 {
+		return time.Time{}, false
+	}
+
+	t := time.Date(y, time.Month(m), d, 0, 0, 0, 0, loc)
+	if t.Day() != d {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// normalize lowercases, folds accents and collapses whitespace
+func normalize(text string) string {
+	s := accents.Replace(strings.ToLower(text))
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// containsPhrase reports whether the padded text contains any whole phrase
+func containsPhrase(padded string, phrases []string) bool {
+	for _, p := range phrases {
+		if strings.Contains(padded, " "+p+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,74 @@
+package dateparse
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	now := time.Date(2024, 3, 15, 14, 30, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	midnight := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		text      string
+		want      time.Time
+		precision Precision
+	}{
+		{"3 days ago", now.Add(-3 * day), PrecisionApproximate},
+		{"Posted 30+ days ago", now.Add(-30 * day), PrecisionApproximate},
+		{"5 hours ago", now.Add(-5 * time.Hour), PrecisionApproximate},
+		{"an hour ago", now.Add(-time.Hour), PrecisionApproximate},
+		{"2w", now.Add(-14 * day), PrecisionApproximate},
+		{"3d", now.Add(-3 * day), PrecisionApproximate},
+		{"Just posted", now, PrecisionApproximate},
+		{"Today", midnight, PrecisionDay},
+		{"yesterday", midnight.Add(-day), PrecisionDay},
+		{"vor 2 Wochen", now.Add(-14 * day), PrecisionApproximate},
+		{"vor einem Tag", now.Add(-day), PrecisionApproximate},
+		{"gestern", midnight.Add(-day), PrecisionDay},
+		{"hier", midnight.Add(-day), PrecisionDay},
+		{"il y a 3 jours", now.Add(-3 * day), PrecisionApproximate},
+		{"Publié aujourd'hui", midnight, PrecisionDay},
+		{"hace 2 días", now.Add(-2 * day), PrecisionApproximate},
+		{"há 4 horas", now.Add(-4 * time.Hour), PrecisionApproximate},
+		{"2 dagen geleden", now.Add(-2 * day), PrecisionApproximate},
+		{"2024-03-10T09:15:00Z", time.Date(2024, 3, 10, 9, 15, 0, 0, time.UTC), PrecisionExact},
+		{"2024-03-10", time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), PrecisionDay},
+		{"03/10/2024", time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), PrecisionDay},
+		{"10.03.2024", time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), PrecisionDay},
+		{"Posted on March 10, 2024", time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), PrecisionDay},
+		{"10. März 2024", time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), PrecisionDay},
+		{"10 mars 2024", time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), PrecisionDay},
+		{"10 de marzo de 2024", time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), PrecisionDay},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := Parse(tt.text, now)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if !got.Time.Equal(tt.want) {
+				t.Errorf("Time = %v, want %v", got.Time, tt.want)
+			}
+			if got.Precision != tt.precision {
+				t.Errorf("Precision = %q, want %q", got.Precision, tt.precision)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	now := time.Now()
+	for _, text := range []string{"", "recently", "31/02/2024", "Posted and updated"} {
+		if _, err := Parse(text, now); err == nil {
+			t.Errorf("Parse(%q) expected error", text)
+		}
+	}
+
+	r := ParseOrNow("sometime", now)
+	if r.Precision != PrecisionUnknown || !r.Time.Equal(now) {
+		t.Errorf("ParseOrNow() = %+v, want now with unknown precision", r)
+	}
+}
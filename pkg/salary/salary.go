@@ -0,0 +1,150 @@
+package salary
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Period is the time span a salary amount is paid for
+type Period string
+
+const (
+	PeriodYear  Period = "year"
+	PeriodMonth Period = "month"
+	PeriodWeek  Period = "week"
+	PeriodDay   Period = "day"
+	PeriodHour  Period = "hour"
+)
+
+// periodsPerYear converts amounts of each period to a yearly amount,
+// assuming full-time work of 40 hours and 5 days a week
+var periodsPerYear = map[Period]int64{
+	PeriodYear:  1,
+	PeriodMonth: 12,
+	PeriodWeek:  52,
+	PeriodDay:   260,
+	PeriodHour:  2080,
+}
+
+// Range is a parsed salary. Min and Max are yearly amounts in whole units
+// of Currency; Max equals Min when a single figure was given.
+type Range struct {
+	Min      int64
+	Max      int64
+	Currency string // ISO 4217 code, "" if not stated
+	Period   Period // Period of the original amounts
+}
+
+var (
+	amountRe = regexp.MustCompile(`(\d+(?:[.,]\d+)*)\s*([km])?\b`)
+
+	currencySymbols = []struct{ symbol, code string }{
+		{"us$", "USD"}, {"c$", "CAD"}, {"a$", "AUD"}, {"$", "USD"},
+		{"€", "EUR"}, {"£", "GBP"}, {"₹", "INR"}, {"¥", "JPY"},
+	}
+	currencyCodes = []string{"usd", "eur", "gbp", "cad", "aud", "inr", "jpy", "chf"}
+
+	periodWords = []struct {
+		words  []string
+		period Period
+	}{
+		{[]string{"/hr", "/hour", "per hour", "an hour", "hourly", "/h"}, PeriodHour},
+		{[]string{"/day", "per day", "a day", "daily"}, PeriodDay},
+		{[]string{"/wk", "/week", "per week", "a week", "weekly"}, PeriodWeek},
+		{[]string{"/mo", "/month", "per month", "a month", "monthly"}, PeriodMonth},
+		{[]string{"/yr", "/year", "per year", "a year", "annually", "per annum", "yearly", "p.a."}, PeriodYear},
+	}
+)
+
+// Parse reads salary text such as "$120k - $150k", "€60.000 per year" or
+// "$45/hr" and annualizes it. Amounts below 1,000 without a stated period
+// are taken as hourly.
+func Parse(text string) (Range, error) {
+	s := strings.ToLower(strings.TrimSpace(text))
+	if s == "" {
+		return Range{}, fmt.Errorf("empty salary")
+	}
+
+	var amounts []int64
+	for _, m := range amountRe.FindAllStringSubmatch(s, 2) {
+		amount, err := parseAmount(m[1], m[2])
+		if err != nil {
+			return Range{}, err
+		}
+		amounts = append(amounts, amount)
+	}
+	if len(amounts) == 0 {
+		return Range{}, fmt.Errorf("no amount in salary %q", text)
+	}
+
+	r := Range{Min: amounts[0], Max: amounts[len(amounts)-1], Currency: currency(s), Period: period(s)}
+	if r.Max < r.Min {
+		r.Min, r.Max = r.Max, r.Min
+	}
+
+	if r.Period == "" {
+		r.Period = PeriodYear
+		if r.Max < 1000 {
+			r.Period = PeriodHour
+		}
+	}
+
+	r.Min *= periodsPerYear[r.Period]
+	r.Max *= periodsPerYear[r.Period]
+	return r, nil
+}
+
+// parseAmount parses "120", "1,200.50", "60.000" and a k or m suffix
+func parseAmount(number, suffix string) (int64, error) {
+	// A separator followed by exactly three digits groups thousands;
+	// anything else is a decimal point
+	clean := number
+	if parts := strings.FieldsFunc(number, func(r rune) bool { return r == ',' || r == '.' }); len(parts) > 1 {
+		last := parts[len(parts)-1]
+		if len(last) == 3 {
+			clean = strings.Join(parts, "")
+		} else {
+			clean = strings.Join(parts[:len(parts)-1], "") + "." + last
+		}
+	}
+
+	value, err := strconv.ParseFloat(clean, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid salary amount %q", number)
+	}
+
+	switch suffix {
+	case "k":
+		value *= 1000
+	case "m":
+		value *= 1000000
+	}
+	return int64(value + 0.5), nil
+}
+
+func currency(s string) string {
+	for _, c := range currencySymbols {
+		if strings.Contains(s, c.symbol) {
+			return c.code
+		}
+	}
+	for _, code := range currencyCodes {
+		if strings.Contains(s, code) {
+			return strings.ToUpper(code)
+		}
+	}
+	return ""
+}
+
+func period(s string) Period {
+	for _, p := range periodWords {
+		for _, w := range p.words {
+			if strings.Contains(s, w) {
+				return p.period
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,42 @@
+package salary
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		text     string
+		min, max int64
+		currency string
+		period   Period
+	}{
+		{"$120k - $150k", 120000, 150000, "USD", PeriodYear},
+		{"$150,000", 150000, 150000, "USD", PeriodYear},
+		{"€60.000 - €75.000 per year", 60000, 75000, "EUR", PeriodYear},
+		{"£4,500 per month", 54000, 54000, "GBP", PeriodMonth},
+		{"$45/hr", 93600, 93600, "USD", PeriodHour},
+		{"50 - 65", 104000, 135200, "", PeriodHour},
+		{"CAD 90k-110k", 90000, 110000, "CAD", PeriodYear},
+		{"$1.2M", 1200000, 1200000, "USD", PeriodYear},
+		{"$170k - $140k", 140000, 170000, "USD", PeriodYear},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := Parse(tt.text)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got.Min != tt.min || got.Max != tt.max || got.Currency != tt.currency || got.Period != tt.period {
+				t.Errorf("Parse() = %+v, want {%d %d %s %s}", got, tt.min, tt.max, tt.currency, tt.period)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, text := range []string{"", "Competitive", "DOE"} {
+		if _, err := Parse(text); err == nil {
+			t.Errorf("Parse(%q) expected error", text)
+		}
+	}
+}
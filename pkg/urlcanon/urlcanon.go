@@ -0,0 +1,209 @@
+package urlcanon
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Result is a canonicalized job URL
+type Result struct {
+	URL      string
+	NativeID string // Job ID assigned by the board, "" if unknown
+}
+
+// Rule describes how URLs of one job board are canonicalized
+type Rule struct {
+	// Host matches the host and its subdomains, e.g. "indeed.com"
+	Host string
+	// KeepParams lists the query parameters that identify the posting.
+	// All others are dropped. nil keeps every non-tracking parameter.
+	KeepParams []string
+	// IDParam is the query parameter holding the native job ID
+	IDParam string
+	// IDPath extracts the native job ID from the path; the first
+	// capture group is the ID
+	IDPath *regexp.Regexp
+	// Template, if set, rebuilds the canonical URL from the native ID so
+	// search pages and apply links of the same posting agree
+	Template string
+}
+
+// trackingParams are removed from every URL
+var trackingParams = map[string]bool{
+	"refid": true, "trackingid": true, "trk": true, "trkinfo": true,
+	"gclid": true, "fbclid": true, "msclkid": true, "mc_cid": true, "mc_eid": true,
+	"ref": true, "referrer": true, "src": true, "from": true, "vjs": true,
+	"tk": true, "_ga": true, "sessionid": true, "lipi": true,
+}
+
+// trackingPrefixes are parameter prefixes removed from every URL
+var trackingPrefixes = []string{"utm_", "mc_", "pk_", "hs_"}
+
+// Rules are the per-host rules, most specific host first
+var Rules = []Rule{
+	{
+		Host:       "indeed.com",
+		KeepParams: []string{"jk"},
+		IDParam:    "jk",
+		IDPath:     regexp.MustCompile(`^/(?:job|viewjob)/([0-9a-z]+)$`),
+		Template:   "https://indeed.com/viewjob?jk=%s",
+	},
+	{
+		Host:       "linkedin.com",
+		KeepParams: []string{},
+		IDParam:    "currentjobid",
+		IDPath:     regexp.MustCompile(`^/jobs/view/(?:[^/]*-)?([0-9]+)$`),
+		Template:   "https://linkedin.com/jobs/view/%s",
+	},
+	{
+		Host:       "glassdoor.com",
+		KeepParams: []string{"jl"},
+		IDParam:    "jl",
+		IDPath:     regexp.MustCompile(`^/job-listing/(?:.*[_-])?([0-9]+)(?:\.htm)?$`),
+	},
+	{
+		Host:       "greenhouse.io",
+		KeepParams: []string{},
+		IDParam:    "gh_jid",
+		IDPath:     regexp.MustCompile(`^/[^/]+/jobs/([0-9]+)$`),
+	},
+	{
+		Host:       "lever.co",
+		KeepParams: []string{},
+		IDPath:     regexp.MustCompile(`^/[^/]+/([0-9a-f-]{36})(?:/apply)?$`),
+	},
+}
+
+// Canonicalize normalizes a job URL using the default rules
+func Canonicalize(raw string) (Result, error) {
+	return CanonicalizeWith(raw, Rules)
+}
+
+// CanonicalizeWith normalizes a job URL: scheme and host are lowercased,
+// "www." and "m." prefixes, fragments, trailing slashes and tracking
+// parameters are removed, the remaining parameters are sorted, and the
+// board-native job ID is extracted by the matching rule
+func CanonicalizeWith(raw string, rules []Rule) (Result, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to parse URL: %w", err)
+	}
+	if u.Host == "" {
+		return Result{}, fmt.Errorf("URL %q has no host", raw)
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	if u.Scheme == "http" {
+		u.Scheme = "https"
+	}
+	u.Host = normalizeHost(u.Host)
+	u.Fragment = ""
+	u.RawFragment = ""
+	u.User = nil
+
+	path := strings.TrimRight(u.Path, "/")
+	if path == "" {
+		path = "/"
+	}
+	u.Path = path
+	u.RawPath = ""
+
+	rule, hasRule := matchRule(u.Host, rules)
+
+	// Parameter names are matched against the tracking and kept lists
+	// case-insensitively, but kept as written
+	params := u.Query()
+	kept := url.Values{}
+	for name, values := range params {
+		key := strings.ToLower(name)
+		if isTracking(key) {
+			continue
+		}
+		if hasRule && rule.KeepParams != nil && !contains(rule.KeepParams, key) {
+			continue
+		}
+		kept[name] = values
+	}
+	u.RawQuery = encodeSorted(kept)
+
+	result := Result{URL: u.String()}
+	if hasRule {
+		result.NativeID = nativeID(rule, u.Path, params)
+		if result.NativeID != "" && rule.Template != "" {
+			result.URL = fmt.Sprintf(rule.Template, url.QueryEscape(result.NativeID))
+		}
+	}
+	return result, nil
+}
+
+// nativeID extracts the board-native job ID, preferring the query parameter
+func nativeID(rule Rule, path string, params url.Values) string {
+	if rule.IDParam != "" {
+		for name, values := range params {
+			if strings.ToLower(name) == rule.IDParam && len(values) > 0 && values[0] != "" {
+				return strings.ToLower(values[0])
+			}
+		}
+	}
+
+	if rule.IDPath != nil {
+		if m := rule.IDPath.FindStringSubmatch(strings.ToLower(path)); m != nil {
+			return m[1]
+		}
+	}
+	return ""
+}
+
+// normalizeHost lowercases a host and drops default ports and "www."/"m."
+func normalizeHost(host string) string {
+	host = strings.ToLower(host)
+	host = strings.TrimSuffix(host, ":443")
+	host = strings.TrimSuffix(host, ":80")
+	for _, prefix := range []string{"www.", "m."} {
+		host = strings.TrimPrefix(host, prefix)
+	}
+	return host
+}
+
+// matchRule finds the rule whose host equals or is a parent of host
+func matchRule(host string, rules []Rule) (Rule, bool) {
+	for _, rule := range rules {
+		if host == rule.Host || strings.HasSuffix(host, "."+rule.Host) {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+func isTracking(key string) bool {
+	if trackingParams[key] {
+		return true
+	}
+	for _, prefix := range trackingPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeSorted encodes query parameters sorted by name and value
+func encodeSorted(v url.Values) string {
+	for _, values := range v {
+		sort.Strings(values)
+	}
+	// url.Values.Encode already sorts by key
+	return v.Encode()
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
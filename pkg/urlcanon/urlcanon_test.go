@@ -0,0 +1,70 @@
+package urlcanon
+
+import "testing"
+
+func TestCanonicalize(t *testing.T) {
+	tests := []struct {
+		raw      string
+		url      string
+		nativeID string
+	}{
+		{
+			"https://www.indeed.com/viewjob?jk=AB12cd34&utm_source=google&from=serp&vjs=3",
+			"https://indeed.com/viewjob?jk=ab12cd34", "ab12cd34",
+		},
+		{"http://indeed.com/job/12345", "https://indeed.com/viewjob?jk=12345", "12345"},
+		{
+			"https://www.linkedin.com/jobs/view/senior-go-developer-at-acme-3812345678/?refId=abc&trackingId=xyz%3D%3D",
+			"https://linkedin.com/jobs/view/3812345678", "3812345678",
+		},
+		{
+			"https://linkedin.com/jobs/search/?currentJobId=3812345678&keywords=go",
+			"https://linkedin.com/jobs/view/3812345678", "3812345678",
+		},
+		{
+			"https://www.glassdoor.com/job-listing/go-developer-acme-JV_IC1147401_KO0,12_KE13,17.htm?jl=1009123456&utm_medium=email",
+			"https://glassdoor.com/job-listing/go-developer-acme-JV_IC1147401_KO0,12_KE13,17.htm?jl=1009123456", "1009123456",
+		},
+		{"https://glassdoor.com/job-listing/42", "https://glassdoor.com/job-listing/42", "42"},
+		{
+			"https://boards.greenhouse.io/acme/jobs/4567890?gh_src=abc#app",
+			"https://boards.greenhouse.io/acme/jobs/4567890", "4567890",
+		},
+		{
+			"https://jobs.lever.co/acme/5f0c1b2a-1234-4cde-9abc-0123456789ab/apply?lever-source=LinkedIn",
+			"https://jobs.lever.co/acme/5f0c1b2a-1234-4cde-9abc-0123456789ab/apply",
+			"5f0c1b2a-1234-4cde-9abc-0123456789ab",
+		},
+		{
+			"HTTPS://Careers.Example.com/Jobs/Go/?b=2&a=1&utm_campaign=x&fbclid=y",
+			"https://careers.example.com/Jobs/Go?a=1&b=2", "",
+		},
+		{
+			"https://careers.example.com/job?ID=7&id=8&UTM_Source=x",
+			"https://careers.example.com/job?ID=7&id=8", "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := Canonicalize(tt.raw)
+			if err != nil {
+				t.Fatalf("Canonicalize() error = %v", err)
+			}
+			if got.URL != tt.url {
+				t.Errorf("URL = %q, want %q", got.URL, tt.url)
+			}
+			if got.NativeID != tt.nativeID {
+				t.Errorf("NativeID = %q, want %q", got.NativeID, tt.nativeID)
+			}
+		})
+	}
+}
+
+func TestCanonicalizeInvalid(t *testing.T) {
+	for _, raw := range []string{"", "not a url", "/relative/path", "http://%zz"} {
+		if _, err := Canonicalize(raw); err == nil {
+			t.Errorf("Canonicalize(%q) expected error", raw)
+		}
+	}
+}